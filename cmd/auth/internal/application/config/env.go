@@ -56,11 +56,47 @@ type environment struct {
 		MaxIdleConns    int           `env:"MYSQL_MAX_IDLE_CONNS"    envDefault:"0"`  // sets the maximum number of connections in the idle
 		MaxOpenConns    int           `env:"MYSQL_MAX_OPEN_CONNS"    envDefault:"5"`  // sets the maximum number of connections in the idle
 	}
+	Postgres struct {
+		Host     string `env:"POSTGRES_HOST"     envDefault:"0.0.0.0"`
+		Port     int    `env:"POSTGRES_PORT"     envDefault:"5432"`
+		User     string `env:"POSTGRES_USER"     envDefault:"postgres"`
+		Pass     string `env:"POSTGRES_PASS"     envDefault:"password"`
+		Database string `env:"POSTGRES_DATABASE" envDefault:"goapiboilerplate"`
+		SSLMode  string `env:"POSTGRES_SSL_MODE" envDefault:"disable"`
+
+		ConnMaxLifetime time.Duration `env:"POSTGRES_CONN_MAX_LIFETIME" envDefault:"5m"` //  sets the maximum amount of time a connection may be reused
+		MaxIdleConns    int           `env:"POSTGRES_MAX_IDLE_CONNS"    envDefault:"0"`  // sets the maximum number of connections in the idle
+		MaxOpenConns    int           `env:"POSTGRES_MAX_OPEN_CONNS"    envDefault:"5"`  // sets the maximum number of open connections
+	}
+	EventStore struct {
+		Driver            string `env:"EVENT_STORE_DRIVER"             envDefault:"mysql"` // mysql, postgres, file or memory
+		SnapshotFrequency int    `env:"EVENT_STORE_SNAPSHOT_FREQUENCY" envDefault:"100"`   // take aggregate root snapshot every N events, 0 disables snapshots
+
+		FileDir          string        `env:"EVENT_STORE_FILE_DIR"           envDefault:"data/auth/events"` // directory of segment files for file driver
+		FileSync         string        `env:"EVENT_STORE_FILE_SYNC"          envDefault:"always"`           // always, interval or never
+		FileSyncInterval time.Duration `env:"EVENT_STORE_FILE_SYNC_INTERVAL" envDefault:"1s"`               // fsync interval for interval sync mode
+		FileSegmentSize  int64         `env:"EVENT_STORE_FILE_SEGMENT_SIZE"  envDefault:"67108864"`         // start new segment file after given size in bytes
+	}
 	CommandBus struct {
 		QueueSize int `env:"COMMAND_BUS_BUFFER" envDefault:"0"`
 	}
 	EventBus struct {
 		QueueSize int `env:"COMMAND_BUS_BUFFER" envDefault:"0"`
+
+		OrderedDelivery bool `env:"EVENT_BUS_ORDERED_DELIVERY" envDefault:"true"` // deliver events of one stream in order, QueueSize streams are handled in parallel
+
+		Outbox             bool          `env:"EVENT_BUS_OUTBOX"               envDefault:"true"` // publish events through transactional outbox relay
+		OutboxPollInterval time.Duration `env:"EVENT_BUS_OUTBOX_POLL_INTERVAL" envDefault:"1s"`   // wait before checking outbox again once it was drained or publishing failed
+		OutboxBatchSize    int           `env:"EVENT_BUS_OUTBOX_BATCH_SIZE"    envDefault:"100"`  // number of events fetched from outbox at once
+		OutboxMaxAttempts  int           `env:"EVENT_BUS_OUTBOX_MAX_ATTEMPTS"  envDefault:"0"`    // stop retrying event after N failed attempts, 0 retries forever
+		OutboxClaimTimeout time.Duration `env:"EVENT_BUS_OUTBOX_CLAIM_TIMEOUT" envDefault:"30s"`  // time event stays claimed by publisher before another one can publish it
+
+		RetryMaxAttempts    int           `env:"EVENT_BUS_RETRY_MAX_ATTEMPTS"    envDefault:"3"`     // call failing event handler up to N times before moving event to dead letters
+		RetryInitialBackoff time.Duration `env:"EVENT_BUS_RETRY_INITIAL_BACKOFF" envDefault:"100ms"` // wait after first failed attempt, doubled after each next one
+		RetryMaxBackoff     time.Duration `env:"EVENT_BUS_RETRY_MAX_BACKOFF"     envDefault:"5s"`
+
+		ConsumerGroupPollInterval time.Duration `env:"EVENT_BUS_CONSUMER_GROUP_POLL_INTERVAL" envDefault:"1s"`  // check event store for events bus did not deliver to consumer groups
+		ConsumerGroupBatchSize    int           `env:"EVENT_BUS_CONSUMER_GROUP_BATCH_SIZE"    envDefault:"100"` // number of events read from event store at once while catching up
 	}
 }
 
@@ -85,6 +121,12 @@ func init() {
 	if err := env.Parse(&Env.MYSQL); err != nil {
 		panic(err)
 	}
+	if err := env.Parse(&Env.Postgres); err != nil {
+		panic(err)
+	}
+	if err := env.Parse(&Env.EventStore); err != nil {
+		panic(err)
+	}
 	if err := env.Parse(&Env.CommandBus); err != nil {
 		panic(err)
 	}
@@ -4,37 +4,26 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"time"
 
 	"github.com/vardius/go-api-boilerplate/cmd/auth/internal/domain/client"
 	"github.com/vardius/go-api-boilerplate/cmd/auth/internal/infrastructure/persistence"
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/mysql"
 )
 
 // WhenClientWasCreated handles event
 func WhenClientWasCreated(db *sql.DB, repository persistence.ClientRepository) eventbus.EventHandler {
-	fn := func(parentCtx context.Context, event domain.Event) error {
-		ctx, cancel := context.WithTimeout(parentCtx, time.Second*120)
-		defer cancel()
-
+	fn := func(ctx context.Context, event domain.Event) error {
 		e := client.WasCreated{}
-		if err := json.Unmarshal(event.Payload, &e); err != nil {
-			return errors.Wrap(err)
-		}
-
-		tx, err := db.BeginTx(ctx, nil)
-		if err != nil {
-			return errors.Wrap(err)
-		}
-		defer tx.Rollback()
-
-		if err := repository.Add(ctx, clientModel{e}); err != nil {
+		if err := client.DecodeEventInto(event, &e); err != nil {
 			return errors.Wrap(err)
 		}
 
-		if err := tx.Commit(); err != nil {
+		if err := mysql.InTransaction(ctx, db, func(ctx context.Context) error {
+			return repository.Add(ctx, clientModel{e})
+		}); err != nil {
 			return errors.Wrap(err)
 		}
 
@@ -3,38 +3,26 @@ package eventhandler
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
-	"time"
 
 	"github.com/vardius/go-api-boilerplate/cmd/auth/internal/domain/token"
 	"github.com/vardius/go-api-boilerplate/cmd/auth/internal/infrastructure/persistence"
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/mysql"
 )
 
 // WhenTokenWasRemoved handles event
 func WhenTokenWasRemoved(db *sql.DB, repository persistence.TokenRepository) eventbus.EventHandler {
-	fn := func(parentCtx context.Context, event domain.Event) error {
-		ctx, cancel := context.WithTimeout(parentCtx, time.Second*120)
-		defer cancel()
-
+	fn := func(ctx context.Context, event domain.Event) error {
 		e := token.WasRemoved{}
-		if err := json.Unmarshal(event.Payload, &e); err != nil {
-			return errors.Wrap(err)
-		}
-
-		tx, err := db.BeginTx(ctx, nil)
-		if err != nil {
-			return errors.Wrap(err)
-		}
-		defer tx.Rollback()
-
-		if err := repository.Delete(ctx, e.ID.String()); err != nil {
+		if err := token.DecodeEventInto(event, &e); err != nil {
 			return errors.Wrap(err)
 		}
 
-		if err := tx.Commit(); err != nil {
+		if err := mysql.InTransaction(ctx, db, func(ctx context.Context) error {
+			return repository.Delete(ctx, e.ID.String())
+		}); err != nil {
 			return errors.Wrap(err)
 		}
 
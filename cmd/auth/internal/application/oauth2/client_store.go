@@ -46,7 +46,7 @@ func (cs *ClientStore) Set(ctx context.Context, info oauth2.ClientInfo) error {
 		ClientInfo: info,
 	}
 
-	if err := cs.commandBus.Publish(ctx, c); err != nil {
+	if _, err := cs.commandBus.Publish(ctx, c); err != nil {
 		return errors.Wrap(err)
 	}
 
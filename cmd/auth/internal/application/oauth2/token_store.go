@@ -32,7 +32,7 @@ func (ts *TokenStore) Create(ctx context.Context, info oauth2.TokenInfo) error {
 		TokenInfo: info,
 	}
 
-	if err := ts.commandBus.Publish(ctx, c); err != nil {
+	if _, err := ts.commandBus.Publish(ctx, c); err != nil {
 		return errors.Wrap(err)
 	}
 
@@ -118,7 +118,7 @@ func (ts *TokenStore) remove(ctx context.Context, t persistence.Token) error {
 		ID: id,
 	}
 
-	if err := ts.commandBus.Publish(ctx, c); err != nil {
+	if _, err := ts.commandBus.Publish(ctx, c); err != nil {
 		return errors.Wrap(err)
 	}
 
@@ -7,14 +7,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 
 	"github.com/google/uuid"
 	"gopkg.in/oauth2.v4"
 
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
-	"github.com/vardius/go-api-boilerplate/pkg/identity"
 )
 
 // StreamName for client domain
@@ -22,62 +21,66 @@ var StreamName = fmt.Sprintf("%T", Client{})
 
 // Client aggregate root
 type Client struct {
-	id      uuid.UUID
-	version int
-	changes []domain.Event
+	aggregate.Base
+}
+
+// snapshot is a serialized client state
+type snapshot struct {
+	ID uuid.UUID `json:"id"`
 }
 
 // New creates an Client
 func New() Client {
-	return Client{}
+	return Client{Base: aggregate.NewBase(StreamName, eventRegistry)}
 }
 
 // FromHistory loads current aggregate root state by applying all events in order
-func FromHistory(events []domain.Event) Client {
+func FromHistory(events []domain.Event) (Client, error) {
 	c := New()
-
-	for _, domainEvent := range events {
-		var e domain.RawEvent
-
-		switch domainEvent.Metadata.Type {
-		case (WasCreated{}).GetType():
-			wasCreated := WasCreated{}
-			if err := unmarshalPayload(domainEvent.Payload, &wasCreated); err != nil {
-				log.Panicf("Error while trying to unmarshal client event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = wasCreated
-		case (WasRemoved{}).GetType():
-			wasRemoved := WasRemoved{}
-			if err := unmarshalPayload(domainEvent.Payload, &wasRemoved); err != nil {
-				log.Panicf("Error while trying to unmarshal client event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = wasRemoved
-		default:
-			log.Panicf("Unhandled client event %s\n", domainEvent.Metadata.Type)
-		}
-
-		c.transition(e)
-		c.version++
+	if err := c.Replay(events); err != nil {
+		return c, errors.Wrap(err)
 	}
 
-	return c
+	return c, nil
 }
 
-// ID returns aggregate root id
-func (c Client) ID() uuid.UUID {
-	return c.id
+// FromSnapshot restores aggregate root state from snapshot taken at given version
+// and applies events that occurred after it in order
+func FromSnapshot(version int, payload json.RawMessage, events []domain.Event) (Client, error) {
+	c := New()
+	if err := c.UnmarshalSnapshot(version, payload); err != nil {
+		return c, errors.Wrap(err)
+	}
+
+	if err := c.Replay(events); err != nil {
+		return c, errors.Wrap(err)
+	}
+
+	return c, nil
 }
 
-// Version returns current aggregate root version
-func (c Client) Version() int {
-	return c.version
+// MarshalSnapshot serializes current aggregate root state
+func (c Client) MarshalSnapshot() (json.RawMessage, error) {
+	data, err := json.Marshal(snapshot{
+		ID: c.ID(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return data, nil
 }
 
-// Changes returns all new applied events
-func (c Client) Changes() []domain.Event {
-	return c.changes
+// UnmarshalSnapshot restores aggregate root state taken at given version
+func (c *Client) UnmarshalSnapshot(version int, payload json.RawMessage) error {
+	var s snapshot
+	if err := unmarshalPayload(payload, &s); err != nil {
+		return errors.Wrap(err)
+	}
+
+	c.Restore(s.ID, version)
+
+	return nil
 }
 
 // Create alters current client state and append changes to aggregate root
@@ -113,7 +116,7 @@ func (c *Client) Create(ctx context.Context, info oauth2.ClientInfo) error {
 // Remove alters current client state and append changes to aggregate root
 func (c *Client) Remove(ctx context.Context) error {
 	if _, err := c.trackChange(ctx, WasRemoved{
-		ID: c.id,
+		ID: c.ID(),
 	}); err != nil {
 		return errors.Wrap(err)
 	}
@@ -122,29 +125,17 @@ func (c *Client) Remove(ctx context.Context) error {
 }
 
 func (c *Client) trackChange(ctx context.Context, e domain.RawEvent) (domain.Event, error) {
-	c.transition(e)
-
-	var (
-		event domain.Event
-		err   error
-	)
-	if i, hasIdentity := identity.FromContext(ctx); hasIdentity {
-		event, err = domain.NewEvent(c.id, StreamName, c.version, e, &i)
-	} else {
-		event, err = domain.NewEvent(c.id, StreamName, c.version, e, nil)
-	}
-	if err != nil {
-		return event, errors.Wrap(err)
-	}
-
-	c.changes = append(c.changes, event)
+	return c.TrackChange(ctx, c.transition, e)
+}
 
-	return event, nil
+// Replay applies stored events in order
+func (c *Client) Replay(events []domain.Event) error {
+	return c.Base.Replay(c.transition, events)
 }
 
 func (c *Client) transition(e domain.RawEvent) {
 	switch e := e.(type) {
 	case WasCreated:
-		c.id = e.ID
+		c.SetID(e.ID)
 	}
 }
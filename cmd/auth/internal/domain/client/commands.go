@@ -8,7 +8,6 @@ import (
 	"gopkg.in/oauth2.v4"
 
 	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
-	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
 )
@@ -25,29 +24,24 @@ func (c Remove) GetName() string {
 
 // OnRemove creates command handler
 func OnRemove(repository Repository) commandbus.CommandHandler {
-	fn := func(ctx context.Context, command domain.Command) error {
-		c, ok := command.(Remove)
-		if !ok {
-			return errors.New("invalid command")
-		}
-
+	fn := func(ctx context.Context, c Remove) (commandbus.Result, error) {
 		client, err := repository.Get(ctx, c.ID)
 		if err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if err := client.Remove(ctx); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if err := repository.Save(executioncontext.WithFlag(ctx, executioncontext.LIVE), client); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
-		return nil
+		return commandbus.Result{ID: client.ID(), Version: client.Version()}, nil
 	}
 
-	return fn
+	return commandbus.NewHandler(fn)
 }
 
 // Create command
@@ -62,26 +56,21 @@ func (c Create) GetName() string {
 
 // OnCreate creates command handler
 func OnCreate(repository Repository) commandbus.CommandHandler {
-	fn := func(ctx context.Context, command domain.Command) error {
-		c, ok := command.(Create)
-		if !ok {
-			return errors.New("invalid command")
-		}
-
+	fn := func(ctx context.Context, c Create) (commandbus.Result, error) {
 		client := New()
 		if err := client.Create(ctx, c.ClientInfo); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		// we block here until event handler is done
 		// this is because when other services request access token after creating client
 		// we want handler to be finished and client persisted in storage
 		if err := repository.SaveAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), client); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
-		return nil
+		return commandbus.Result{ID: client.ID(), Version: client.Version()}, nil
 	}
 
-	return fn
+	return commandbus.NewHandler(fn)
 }
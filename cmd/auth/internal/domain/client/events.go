@@ -2,11 +2,35 @@ package client
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
 )
 
+// eventRegistry maps stable client event type names to raw events
+var eventRegistry = newEventRegistry()
+
+func newEventRegistry() *domain.EventRegistry {
+	registry := domain.NewEventRegistry()
+	registry.MustRegister(
+		WasCreated{},
+		WasRemoved{},
+	)
+
+	return registry
+}
+
+// DecodeEvent decodes client domain event into its typed raw event
+func DecodeEvent(event domain.Event) (domain.RawEvent, error) {
+	return eventRegistry.Decode(event)
+}
+
+// DecodeEventInto decodes client domain event into given raw event pointer
+func DecodeEventInto(event domain.Event, target domain.RawEvent) error {
+	return eventRegistry.DecodeInto(event, target)
+}
+
 // WasCreated event
 type WasCreated struct {
 	ID     uuid.UUID `json:"id"`
@@ -19,7 +43,7 @@ type WasCreated struct {
 
 // GetType returns event type
 func (e WasCreated) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "client.WasCreated"
 }
 
 // WasRemoved event
@@ -29,5 +53,5 @@ type WasRemoved struct {
 
 // GetType returns event type
 func (e WasRemoved) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "client.WasRemoved"
 }
@@ -2,14 +2,21 @@ package client
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 // Repository allows to get/save events from/to event store
+// Save returns eventstore.ErrConcurrencyConflict if the stream was modified
+// since aggregate root has been loaded, caller can reload it and retry the command
 type Repository interface {
 	Save(ctx context.Context, c Client) error
 	Get(ctx context.Context, id uuid.UUID) (Client, error)
+	// GetAtVersion returns client state after given number of events were applied
+	GetAtVersion(ctx context.Context, id uuid.UUID, version int) (Client, error)
+	// GetAsOf returns client state from events that occurred at or before given time
+	GetAsOf(ctx context.Context, id uuid.UUID, at time.Time) (Client, error)
 
 	// Block and returns after event handlers are finished
 	SaveAndAcknowledge(ctx context.Context, c Client) error
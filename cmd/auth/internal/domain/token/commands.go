@@ -9,7 +9,6 @@ import (
 
 	"github.com/vardius/go-api-boilerplate/pkg/application"
 	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
-	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
 )
@@ -26,28 +25,23 @@ func (c Remove) GetName() string {
 
 // OnRemove creates command handler
 func OnRemove(repository Repository) commandbus.CommandHandler {
-	fn := func(ctx context.Context, command domain.Command) error {
-		c, ok := command.(Remove)
-		if !ok {
-			return errors.New("invalid command")
-		}
-
+	fn := func(ctx context.Context, c Remove) (commandbus.Result, error) {
 		token, err := repository.Get(ctx, c.ID)
 		if err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 		if err := token.Remove(ctx); err != nil {
-			return errors.Wrap(fmt.Errorf("%w: Error when removing token: %s", application.ErrInternal, err))
+			return commandbus.Result{}, errors.Wrap(fmt.Errorf("%w: Error when removing token: %s", application.ErrInternal, err))
 		}
 
 		if err := repository.Save(executioncontext.WithFlag(ctx, executioncontext.LIVE), token); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
-		return nil
+		return commandbus.Result{ID: token.ID(), Version: token.Version()}, nil
 	}
 
-	return fn
+	return commandbus.NewHandler(fn)
 }
 
 // Create command
@@ -62,28 +56,23 @@ func (c Create) GetName() string {
 
 // OnCreate creates command handler
 func OnCreate(repository Repository) commandbus.CommandHandler {
-	fn := func(ctx context.Context, command domain.Command) error {
-		c, ok := command.(Create)
-		if !ok {
-			return errors.New("invalid command")
-		}
-
+	fn := func(ctx context.Context, c Create) (commandbus.Result, error) {
 		id, err := uuid.NewRandom()
 		if err != nil {
-			return errors.Wrap(fmt.Errorf("%w: Could not generate new id: %s", application.ErrInternal, err))
+			return commandbus.Result{}, errors.Wrap(fmt.Errorf("%w: Could not generate new id: %s", application.ErrInternal, err))
 		}
 
 		token := New()
 		if err := token.Create(ctx, id, c.TokenInfo); err != nil {
-			return errors.Wrap(fmt.Errorf("%w: Error when creating new token: %s", application.ErrInternal, err))
+			return commandbus.Result{}, errors.Wrap(fmt.Errorf("%w: Error when creating new token: %s", application.ErrInternal, err))
 		}
 
 		if err := repository.Save(executioncontext.WithFlag(ctx, executioncontext.LIVE), token); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
-		return nil
+		return commandbus.Result{ID: token.ID(), Version: token.Version()}, nil
 	}
 
-	return fn
+	return commandbus.NewHandler(fn)
 }
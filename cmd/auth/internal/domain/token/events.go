@@ -2,11 +2,35 @@ package token
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
 )
 
+// eventRegistry maps stable token event type names to raw events
+var eventRegistry = newEventRegistry()
+
+func newEventRegistry() *domain.EventRegistry {
+	registry := domain.NewEventRegistry()
+	registry.MustRegister(
+		WasCreated{},
+		WasRemoved{},
+	)
+
+	return registry
+}
+
+// DecodeEvent decodes token domain event into its typed raw event
+func DecodeEvent(event domain.Event) (domain.RawEvent, error) {
+	return eventRegistry.Decode(event)
+}
+
+// DecodeEventInto decodes token domain event into given raw event pointer
+func DecodeEventInto(event domain.Event, target domain.RawEvent) error {
+	return eventRegistry.DecodeInto(event, target)
+}
+
 // WasCreated event
 type WasCreated struct {
 	ID uuid.UUID `json:"id"`
@@ -23,7 +47,7 @@ type WasCreated struct {
 
 // GetType returns event type
 func (e WasCreated) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "token.WasCreated"
 }
 
 // WasRemoved event
@@ -33,5 +57,5 @@ type WasRemoved struct {
 
 // GetType returns event type
 func (e WasRemoved) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "token.WasRemoved"
 }
@@ -2,12 +2,19 @@ package token
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 // Repository allows to get/save events from/to event store
+// Save returns eventstore.ErrConcurrencyConflict if the stream was modified
+// since aggregate root has been loaded, caller can reload it and retry the command
 type Repository interface {
 	Save(ctx context.Context, t Token) error
 	Get(ctx context.Context, id uuid.UUID) (Token, error)
+	// GetAtVersion returns token state after given number of events were applied
+	GetAtVersion(ctx context.Context, id uuid.UUID, version int) (Token, error)
+	// GetAsOf returns token state from events that occurred at or before given time
+	GetAsOf(ctx context.Context, id uuid.UUID, at time.Time) (Token, error)
 }
@@ -7,14 +7,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 
 	"github.com/google/uuid"
 	"gopkg.in/oauth2.v4"
 
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
-	"github.com/vardius/go-api-boilerplate/pkg/identity"
 )
 
 // StreamName for token domain
@@ -22,62 +21,66 @@ var StreamName = fmt.Sprintf("%T", Token{})
 
 // Token aggregate root
 type Token struct {
-	id      uuid.UUID
-	version int
-	changes []domain.Event
+	aggregate.Base
+}
+
+// snapshot is a serialized token state
+type snapshot struct {
+	ID uuid.UUID `json:"id"`
 }
 
 // New creates an Token
 func New() Token {
-	return Token{}
+	return Token{Base: aggregate.NewBase(StreamName, eventRegistry)}
 }
 
 // FromHistory loads current aggregate root state by applying all events in order
-func FromHistory(events []domain.Event) Token {
+func FromHistory(events []domain.Event) (Token, error) {
 	t := New()
-
-	for _, domainEvent := range events {
-		var e domain.RawEvent
-
-		switch domainEvent.Metadata.Type {
-		case (WasCreated{}).GetType():
-			wasCreated := WasCreated{}
-			if err := unmarshalPayload(domainEvent.Payload, &wasCreated); err != nil {
-				log.Panicf("Error while trying to unmarshal token event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = wasCreated
-		case (WasRemoved{}).GetType():
-			wasRemoved := WasRemoved{}
-			if err := unmarshalPayload(domainEvent.Payload, &wasRemoved); err != nil {
-				log.Panicf("Error while trying to unmarshal token event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = wasRemoved
-		default:
-			log.Panicf("Unhandled token event %s\n", domainEvent.Metadata.Type)
-		}
-
-		t.transition(e)
-		t.version++
+	if err := t.Replay(events); err != nil {
+		return t, errors.Wrap(err)
 	}
 
-	return t
+	return t, nil
 }
 
-// ID returns aggregate root id
-func (t Token) ID() uuid.UUID {
-	return t.id
+// FromSnapshot restores aggregate root state from snapshot taken at given version
+// and applies events that occurred after it in order
+func FromSnapshot(version int, payload json.RawMessage, events []domain.Event) (Token, error) {
+	t := New()
+	if err := t.UnmarshalSnapshot(version, payload); err != nil {
+		return t, errors.Wrap(err)
+	}
+
+	if err := t.Replay(events); err != nil {
+		return t, errors.Wrap(err)
+	}
+
+	return t, nil
 }
 
-// Version returns current aggregate root version
-func (t Token) Version() int {
-	return t.version
+// MarshalSnapshot serializes current aggregate root state
+func (t Token) MarshalSnapshot() (json.RawMessage, error) {
+	data, err := json.Marshal(snapshot{
+		ID: t.ID(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return data, nil
 }
 
-// Changes returns all new applied events
-func (t Token) Changes() []domain.Event {
-	return t.changes
+// UnmarshalSnapshot restores aggregate root state taken at given version
+func (t *Token) UnmarshalSnapshot(version int, payload json.RawMessage) error {
+	var s snapshot
+	if err := unmarshalPayload(payload, &s); err != nil {
+		return errors.Wrap(err)
+	}
+
+	t.Restore(s.ID, version)
+
+	return nil
 }
 
 // Create alters current token state and append changes to aggregate root
@@ -116,7 +119,7 @@ func (t *Token) Create(ctx context.Context, id uuid.UUID, info oauth2.TokenInfo)
 // Remove alters current token state and append changes to aggregate root
 func (t *Token) Remove(ctx context.Context) error {
 	if _, err := t.trackChange(ctx, WasRemoved{
-		ID: t.id,
+		ID: t.ID(),
 	}); err != nil {
 		return errors.Wrap(err)
 	}
@@ -125,29 +128,17 @@ func (t *Token) Remove(ctx context.Context) error {
 }
 
 func (t *Token) trackChange(ctx context.Context, e domain.RawEvent) (domain.Event, error) {
-	t.transition(e)
-
-	var (
-		event domain.Event
-		err   error
-	)
-	if i, hasIdentity := identity.FromContext(ctx); hasIdentity {
-		event, err = domain.NewEvent(t.id, StreamName, t.version, e, &i)
-	} else {
-		event, err = domain.NewEvent(t.id, StreamName, t.version, e, nil)
-	}
-	if err != nil {
-		return event, errors.Wrap(err)
-	}
-
-	t.changes = append(t.changes, event)
+	return t.TrackChange(ctx, t.transition, e)
+}
 
-	return event, nil
+// Replay applies stored events in order
+func (t *Token) Replay(events []domain.Event) error {
+	return t.Base.Replay(t.transition, events)
 }
 
 func (t *Token) transition(e domain.RawEvent) {
 	switch e := e.(type) {
 	case WasCreated:
-		t.id = e.ID
+		t.SetID(e.ID)
 	}
 }
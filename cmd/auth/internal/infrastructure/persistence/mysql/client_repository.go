@@ -12,6 +12,7 @@ import (
 	"github.com/vardius/go-api-boilerplate/cmd/auth/internal/infrastructure/persistence"
 	"github.com/vardius/go-api-boilerplate/pkg/application"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/mysql"
 )
 
 type clientRepository struct {
@@ -19,7 +20,7 @@ type clientRepository struct {
 }
 
 func (r *clientRepository) Get(ctx context.Context, id string) (persistence.Client, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, user_id, secret, domain, data FROM clients WHERE id=? LIMIT 1`, id)
+	row := mysql.ExecutorFromContext(ctx, r.db).QueryRowContext(ctx, fmt.Sprintf(`SELECT id, user_id, secret, domain, data FROM %s WHERE id=? LIMIT 1`, mysql.TableFromContext(ctx, "clients")), id)
 
 	client := Client{}
 
@@ -43,31 +44,21 @@ func (r *clientRepository) Add(ctx context.Context, c persistence.Client) error
 		Data:   c.GetData(),
 	}
 
-	stmt, err := r.db.PrepareContext(ctx, `INSERT INTO clients (id, user_id, secret, domain, data) VALUES (?,?,?,?,?)`)
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`INSERT IGNORE INTO %s (id, user_id, secret, domain, data) VALUES (?,?,?,?,?)`, mysql.TableFromContext(ctx, "clients")))
 	if err != nil {
 		return errors.Wrap(err)
 	}
 	defer stmt.Close()
 
-	result, err := stmt.ExecContext(ctx, client.ID, client.UserID, client.Secret, client.Domain, client.Data)
-	if err != nil {
+	if _, err := stmt.ExecContext(ctx, client.ID, client.UserID, client.Secret, client.Domain, client.Data); err != nil {
 		return errors.Wrap(err)
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return errors.Wrap(err)
-	}
-
-	if rows != 1 {
-		return errors.New("Did not add client")
-	}
-
 	return nil
 }
 
 func (r *clientRepository) Delete(ctx context.Context, id string) error {
-	stmt, err := r.db.PrepareContext(ctx, `DELETE FROM clients WHERE id=?`)
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id=?`, mysql.TableFromContext(ctx, "clients")))
 	if err != nil {
 		return errors.Wrap(err)
 	}
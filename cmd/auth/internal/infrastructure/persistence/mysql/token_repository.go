@@ -27,25 +27,25 @@ type tokenRepository struct {
 }
 
 func (r *tokenRepository) Get(ctx context.Context, id string) (persistence.Token, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, client_id, user_id, code, access, refresh, data FROM auth_tokens WHERE id=? LIMIT 1`, id)
+	row := mysql.ExecutorFromContext(ctx, r.db).QueryRowContext(ctx, fmt.Sprintf(`SELECT id, client_id, user_id, code, access, refresh, data FROM %s WHERE id=? LIMIT 1`, mysql.TableFromContext(ctx, "auth_tokens")), id)
 
 	return r.getTokenFromRow(row)
 }
 
 func (r *tokenRepository) GetByCode(ctx context.Context, code string) (persistence.Token, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, client_id, user_id, code, access, refresh, data FROM auth_tokens WHERE code=? LIMIT 1`, code)
+	row := mysql.ExecutorFromContext(ctx, r.db).QueryRowContext(ctx, fmt.Sprintf(`SELECT id, client_id, user_id, code, access, refresh, data FROM %s WHERE code=? LIMIT 1`, mysql.TableFromContext(ctx, "auth_tokens")), code)
 
 	return r.getTokenFromRow(row)
 }
 
 func (r *tokenRepository) GetByAccess(ctx context.Context, access string) (persistence.Token, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, client_id, user_id, code, access, refresh, data FROM auth_tokens WHERE access=? LIMIT 1`, access)
+	row := mysql.ExecutorFromContext(ctx, r.db).QueryRowContext(ctx, fmt.Sprintf(`SELECT id, client_id, user_id, code, access, refresh, data FROM %s WHERE access=? LIMIT 1`, mysql.TableFromContext(ctx, "auth_tokens")), access)
 
 	return r.getTokenFromRow(row)
 }
 
 func (r *tokenRepository) GetByRefresh(ctx context.Context, refresh string) (persistence.Token, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, client_id, user_id, code, access, refresh, data FROM auth_tokens WHERE refresh=? LIMIT 1`, refresh)
+	row := mysql.ExecutorFromContext(ctx, r.db).QueryRowContext(ctx, fmt.Sprintf(`SELECT id, client_id, user_id, code, access, refresh, data FROM %s WHERE refresh=? LIMIT 1`, mysql.TableFromContext(ctx, "auth_tokens")), refresh)
 
 	return r.getTokenFromRow(row)
 }
@@ -65,31 +65,21 @@ func (r *tokenRepository) Add(ctx context.Context, t persistence.Token) error {
 		Data: t.GetData(),
 	}
 
-	stmt, err := r.db.PrepareContext(ctx, `INSERT INTO auth_tokens (id, client_id, user_id, code, access, refresh, data) VALUES (?,?,?,?,?,?,?)`)
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`INSERT IGNORE INTO %s (id, client_id, user_id, code, access, refresh, data) VALUES (?,?,?,?,?,?,?)`, mysql.TableFromContext(ctx, "auth_tokens")))
 	if err != nil {
 		return errors.Wrap(fmt.Errorf("%w: Invalid token insert query: %s", application.ErrInternal, err))
 	}
 	defer stmt.Close()
 
-	result, err := stmt.ExecContext(ctx, token.ID, token.ClientID, token.UserID, token.Code, token.Access, token.Refresh, token.Data)
-	if err != nil {
+	if _, err := stmt.ExecContext(ctx, token.ID, token.ClientID, token.UserID, token.Code, token.Access, token.Refresh, token.Data); err != nil {
 		return errors.Wrap(fmt.Errorf("%w: Could not add token: %s", application.ErrInternal, err))
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return errors.Wrap(fmt.Errorf("%w: Could not get affected rows: %s", application.ErrInternal, err))
-	}
-
-	if rows != 1 {
-		return errors.Wrap(fmt.Errorf("%w: Did not add token", application.ErrInternal))
-	}
-
 	return nil
 }
 
 func (r *tokenRepository) Delete(ctx context.Context, id string) error {
-	stmt, err := r.db.PrepareContext(ctx, `DELETE FROM auth_tokens WHERE id=?`)
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id=?`, mysql.TableFromContext(ctx, "auth_tokens")))
 	if err != nil {
 		return errors.Wrap(fmt.Errorf("%w: Invalid token delete query: %s", application.ErrInternal, err))
 	}
@@ -127,7 +117,7 @@ func (r *tokenRepository) getTokenFromRow(row *sql.Row) (persistence.Token, erro
 }
 
 func (r *tokenRepository) GetByUserID(ctx context.Context, clientID string, userID uuid.UUID) ([]persistence.Token, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id, code, access, refresh, data FROM auth_tokens WHERE client_id=? AND user_id=?`, clientID, userID.String())
+	rows, err := mysql.ExecutorFromContext(ctx, r.db).QueryContext(ctx, fmt.Sprintf(`SELECT id, code, access, refresh, data FROM %s WHERE client_id=? AND user_id=?`, mysql.TableFromContext(ctx, "auth_tokens")), clientID, userID.String())
 	if err != nil {
 		return nil, errors.Wrap(err)
 	}
@@ -5,67 +5,76 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/vardius/go-api-boilerplate/cmd/auth/internal/domain/client"
-	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
 	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
 )
 
 type clientRepository struct {
-	eventStore eventstore.EventStore
-	eventBus   eventbus.EventBus
+	repository *aggregate.Repository
 }
 
 // Save current client changes to event store and publish each event with an event bus
-func (r *clientRepository) Save(ctx context.Context, u client.Client) error {
-	if err := r.eventStore.Store(ctx, u.Changes()); err != nil {
+func (r *clientRepository) Save(ctx context.Context, c client.Client) error {
+	if err := r.repository.Save(ctx, &c); err != nil {
 		return errors.Wrap(err)
 	}
 
-	for _, event := range u.Changes() {
-		if err := r.eventBus.Publish(ctx, event); err != nil {
-			return errors.Wrap(err)
-		}
-	}
-
 	return nil
 }
 
 // Save current client changes to event store and publish each event with an event bus
 // blocks until event handlers are finished
-func (r *clientRepository) SaveAndAcknowledge(ctx context.Context, u client.Client) error {
-	if err := r.eventStore.Store(ctx, u.Changes()); err != nil {
+func (r *clientRepository) SaveAndAcknowledge(ctx context.Context, c client.Client) error {
+	if err := r.repository.SaveAndAcknowledge(ctx, &c); err != nil {
 		return errors.Wrap(err)
 	}
 
-	for _, event := range u.Changes() {
-		if err := r.eventBus.PublishAndAcknowledge(ctx, event); err != nil {
-			return errors.Wrap(err)
-		}
-	}
-
 	return nil
 }
 
 // Get client with current state applied
 func (r *clientRepository) Get(ctx context.Context, id uuid.UUID) (client.Client, error) {
-	events, err := r.eventStore.GetStream(ctx, id, client.StreamName)
-	if err != nil {
+	c := client.New()
+	if err := r.repository.Load(ctx, id, &c); err != nil {
 		return client.Client{}, errors.Wrap(err)
 	}
 
-	if len(events) == 0 {
-		return client.Client{}, application.ErrNotFound
+	return c, nil
+}
+
+// GetAtVersion returns client state after given number of events were applied
+func (r *clientRepository) GetAtVersion(ctx context.Context, id uuid.UUID, version int) (client.Client, error) {
+	c := client.New()
+	if err := r.repository.LoadAtVersion(ctx, id, version, &c); err != nil {
+		return client.Client{}, errors.Wrap(err)
 	}
 
-	return client.FromHistory(events), nil
+	return c, nil
+}
+
+// GetAsOf returns client state from events that occurred at or before given time
+func (r *clientRepository) GetAsOf(ctx context.Context, id uuid.UUID, at time.Time) (client.Client, error) {
+	c := client.New()
+	if err := r.repository.LoadAsOf(ctx, id, at, &c); err != nil {
+		return client.Client{}, errors.Wrap(err)
+	}
+
+	return c, nil
 }
 
 // NewClientRepository creates new client event sourced repository
-func NewClientRepository(store eventstore.EventStore, bus eventbus.EventBus) client.Repository {
-	return &clientRepository{store, bus}
+// snapshot of aggregate root is taken after save whenever given policy allows it
+func NewClientRepository(store eventstore.EventStore, bus eventbus.EventBus, snapshotStore snapshotstore.SnapshotStore, snapshotPolicy snapshotstore.Policy, logger *log.Logger) client.Repository {
+	return &clientRepository{
+		repository: aggregate.NewRepository(store, bus, snapshotStore, snapshotPolicy, logger),
+	}
 }
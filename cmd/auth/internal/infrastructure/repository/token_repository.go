@@ -5,51 +5,66 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/vardius/go-api-boilerplate/cmd/auth/internal/domain/token"
-	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
 	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
 )
 
 type tokenRepository struct {
-	eventStore eventstore.EventStore
-	eventBus   eventbus.EventBus
+	repository *aggregate.Repository
 }
 
 // Save current token changes to event store and publish each event with an event bus
-func (r *tokenRepository) Save(ctx context.Context, u token.Token) error {
-	if err := r.eventStore.Store(ctx, u.Changes()); err != nil {
+func (r *tokenRepository) Save(ctx context.Context, t token.Token) error {
+	if err := r.repository.Save(ctx, &t); err != nil {
 		return errors.Wrap(err)
 	}
 
-	for _, event := range u.Changes() {
-		if err := r.eventBus.Publish(ctx, event); err != nil {
-			return errors.Wrap(err)
-		}
-	}
-
 	return nil
 }
 
 // Get token with current state applied
 func (r *tokenRepository) Get(ctx context.Context, id uuid.UUID) (token.Token, error) {
-	events, err := r.eventStore.GetStream(ctx, id, token.StreamName)
-	if err != nil {
+	t := token.New()
+	if err := r.repository.Load(ctx, id, &t); err != nil {
 		return token.Token{}, errors.Wrap(err)
 	}
 
-	if len(events) == 0 {
-		return token.Token{}, application.ErrNotFound
+	return t, nil
+}
+
+// GetAtVersion returns token state after given number of events were applied
+func (r *tokenRepository) GetAtVersion(ctx context.Context, id uuid.UUID, version int) (token.Token, error) {
+	t := token.New()
+	if err := r.repository.LoadAtVersion(ctx, id, version, &t); err != nil {
+		return token.Token{}, errors.Wrap(err)
 	}
 
-	return token.FromHistory(events), nil
+	return t, nil
+}
+
+// GetAsOf returns token state from events that occurred at or before given time
+func (r *tokenRepository) GetAsOf(ctx context.Context, id uuid.UUID, at time.Time) (token.Token, error) {
+	t := token.New()
+	if err := r.repository.LoadAsOf(ctx, id, at, &t); err != nil {
+		return token.Token{}, errors.Wrap(err)
+	}
+
+	return t, nil
 }
 
 // NewTokenRepository creates new token event sourced repository
-func NewTokenRepository(store eventstore.EventStore, bus eventbus.EventBus) token.Repository {
-	return &tokenRepository{store, bus}
+// snapshot of aggregate root is taken after save whenever given policy allows it
+func NewTokenRepository(store eventstore.EventStore, bus eventbus.EventBus, snapshotStore snapshotstore.SnapshotStore, snapshotPolicy snapshotstore.Policy, logger *log.Logger) token.Repository {
+	return &tokenRepository{
+		repository: aggregate.NewRepository(store, bus, snapshotStore, snapshotPolicy, logger),
+	}
 }
@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"math/rand"
+	"os"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
 	"github.com/vardius/gocontainer"
 	"google.golang.org/grpc"
 	grpchealth "google.golang.org/grpc/health"
@@ -24,11 +27,34 @@ import (
 	"github.com/vardius/go-api-boilerplate/pkg/auth"
 	"github.com/vardius/go-api-boilerplate/pkg/buildinfo"
 	commandbus "github.com/vardius/go-api-boilerplate/pkg/commandbus/memory"
+	commandbusmiddleware "github.com/vardius/go-api-boilerplate/pkg/commandbus/middleware"
+	"github.com/vardius/go-api-boilerplate/pkg/consumergroup"
+	mysqlconsumergroup "github.com/vardius/go-api-boilerplate/pkg/consumergroup/mysql"
+	"github.com/vardius/go-api-boilerplate/pkg/deadletter"
+	mysqldeadletter "github.com/vardius/go-api-boilerplate/pkg/deadletter/mysql"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	baseeventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus"
 	eventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
-	eventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/mysql"
+	eventbusmiddleware "github.com/vardius/go-api-boilerplate/pkg/eventbus/middleware"
+	baseeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	fileeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/file"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	mysqleventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/mysql"
+	postgreseventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/postgres"
 	grpcutils "github.com/vardius/go-api-boilerplate/pkg/grpc"
+	"github.com/vardius/go-api-boilerplate/pkg/idempotency"
+	mysqlidempotency "github.com/vardius/go-api-boilerplate/pkg/idempotency/mysql"
 	"github.com/vardius/go-api-boilerplate/pkg/log"
 	"github.com/vardius/go-api-boilerplate/pkg/mysql"
+	baseoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox"
+	memoryoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox/memory"
+	mysqloutbox "github.com/vardius/go-api-boilerplate/pkg/outbox/mysql"
+	postgresoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox/postgres"
+	"github.com/vardius/go-api-boilerplate/pkg/postgres"
+	"github.com/vardius/go-api-boilerplate/pkg/projection"
+	mysqlprojection "github.com/vardius/go-api-boilerplate/pkg/projection/mysql"
+	basesnapshotstore "github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
+	snapshotstore "github.com/vardius/go-api-boilerplate/pkg/snapshotstore/mysql"
 )
 
 func init() {
@@ -54,7 +80,14 @@ func main() {
 		nil,
 		nil,
 	)
-	commandBus := commandbus.New(config.Env.CommandBus.QueueSize, logger)
+	commandBus := commandbusmiddleware.NewCommandBus(
+		commandbus.New(config.Env.CommandBus.QueueSize, logger),
+		commandbusmiddleware.Logger(logger),
+		commandbusmiddleware.Metrics(),
+		commandbusmiddleware.Recover(logger),
+		commandbusmiddleware.Authorize(),
+		commandbusmiddleware.Validate(),
+	)
 
 	mysqlConnection := mysql.NewConnection(
 		ctx,
@@ -83,10 +116,99 @@ func main() {
 	)
 	defer grpcAuthConn.Close()
 
-	eventStore := eventstore.New(mysqlConnection)
-	eventBus := eventbus.New(config.Env.EventBus.QueueSize, logger)
-	tokenRepository := repository.NewTokenRepository(eventStore, eventBus)
-	clientRepository := repository.NewClientRepository(eventStore, eventBus)
+	var (
+		eventStore  baseeventstore.EventStore
+		eventOutbox baseoutbox.Outbox
+	)
+	switch config.Env.EventStore.Driver {
+	case "mysql":
+		if config.Env.EventBus.Outbox {
+			eventStore = mysqleventstore.NewWithOutbox(mysqlConnection, "auth")
+			eventOutbox = mysqloutbox.New(mysqlConnection, "auth")
+		} else {
+			eventStore = mysqleventstore.New(mysqlConnection)
+		}
+	case "postgres":
+		postgresConnection := postgres.NewConnection(
+			ctx,
+			postgres.ConnectionConfig{
+				Host:            config.Env.Postgres.Host,
+				Port:            config.Env.Postgres.Port,
+				User:            config.Env.Postgres.User,
+				Pass:            config.Env.Postgres.Pass,
+				Database:        config.Env.Postgres.Database,
+				SSLMode:         config.Env.Postgres.SSLMode,
+				ConnMaxLifetime: config.Env.Postgres.ConnMaxLifetime,
+				MaxIdleConns:    config.Env.Postgres.MaxIdleConns,
+				MaxOpenConns:    config.Env.Postgres.MaxOpenConns,
+			},
+			logger,
+		)
+		defer postgresConnection.Close()
+		if config.Env.EventBus.Outbox {
+			eventStore = postgreseventstore.NewWithOutbox(postgresConnection, "auth")
+			eventOutbox = postgresoutbox.New(postgresConnection, "auth")
+		} else {
+			eventStore = postgreseventstore.New(postgresConnection)
+		}
+	case "file":
+		fileEventStore, err := fileeventstore.New(config.Env.EventStore.FileDir, fileeventstore.Config{
+			SegmentSize:  config.Env.EventStore.FileSegmentSize,
+			Sync:         fileeventstore.SyncMode(config.Env.EventStore.FileSync),
+			SyncInterval: config.Env.EventStore.FileSyncInterval,
+		})
+		if err != nil {
+			panic(err)
+		}
+		defer fileEventStore.Close()
+		eventStore = fileEventStore
+	case "memory":
+		eventStore = memoryeventstore.New()
+	default:
+		panic(fmt.Errorf("unsupported event store driver: %s", config.Env.EventStore.Driver))
+	}
+	if config.Env.EventBus.Outbox && eventOutbox == nil {
+		// file and memory event stores can not share transaction with the outbox
+		memoryOutbox := memoryoutbox.New()
+		eventStore = baseoutbox.WithOutbox(eventStore, memoryOutbox)
+		eventOutbox = memoryOutbox
+	}
+	// upcasters transform payloads of evolved events read back from the event store to their current schema version
+	eventUpcasters := domain.NewUpcasterRegistry()
+	eventStore = baseeventstore.WithUpcasting(eventStore, eventUpcasters)
+	snapshotStore := snapshotstore.New(mysqlConnection)
+	snapshotPolicy := basesnapshotstore.EveryNEvents(config.Env.EventStore.SnapshotFrequency)
+	memoryEventBus := eventbus.New(config.Env.EventBus.QueueSize, logger)
+	if config.Env.EventBus.OrderedDelivery {
+		memoryEventBus = eventbus.NewOrdered(config.Env.EventBus.QueueSize, logger)
+	}
+	// failing event handlers are retried, events they could not handle are kept as dead letters
+	eventBus := deadletter.NewEventBus(
+		// middleware wraps handlers together with their retries, timeout applies to all attempts
+		eventbusmiddleware.NewEventBus(
+			memoryEventBus,
+			eventbusmiddleware.Trace(),
+			eventbusmiddleware.Logger(logger),
+			eventbusmiddleware.Metrics(),
+			eventbusmiddleware.Recover(logger),
+			eventbusmiddleware.Timeout(config.Env.App.EventHandlerTimeout),
+		),
+		mysqldeadletter.New(mysqlConnection, "auth"),
+		eventStore,
+		deadletter.RetryPolicy{
+			MaxAttempts:    config.Env.EventBus.RetryMaxAttempts,
+			InitialBackoff: config.Env.EventBus.RetryInitialBackoff,
+			MaxBackoff:     config.Env.EventBus.RetryMaxBackoff,
+		},
+		logger,
+	)
+	// repositories publish through outbox relay so events are not lost when process dies after storing them
+	var repositoryEventBus baseeventbus.EventBus = eventBus
+	if eventOutbox != nil {
+		repositoryEventBus = baseoutbox.WithDeferredPublish(eventBus, eventOutbox, config.Env.EventBus.OutboxClaimTimeout)
+	}
+	tokenRepository := repository.NewTokenRepository(eventStore, repositoryEventBus, snapshotStore, snapshotPolicy, logger)
+	clientRepository := repository.NewClientRepository(eventStore, repositoryEventBus, snapshotStore, snapshotPolicy, logger)
 	tokenPersistenceRepository := persistence.NewTokenRepository(mysqlConnection)
 	clientPersistenceRepository := persistence.NewClientRepository(mysqlConnection)
 	tokenStore := oauth2.NewTokenStore(tokenPersistenceRepository, commandBus)
@@ -119,20 +241,50 @@ func main() {
 		panic(err)
 	}
 
-	if err := eventBus.Subscribe(ctx, (token.WasCreated{}).GetType(), eventhandler.WhenTokenWasCreated(mysqlConnection, tokenPersistenceRepository)); err != nil {
-		panic(err)
-	}
-	if err := eventBus.Subscribe(ctx, (token.WasRemoved{}).GetType(), eventhandler.WhenTokenWasRemoved(mysqlConnection, tokenPersistenceRepository)); err != nil {
-		panic(err)
-	}
-	if err := eventBus.Subscribe(ctx, (client.WasCreated{}).GetType(), eventhandler.WhenClientWasCreated(mysqlConnection, clientPersistenceRepository)); err != nil {
-		panic(err)
-	}
-	if err := eventBus.Subscribe(ctx, (client.WasRemoved{}).GetType(), eventhandler.WhenClientWasRemoved(mysqlConnection, clientPersistenceRepository)); err != nil {
-		panic(err)
+	// projections resume from their checkpoint after restart and handle events in order they were stored
+	projections := consumergroup.New("auth-projections", eventStore, eventBus, mysqlconsumergroup.New(mysqlConnection), logger, consumergroup.Config{
+		BatchSize:    config.Env.EventBus.ConsumerGroupBatchSize,
+		PollInterval: config.Env.EventBus.ConsumerGroupPollInterval,
+		// projections were built by handlers subscribed to the bus before, past events are already handled
+		StartAtEnd: true,
+	})
+	// redelivered events are skipped, events handlers failed to handle are handled again so they have to be safe to repeat
+	ledger := mysqlidempotency.New(mysqlConnection)
+	projections.Subscribe((token.WasCreated{}).GetType(), idempotency.Handler(ledger, "auth.WhenTokenWasCreated", eventhandler.WhenTokenWasCreated(mysqlConnection, tokenPersistenceRepository)))
+	projections.Subscribe((token.WasRemoved{}).GetType(), idempotency.Handler(ledger, "auth.WhenTokenWasRemoved", eventhandler.WhenTokenWasRemoved(mysqlConnection, tokenPersistenceRepository)))
+	projections.Subscribe((client.WasCreated{}).GetType(), idempotency.Handler(ledger, "auth.WhenClientWasCreated", eventhandler.WhenClientWasCreated(mysqlConnection, clientPersistenceRepository)))
+	projections.Subscribe((client.WasRemoved{}).GetType(), idempotency.Handler(ledger, "auth.WhenClientWasRemoved", eventhandler.WhenClientWasRemoved(mysqlConnection, clientPersistenceRepository)))
+
+	// read models are rebuilt with handlers writing to shadow tables, see pkg/projection
+	projectionRunner := projection.NewRunner(eventStore, mysqlprojection.New(mysqlConnection), logger, projection.Config{
+		BatchSize: config.Env.EventBus.ConsumerGroupBatchSize,
+	}, projection.Projection{
+		Name:  "auth_tokens",
+		Table: "auth_tokens",
+		Handlers: map[string]baseeventbus.EventHandler{
+			(token.WasCreated{}).GetType(): eventhandler.WhenTokenWasCreated(mysqlConnection, tokenPersistenceRepository),
+			(token.WasRemoved{}).GetType(): eventhandler.WhenTokenWasRemoved(mysqlConnection, tokenPersistenceRepository),
+		},
+	}, projection.Projection{
+		Name:  "clients",
+		Table: "clients",
+		Handlers: map[string]baseeventbus.EventHandler{
+			(client.WasCreated{}).GetType(): eventhandler.WhenClientWasCreated(mysqlConnection, clientPersistenceRepository),
+			(client.WasRemoved{}).GetType(): eventhandler.WhenClientWasRemoved(mysqlConnection, clientPersistenceRepository),
+		},
+	})
+
+	// auth rebuild-projection <name> rebuilds read model and exits, running service has to be stopped meanwhile
+	if flag.Arg(0) == "rebuild-projection" {
+		if err := projectionRunner.Rebuild(ctx, flag.Arg(1), nil); err != nil {
+			logger.Critical(ctx, "%v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	app.AddAdapters(
+		projections,
 		authhttp.NewAdapter(
 			fmt.Sprintf("%s:%d", config.Env.HTTP.Host, config.Env.HTTP.Port),
 			router,
@@ -145,14 +297,26 @@ func main() {
 		),
 	)
 
-	if config.Env.App.Environment == "development" {
+	if eventOutbox != nil {
 		app.AddAdapters(
-			application.NewDebugAdapter(
-				fmt.Sprintf("%s:%d", config.Env.Debug.Host, config.Env.Debug.Port),
-			),
+			baseoutbox.NewRelay(eventOutbox, eventStore, eventBus, logger, baseoutbox.RelayConfig{
+				PollInterval: config.Env.EventBus.OutboxPollInterval,
+				BatchSize:    config.Env.EventBus.OutboxBatchSize,
+				MaxAttempts:  config.Env.EventBus.OutboxMaxAttempts,
+				ClaimTimeout: config.Env.EventBus.OutboxClaimTimeout,
+			}),
 		)
 	}
 
+	if config.Env.App.Environment == "development" {
+		debugAdapter := application.NewDebugAdapter(
+			fmt.Sprintf("%s:%d", config.Env.Debug.Host, config.Env.Debug.Port),
+		)
+		debugAdapter.Handle("/debug/projections", projection.NewDebugHandler(projectionRunner, projections))
+
+		app.AddAdapters(debugAdapter)
+	}
+
 	app.WithShutdownTimeout(config.Env.App.ShutdownTimeout)
 	app.Run(ctx)
 }
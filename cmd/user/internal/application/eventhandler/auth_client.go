@@ -0,0 +1,36 @@
+package eventhandler
+
+import (
+	"context"
+	systemErrors "errors"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/cmd/auth/proto"
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/application/config"
+	appidentity "github.com/vardius/go-api-boilerplate/cmd/user/internal/application/identity"
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+// authClientOf returns credentials of user auth client, client is created unless previous attempt
+// of handling the same event already created it, handlers are called again when they fail afterwards
+func authClientOf(ctx context.Context, userID uuid.UUID, identityProvider appidentity.Provider, authClient proto.AuthenticationServiceClient) (clientID, clientSecret string, err error) {
+	i, err := identityProvider.GetByUserIDAndDomain(ctx, userID, config.Env.App.Domain)
+	if err == nil {
+		return i.ClientID.String(), i.ClientSecret, nil
+	}
+	if !systemErrors.Is(err, application.ErrNotFound) {
+		return "", "", errors.Wrap(err)
+	}
+
+	resp, err := authClient.CreateClient(ctx, &proto.CreateClientRequest{
+		UserID: userID.String(),
+		Domain: config.Env.App.Domain,
+	})
+	if err != nil {
+		return "", "", errors.Wrap(err)
+	}
+
+	return resp.ClientID, resp.ClientSecret, nil
+}
@@ -2,8 +2,6 @@ package eventhandler
 
 import (
 	"context"
-	"encoding/json"
-	"time"
 
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/application/config"
 	appidentity "github.com/vardius/go-api-boilerplate/cmd/user/internal/application/identity"
@@ -17,12 +15,9 @@ import (
 
 // WhenUserAccessTokenWasRequested handles event
 func WhenUserAccessTokenWasRequested(tokenProvider oauth2.TokenProvider, identityProvider appidentity.Provider) eventbus.EventHandler {
-	fn := func(parentCtx context.Context, event domain.Event) error {
-		ctx, cancel := context.WithTimeout(parentCtx, time.Second*120)
-		defer cancel()
-
+	fn := func(ctx context.Context, event domain.Event) error {
 		e := user.WasRegisteredWithEmail{}
-		if err := json.Unmarshal(event.Payload, &e); err != nil {
+		if err := user.DecodeEventInto(event, &e); err != nil {
 			return errors.Wrap(err)
 		}
 
@@ -3,38 +3,26 @@ package eventhandler
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
-	"time"
 
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence"
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/mysql"
 )
 
 // WhenUserConnectedWithFacebook handles event
 func WhenUserConnectedWithFacebook(db *sql.DB, repository persistence.UserRepository) eventbus.EventHandler {
-	fn := func(parentCtx context.Context, event domain.Event) error {
-		ctx, cancel := context.WithTimeout(parentCtx, time.Second*120)
-		defer cancel()
-
+	fn := func(ctx context.Context, event domain.Event) error {
 		e := user.ConnectedWithFacebook{}
-		if err := json.Unmarshal(event.Payload, &e); err != nil {
-			return errors.Wrap(err)
-		}
-
-		tx, err := db.BeginTx(ctx, nil)
-		if err != nil {
-			return errors.Wrap(err)
-		}
-		defer tx.Rollback()
-
-		if err := repository.UpdateFacebookID(ctx, e.ID.String(), e.FacebookID); err != nil {
+		if err := user.DecodeEventInto(event, &e); err != nil {
 			return errors.Wrap(err)
 		}
 
-		if err := tx.Commit(); err != nil {
+		if err := mysql.InTransaction(ctx, db, func(ctx context.Context) error {
+			return repository.UpdateFacebookID(ctx, e.ID.String(), e.FacebookID)
+		}); err != nil {
 			return errors.Wrap(err)
 		}
 
@@ -0,0 +1,30 @@
+package eventhandler
+
+import (
+	"context"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// WhenUserWasForgotten handles event
+func WhenUserWasForgotten(repository persistence.UserRepository) eventbus.EventHandler {
+	fn := func(ctx context.Context, event domain.Event) error {
+		e := user.WasForgotten{}
+
+		if err := user.DecodeEventInto(event, &e); err != nil {
+			return errors.Wrap(err)
+		}
+
+		if err := repository.Delete(ctx, e.ID.String()); err != nil {
+			return errors.Wrap(err)
+		}
+
+		return nil
+	}
+
+	return fn
+}
@@ -3,11 +3,9 @@ package eventhandler
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
-	"time"
 
 	"github.com/vardius/go-api-boilerplate/cmd/auth/proto"
-	"github.com/vardius/go-api-boilerplate/cmd/user/internal/application/config"
+	appidentity "github.com/vardius/go-api-boilerplate/cmd/user/internal/application/identity"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/application/mailer"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence"
@@ -16,43 +14,35 @@ import (
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
 	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/mysql"
 )
 
 // WhenUserWasRegisteredWithEmail handles event
-func WhenUserWasRegisteredWithEmail(db *sql.DB, repository persistence.UserRepository, tokenProvider oauth2.TokenProvider, authClient proto.AuthenticationServiceClient) eventbus.EventHandler {
-	fn := func(parentCtx context.Context, event domain.Event) error {
-		ctx, cancel := context.WithTimeout(parentCtx, time.Second*120)
-		defer cancel()
-
+func WhenUserWasRegisteredWithEmail(db *sql.DB, repository persistence.UserRepository, tokenProvider oauth2.TokenProvider, identityProvider appidentity.Provider, authClient proto.AuthenticationServiceClient) eventbus.EventHandler {
+	fn := func(ctx context.Context, event domain.Event) error {
 		e := user.WasRegisteredWithEmail{}
 
-		if err := json.Unmarshal(event.Payload, &e); err != nil {
-			return errors.Wrap(err)
-		}
-
-		tx, err := db.BeginTx(ctx, nil)
-		if err != nil {
+		if err := user.DecodeEventInto(event, &e); err != nil {
 			return errors.Wrap(err)
 		}
-		defer tx.Rollback()
 
-		if err := repository.Add(ctx, userWasRegisteredWithEmailModel{e}); err != nil {
+		if err := mysql.InTransaction(ctx, db, func(ctx context.Context) error {
+			return repository.Add(ctx, userWasRegisteredWithEmailModel{e})
+		}); err != nil {
 			return errors.Wrap(err)
 		}
 
-		if err := tx.Commit(); err != nil {
-			return errors.Wrap(err)
+		// auth client of the user already exists, rebuilt read model must not create another one
+		if executioncontext.Has(ctx, executioncontext.REBUILD) {
+			return nil
 		}
 
-		clientResp, err := authClient.CreateClient(ctx, &proto.CreateClientRequest{
-			UserID: e.ID.String(),
-			Domain: config.Env.App.Domain,
-		})
+		clientID, clientSecret, err := authClientOf(ctx, e.ID, identityProvider, authClient)
 		if err != nil {
 			return errors.Wrap(err)
 		}
 
-		token, err := tokenProvider.RetrievePasswordCredentialsToken(ctx, clientResp.ClientID, clientResp.ClientSecret, string(e.Email), []string{"all"})
+		token, err := tokenProvider.RetrievePasswordCredentialsToken(ctx, clientID, clientSecret, string(e.Email), []string{"all"})
 		if err != nil {
 			return errors.Wrap(err)
 		}
@@ -3,48 +3,39 @@ package eventhandler
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
-	"time"
 
 	"github.com/vardius/go-api-boilerplate/cmd/auth/proto"
-	"github.com/vardius/go-api-boilerplate/cmd/user/internal/application/config"
+	appidentity "github.com/vardius/go-api-boilerplate/cmd/user/internal/application/identity"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence"
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/mysql"
 )
 
 // WhenUserWasRegisteredWithGoogle handles event
-func WhenUserWasRegisteredWithGoogle(db *sql.DB, repository persistence.UserRepository, authClient proto.AuthenticationServiceClient) eventbus.EventHandler {
-	fn := func(parentCtx context.Context, event domain.Event) error {
-		ctx, cancel := context.WithTimeout(parentCtx, time.Second*120)
-		defer cancel()
-
+func WhenUserWasRegisteredWithGoogle(db *sql.DB, repository persistence.UserRepository, identityProvider appidentity.Provider, authClient proto.AuthenticationServiceClient) eventbus.EventHandler {
+	fn := func(ctx context.Context, event domain.Event) error {
 		e := user.WasRegisteredWithGoogle{}
 
-		if err := json.Unmarshal(event.Payload, &e); err != nil {
-			return errors.Wrap(err)
-		}
-
-		tx, err := db.BeginTx(ctx, nil)
-		if err != nil {
+		if err := user.DecodeEventInto(event, &e); err != nil {
 			return errors.Wrap(err)
 		}
-		defer tx.Rollback()
 
-		if err := repository.Add(ctx, userWasRegisteredWithGoogleModel{e}); err != nil {
+		if err := mysql.InTransaction(ctx, db, func(ctx context.Context) error {
+			return repository.Add(ctx, userWasRegisteredWithGoogleModel{e})
+		}); err != nil {
 			return errors.Wrap(err)
 		}
 
-		if err := tx.Commit(); err != nil {
-			return errors.Wrap(err)
+		// client was created when event was first handled
+		if executioncontext.Has(ctx, executioncontext.REBUILD) {
+			return nil
 		}
 
-		if _, err := authClient.CreateClient(ctx, &proto.CreateClientRequest{
-			UserID: e.ID.String(),
-			Domain: config.Env.App.Domain,
-		}); err != nil {
+		if _, _, err := authClientOf(ctx, e.ID, identityProvider, authClient); err != nil {
 			return errors.Wrap(err)
 		}
 
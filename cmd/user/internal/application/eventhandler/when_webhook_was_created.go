@@ -0,0 +1,54 @@
+package eventhandler
+
+import (
+	"context"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/webhook"
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// WhenWebhookWasCreated handles event
+func WhenWebhookWasCreated(repository persistence.WebhookRepository) eventbus.EventHandler {
+	fn := func(ctx context.Context, event domain.Event) error {
+		e := webhook.WasCreated{}
+
+		if err := webhook.DecodeEventInto(event, &e); err != nil {
+			return errors.Wrap(err)
+		}
+
+		if err := repository.Add(ctx, webhookWasCreatedModel{e}); err != nil {
+			return errors.Wrap(err)
+		}
+
+		return nil
+	}
+
+	return fn
+}
+
+type webhookWasCreatedModel struct {
+	e webhook.WasCreated
+}
+
+// GetID the id
+func (w webhookWasCreatedModel) GetID() string {
+	return w.e.ID.String()
+}
+
+// GetURL the url
+func (w webhookWasCreatedModel) GetURL() string {
+	return w.e.URL
+}
+
+// GetEventTypes event types
+func (w webhookWasCreatedModel) GetEventTypes() []string {
+	return w.e.EventTypes
+}
+
+// GetSecret the secret
+func (w webhookWasCreatedModel) GetSecret() string {
+	return w.e.Secret
+}
@@ -15,6 +15,7 @@ import (
 
 type Provider interface {
 	GetByUserEmail(ctx context.Context, userEmail, domain string) (identity.Identity, error)
+	GetByUserIDAndDomain(ctx context.Context, userID uuid.UUID, domain string) (identity.Identity, error)
 }
 
 type identityProvider struct {
@@ -50,6 +51,29 @@ LIMIT 1
 	return i, nil
 }
 
+func (p *identityProvider) GetByUserIDAndDomain(ctx context.Context, userID uuid.UUID, domain string) (identity.Identity, error) {
+	var i identity.Identity
+
+	row := p.db.QueryRowContext(ctx, `
+SELECT c.id, c.secret, u.id, u.email_address
+FROM clients AS c
+  INNER JOIN users AS u ON u.id = c.user_id
+WHERE c.user_id = ?
+  AND c.domain = ?
+LIMIT 1
+`, userID, domain)
+
+	err := row.Scan(&i.ClientID, &i.ClientSecret, &i.UserID, &i.UserEmail)
+	switch {
+	case systemErrors.Is(err, sql.ErrNoRows):
+		return i, errors.Wrap(fmt.Errorf("%w: credentials not found: %s", application.ErrNotFound, err))
+	case err != nil:
+		return i, errors.Wrap(err)
+	}
+
+	return i, nil
+}
+
 func (p *identityProvider) GetByUserID(ctx context.Context, userID, clientID uuid.UUID) (identity.Identity, error) {
 	var i identity.Identity
 
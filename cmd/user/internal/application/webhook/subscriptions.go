@@ -0,0 +1,54 @@
+/*
+Package webhook provides webhook subscriptions to the dispatcher
+*/
+package webhook
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	basewebhook "github.com/vardius/go-api-boilerplate/pkg/webhook"
+)
+
+// pageSize is a number of webhooks read from repository at once
+const pageSize = 100
+
+type subscriptions struct {
+	repository persistence.WebhookRepository
+}
+
+// NewSubscriptions provides webhooks read model as dispatcher subscriptions
+func NewSubscriptions(repository persistence.WebhookRepository) basewebhook.Subscriptions {
+	return &subscriptions{repository: repository}
+}
+
+func (s *subscriptions) FindAll(ctx context.Context) ([]basewebhook.Subscription, error) {
+	var list []basewebhook.Subscription
+	for offset := int32(0); ; offset += pageSize {
+		webhooks, err := s.repository.FindAll(ctx, pageSize, offset)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		for _, w := range webhooks {
+			id, err := uuid.Parse(w.GetID())
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+
+			list = append(list, basewebhook.Subscription{
+				ID:         id,
+				URL:        w.GetURL(),
+				Secret:     w.GetSecret(),
+				EventTypes: w.GetEventTypes(),
+			})
+		}
+
+		if len(webhooks) < pageSize {
+			return list, nil
+		}
+	}
+}
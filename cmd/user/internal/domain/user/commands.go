@@ -13,6 +13,7 @@ import (
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/keystore"
 )
 
 const (
@@ -26,6 +27,8 @@ const (
 	RegisterUserWithFacebook = "register-user-with-facebook"
 	// RegisterUserWithGoogle command bus contract
 	RegisterUserWithGoogle = "register-user-with-google"
+	// ForgetUser command bus contract
+	ForgetUser = "forget-user"
 )
 
 // NewCommandFromPayload builds command by contract from json payload
@@ -56,6 +59,11 @@ func NewCommandFromPayload(contract string, payload []byte) (domain.Command, err
 		err := unmarshalPayload(payload, &requestAccessToken)
 
 		return requestAccessToken, err
+	case ForgetUser:
+		forget := Forget{}
+		err := unmarshalPayload(payload, &forget)
+
+		return forget, err
 	default:
 		return nil, errors.New("Invalid command contract")
 	}
@@ -71,48 +79,48 @@ func (c RequestAccessToken) GetName() string {
 	return fmt.Sprintf("%T", c)
 }
 
+// Validate returns error if command payload is not valid
+func (c RequestAccessToken) Validate() error {
+	return c.Email.IsValid()
+}
+
 // OnRequestAccessToken creates command handler
 func OnRequestAccessToken(repository Repository, db *sql.DB) commandbus.CommandHandler {
-	fn := func(ctx context.Context, command domain.Command) error {
-		c, ok := command.(RequestAccessToken)
-		if !ok {
-			return errors.New("invalid command")
-		}
-
+	fn := func(ctx context.Context, c RequestAccessToken) (commandbus.Result, error) {
 		var id string
 		row := db.QueryRowContext(ctx, `SELECT id FROM users WHERE email_address=? LIMIT 1`, c.Email.String())
 		if err := row.Scan(&id); err != nil {
 			if systemErrors.Is(err, sql.ErrNoRows) {
-				return errors.Wrap(fmt.Errorf("%s: %w", err, application.ErrNotFound))
+				return commandbus.Result{}, errors.Wrap(fmt.Errorf("%s: %w", err, application.ErrNotFound))
 			}
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 		if id == "" {
-			return application.ErrNotFound
+			return commandbus.Result{}, application.ErrNotFound
 		}
 
 		userID, err := uuid.Parse(id)
 		if err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		u, err := repository.Get(ctx, userID)
 		if err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if err := u.RequestAccessToken(ctx); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if err := repository.Save(executioncontext.WithFlag(ctx, executioncontext.LIVE), u); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
-		return nil
+		return commandbus.Result{ID: u.ID(), Version: u.Version()}, nil
 	}
 
-	return fn
+	return commandbus.NewHandler(fn)
 }
 
 // ChangeEmailAddress command
@@ -126,42 +134,47 @@ func (c ChangeEmailAddress) GetName() string {
 	return fmt.Sprintf("%T", c)
 }
 
+// Validate returns error if command payload is not valid
+func (c ChangeEmailAddress) Validate() error {
+	return c.Email.IsValid()
+}
+
+// OwnerID returns id of the only user allowed to change the email address
+func (c ChangeEmailAddress) OwnerID() uuid.UUID {
+	return c.ID
+}
+
 // OnChangeEmailAddress creates command handler
 func OnChangeEmailAddress(repository Repository, db *sql.DB) commandbus.CommandHandler {
-	fn := func(ctx context.Context, command domain.Command) error {
-		c, ok := command.(ChangeEmailAddress)
-		if !ok {
-			return errors.New("invalid command")
-		}
-
+	fn := func(ctx context.Context, c ChangeEmailAddress) (commandbus.Result, error) {
 		var totalUsers int32
 
 		row := db.QueryRowContext(ctx, `SELECT COUNT(distinct_id) FROM users WHERE email_address=?`, c.Email.String())
 		if err := row.Scan(&totalUsers); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if totalUsers != 0 {
-			return errors.Wrap(application.ErrInvalid)
+			return commandbus.Result{}, errors.Wrap(application.ErrInvalid)
 		}
 
 		u, err := repository.Get(ctx, c.ID)
 		if err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if err := u.ChangeEmailAddress(ctx, c.Email); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if err := repository.Save(executioncontext.WithFlag(ctx, executioncontext.LIVE), u); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
-		return nil
+		return commandbus.Result{ID: u.ID(), Version: u.Version()}, nil
 	}
 
-	return fn
+	return commandbus.NewHandler(fn)
 }
 
 // RegisterWithEmail command
@@ -174,43 +187,43 @@ func (c RegisterWithEmail) GetName() string {
 	return fmt.Sprintf("%T", c)
 }
 
+// Validate returns error if command payload is not valid
+func (c RegisterWithEmail) Validate() error {
+	return c.Email.IsValid()
+}
+
 // OnRegisterWithEmail creates command handler
 func OnRegisterWithEmail(repository Repository, db *sql.DB) commandbus.CommandHandler {
-	fn := func(ctx context.Context, command domain.Command) error {
-		c, ok := command.(RegisterWithEmail)
-		if !ok {
-			return errors.New("invalid command")
-		}
-
+	fn := func(ctx context.Context, c RegisterWithEmail) (commandbus.Result, error) {
 		var totalUsers int32
 
 		row := db.QueryRowContext(ctx, `SELECT COUNT(distinct_id) FROM users WHERE email_address=?`, c.Email.String())
 		if err := row.Scan(&totalUsers); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if totalUsers != 0 {
-			return errors.Wrap(application.ErrInvalid)
+			return commandbus.Result{}, errors.Wrap(application.ErrInvalid)
 		}
 
 		id, err := uuid.NewRandom()
 		if err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		u := New()
 		if err := u.RegisterWithEmail(ctx, id, c.Email); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if err := repository.Save(executioncontext.WithFlag(ctx, executioncontext.LIVE), u); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
-		return nil
+		return commandbus.Result{ID: u.ID(), Version: u.Version()}, nil
 	}
 
-	return fn
+	return commandbus.NewHandler(fn)
 }
 
 // RegisterWithFacebook command
@@ -225,61 +238,65 @@ func (c RegisterWithFacebook) GetName() string {
 	return fmt.Sprintf("%T", c)
 }
 
+// Validate returns error if command payload is not valid
+func (c RegisterWithFacebook) Validate() error {
+	if c.FacebookID == "" {
+		return errors.New("missing facebook id")
+	}
+
+	return c.Email.IsValid()
+}
+
 // OnRegisterWithFacebook creates command handler
 func OnRegisterWithFacebook(repository Repository, db *sql.DB) commandbus.CommandHandler {
-	fn := func(ctx context.Context, command domain.Command) error {
-		c, ok := command.(RegisterWithFacebook)
-		if !ok {
-			return errors.New("invalid command")
-		}
-
+	fn := func(ctx context.Context, c RegisterWithFacebook) (commandbus.Result, error) {
 		var id, emailAddress, facebookID string
 
 		row := db.QueryRowContext(ctx, `SELECT id, email_address, facebook_id FROM users WHERE email_address=? OR facebook_id=? LIMIT 1`, c.Email.String(), c.FacebookID)
 		if err := row.Scan(&id, &emailAddress, &facebookID); err != nil && !systemErrors.Is(err, sql.ErrNoRows) {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if facebookID == c.FacebookID {
-			return errors.Wrap(application.ErrInvalid)
+			return commandbus.Result{}, errors.Wrap(application.ErrInvalid)
 		}
 
 		var u User
 		if emailAddress == string(c.Email) {
 			userID, err := uuid.Parse(id)
 			if err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 
-			u, err := repository.Get(ctx, userID)
+			u, err = repository.Get(ctx, userID)
 			if err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 
 			if err := u.ConnectWithFacebook(ctx, c.FacebookID, c.AccessToken); err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 		} else {
 			id, err := uuid.NewRandom()
 			if err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 
 			u = New()
 
 			if err := u.RegisterWithFacebook(ctx, id, c.Email, c.FacebookID, c.AccessToken); err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 		}
 
 		if err := repository.SaveAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), u); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
-		return nil
+		return commandbus.Result{ID: u.ID(), Version: u.Version()}, nil
 	}
 
-	return fn
+	return commandbus.NewHandler(fn)
 }
 
 // RegisterWithGoogle command
@@ -294,58 +311,104 @@ func (c RegisterWithGoogle) GetName() string {
 	return fmt.Sprintf("%T", c)
 }
 
+// Validate returns error if command payload is not valid
+func (c RegisterWithGoogle) Validate() error {
+	if c.GoogleID == "" {
+		return errors.New("missing google id")
+	}
+
+	return c.Email.IsValid()
+}
+
 // OnRegisterWithGoogle creates command handler
 func OnRegisterWithGoogle(repository Repository, db *sql.DB) commandbus.CommandHandler {
-	fn := func(ctx context.Context, command domain.Command) error {
-		c, ok := command.(RegisterWithGoogle)
-		if !ok {
-			return errors.New("invalid command")
-		}
-
+	fn := func(ctx context.Context, c RegisterWithGoogle) (commandbus.Result, error) {
 		var id, emailAddress, googleID string
 
 		row := db.QueryRowContext(ctx, `SELECT id, email_address, google_id FROM users WHERE email_address=? OR google_id=? LIMIT 1`, c.Email.String(), c.GoogleID)
 		if err := row.Scan(&id, &emailAddress, &googleID); err != nil && !systemErrors.Is(err, sql.ErrNoRows) {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
 		if googleID == c.GoogleID {
-			return errors.Wrap(application.ErrInvalid)
+			return commandbus.Result{}, errors.Wrap(application.ErrInvalid)
 		}
 
 		var u User
 		if emailAddress == string(c.Email) {
 			userID, err := uuid.Parse(id)
 			if err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 
-			u, err := repository.Get(ctx, userID)
+			u, err = repository.Get(ctx, userID)
 			if err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 
 			if err := u.ConnectWithGoogle(ctx, c.GoogleID, c.AccessToken); err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 		} else {
 			id, err := uuid.NewRandom()
 			if err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 
 			u = New()
 			if err := u.RegisterWithGoogle(ctx, id, c.Email, c.GoogleID, c.AccessToken); err != nil {
-				return errors.Wrap(err)
+				return commandbus.Result{}, errors.Wrap(err)
 			}
 		}
 
 		if err := repository.SaveAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), u); err != nil {
-			return errors.Wrap(err)
+			return commandbus.Result{}, errors.Wrap(err)
+		}
+
+		return commandbus.Result{ID: u.ID(), Version: u.Version()}, nil
+	}
+
+	return commandbus.NewHandler(fn)
+}
+
+// Forget command
+type Forget struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// GetName returns command name
+func (c Forget) GetName() string {
+	return fmt.Sprintf("%T", c)
+}
+
+// OwnerID returns id of the only user allowed to forget the user
+func (c Forget) OwnerID() uuid.UUID {
+	return c.ID
+}
+
+// OnForget creates command handler
+// users can only forget themselves, see Forget.OwnerID, personal data is shredded by destroying user encryption key
+func OnForget(repository Repository, keyStore keystore.KeyStore) commandbus.CommandHandler {
+	fn := func(ctx context.Context, c Forget) (commandbus.Result, error) {
+		u, err := repository.Get(ctx, c.ID)
+		if err != nil {
+			return commandbus.Result{}, errors.Wrap(err)
+		}
+
+		if err := u.Forget(ctx); err != nil {
+			return commandbus.Result{}, errors.Wrap(err)
+		}
+
+		if err := repository.Save(executioncontext.WithFlag(ctx, executioncontext.LIVE), u); err != nil {
+			return commandbus.Result{}, errors.Wrap(err)
+		}
+
+		if err := keyStore.Forget(ctx, c.ID); err != nil {
+			return commandbus.Result{}, errors.Wrap(err)
 		}
 
-		return nil
+		return commandbus.Result{ID: u.ID(), Version: u.Version()}, nil
 	}
 
-	return fn
+	return commandbus.NewHandler(fn)
 }
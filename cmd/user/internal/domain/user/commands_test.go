@@ -33,6 +33,42 @@ func TestUnmarshalRegisterWithGoogle(t *testing.T) {
 	testUnmarshalCommand(t, testJSON, &RegisterWithGoogle{})
 }
 
+func TestUnmarshalForget(t *testing.T) {
+	testJSON := []byte(`{"id":"4dded431-acee-4078-86c6-9dffa5efba1e"}`)
+
+	testUnmarshalCommand(t, testJSON, &Forget{})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		command interface{ Validate() error }
+		valid   bool
+	}{
+		{"register with email", RegisterWithEmail{Email: "test@test.com"}, true},
+		{"register without email", RegisterWithEmail{}, false},
+		{"register with facebook", RegisterWithFacebook{Email: "test@test.com", FacebookID: "1"}, true},
+		{"register without facebook id", RegisterWithFacebook{Email: "test@test.com"}, false},
+		{"register with google", RegisterWithGoogle{Email: "test@test.com", GoogleID: "1"}, true},
+		{"register with google invalid email", RegisterWithGoogle{Email: "test", GoogleID: "1"}, false},
+		{"change email address", ChangeEmailAddress{ID: uuid.New(), Email: "test@test.com"}, true},
+		{"change to invalid email address", ChangeEmailAddress{ID: uuid.New(), Email: "test"}, false},
+		{"request access token without email", RequestAccessToken{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.command.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("expected command to be valid, got: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected command to be invalid")
+			}
+		})
+	}
+}
+
 func testUnmarshalCommand(t *testing.T, testJSON []byte, c interface{}) {
 	if err := json.Unmarshal(testJSON, c); err != nil {
 		t.Fatal(err)
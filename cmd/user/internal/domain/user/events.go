@@ -1,90 +1,129 @@
 package user
 
 import (
-	"fmt"
-
 	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
 )
 
+// eventRegistry maps stable user event type names to raw events
+var eventRegistry = newEventRegistry()
+
+func newEventRegistry() *domain.EventRegistry {
+	registry := domain.NewEventRegistry()
+	registry.MustRegister(
+		AccessTokenWasRequested{},
+		EmailAddressWasChanged{},
+		WasRegisteredWithEmail{},
+		WasRegisteredWithFacebook{},
+		ConnectedWithFacebook{},
+		WasRegisteredWithGoogle{},
+		ConnectedWithGoogle{},
+		WasForgotten{},
+	)
+
+	return registry
+}
+
+// DecodeEvent decodes user domain event into its typed raw event
+func DecodeEvent(event domain.Event) (domain.RawEvent, error) {
+	return eventRegistry.Decode(event)
+}
+
+// DecodeEventInto decodes user domain event into given raw event pointer
+func DecodeEventInto(event domain.Event, target domain.RawEvent) error {
+	return eventRegistry.DecodeInto(event, target)
+}
+
 // AccessTokenWasRequested event
 type AccessTokenWasRequested struct {
 	ID    uuid.UUID    `json:"id"`
-	Email EmailAddress `json:"email"`
+	Email EmailAddress `json:"email" pii:"true"`
 }
 
 // GetType returns event type
 func (e AccessTokenWasRequested) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "user.AccessTokenWasRequested"
 }
 
 // EmailAddressWasChanged event
 type EmailAddressWasChanged struct {
 	ID    uuid.UUID    `json:"id"`
-	Email EmailAddress `json:"email"`
+	Email EmailAddress `json:"email" pii:"true"`
 }
 
 // GetType returns event type
 func (e EmailAddressWasChanged) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "user.EmailAddressWasChanged"
 }
 
 // WasRegisteredWithEmail event
 type WasRegisteredWithEmail struct {
 	ID    uuid.UUID    `json:"id"`
-	Email EmailAddress `json:"email"`
+	Email EmailAddress `json:"email" pii:"true"`
 }
 
 // GetType returns event type
 func (e WasRegisteredWithEmail) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "user.WasRegisteredWithEmail"
 }
 
 // WasRegisteredWithFacebook event
 type WasRegisteredWithFacebook struct {
 	ID          uuid.UUID    `json:"id"`
-	Email       EmailAddress `json:"email"`
-	FacebookID  string       `json:"facebook_id"`
-	AccessToken string       `json:"access_token"`
+	Email       EmailAddress `json:"email" pii:"true"`
+	FacebookID  string       `json:"facebook_id" pii:"true"`
+	AccessToken string       `json:"access_token" pii:"true"`
 }
 
 // GetType returns event type
 func (e WasRegisteredWithFacebook) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "user.WasRegisteredWithFacebook"
 }
 
 // ConnectedWithFacebook event
 type ConnectedWithFacebook struct {
 	ID          uuid.UUID `json:"id"`
-	FacebookID  string    `json:"facebook_id"`
-	AccessToken string    `json:"access_token"`
+	FacebookID  string    `json:"facebook_id" pii:"true"`
+	AccessToken string    `json:"access_token" pii:"true"`
 }
 
 // GetType returns event type
 func (e ConnectedWithFacebook) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "user.ConnectedWithFacebook"
 }
 
 // WasRegisteredWithGoogle event
 type WasRegisteredWithGoogle struct {
 	ID          uuid.UUID    `json:"id"`
-	Email       EmailAddress `json:"email"`
-	GoogleID    string       `json:"google_id"`
-	AccessToken string       `json:"access_token"`
+	Email       EmailAddress `json:"email" pii:"true"`
+	GoogleID    string       `json:"google_id" pii:"true"`
+	AccessToken string       `json:"access_token" pii:"true"`
 }
 
 // GetType returns event type
 func (e WasRegisteredWithGoogle) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "user.WasRegisteredWithGoogle"
 }
 
 // ConnectedWithGoogle event
 type ConnectedWithGoogle struct {
 	ID          uuid.UUID `json:"id"`
-	GoogleID    string    `json:"google_id"`
-	AccessToken string    `json:"access_token"`
+	GoogleID    string    `json:"google_id" pii:"true"`
+	AccessToken string    `json:"access_token" pii:"true"`
 }
 
 // GetType returns event type
 func (e ConnectedWithGoogle) GetType() string {
-	return fmt.Sprintf("%T", e)
+	return "user.ConnectedWithGoogle"
+}
+
+// WasForgotten event, personal data of the user can no longer be read
+type WasForgotten struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// GetType returns event type
+func (e WasForgotten) GetType() string {
+	return "user.WasForgotten"
 }
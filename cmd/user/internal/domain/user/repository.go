@@ -2,14 +2,21 @@ package user
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 // Repository allows to get/save events from/to event store
+// Save returns eventstore.ErrConcurrencyConflict if the stream was modified
+// since aggregate root has been loaded, caller can reload it and retry the command
 type Repository interface {
 	Save(ctx context.Context, u User) error
 	Get(ctx context.Context, id uuid.UUID) (User, error)
+	// GetAtVersion returns user state after given number of events were applied
+	GetAtVersion(ctx context.Context, id uuid.UUID, version int) (User, error)
+	// GetAsOf returns user state from events that occurred at or before given time
+	GetAsOf(ctx context.Context, id uuid.UUID, at time.Time) (User, error)
 
 	// Block and returns after event handlers are finished
 	SaveAndAcknowledge(ctx context.Context, u User) error
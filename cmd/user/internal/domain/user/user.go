@@ -5,14 +5,14 @@ package user
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
 
 	"github.com/google/uuid"
 
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
-	"github.com/vardius/go-api-boilerplate/pkg/identity"
 )
 
 // StreamName for user domain
@@ -20,99 +20,76 @@ var StreamName = fmt.Sprintf("%T", User{})
 
 // User aggregate root
 type User struct {
-	id      uuid.UUID
-	version int
-	changes []domain.Event
+	aggregate.Base
 
 	email EmailAddress
 }
 
+// snapshot is a serialized user state
+type snapshot struct {
+	ID    uuid.UUID    `json:"id"`
+	Email EmailAddress `json:"email"`
+}
+
 // New creates an User
 func New() User {
-	return User{}
+	return User{Base: aggregate.NewBase(StreamName, eventRegistry)}
 }
 
 // FromHistory loads current aggregate root state by applying all events in order
-func FromHistory(events []domain.Event) User {
+func FromHistory(events []domain.Event) (User, error) {
+	u := New()
+	if err := u.Replay(events); err != nil {
+		return u, errors.Wrap(err)
+	}
+
+	return u, nil
+}
+
+// FromSnapshot restores aggregate root state from snapshot taken at given version
+// and applies events that occurred after it in order
+func FromSnapshot(version int, payload json.RawMessage, events []domain.Event) (User, error) {
 	u := New()
+	if err := u.UnmarshalSnapshot(version, payload); err != nil {
+		return u, errors.Wrap(err)
+	}
 
-	for _, domainEvent := range events {
-		var e domain.RawEvent
-
-		switch domainEvent.Metadata.Type {
-		case (AccessTokenWasRequested{}).GetType():
-			accessTokenWasRequested := AccessTokenWasRequested{}
-			if err := unmarshalPayload(domainEvent.Payload, &accessTokenWasRequested); err != nil {
-				log.Panicf("Error while trying to unmarshal user event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = accessTokenWasRequested
-		case (EmailAddressWasChanged{}).GetType():
-			emailAddressWasChanged := EmailAddressWasChanged{}
-			if err := unmarshalPayload(domainEvent.Payload, &emailAddressWasChanged); err != nil {
-				log.Panicf("Error while trying to unmarshal user event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = emailAddressWasChanged
-		case (WasRegisteredWithEmail{}).GetType():
-			wasRegisteredWithEmail := WasRegisteredWithEmail{}
-			if err := unmarshalPayload(domainEvent.Payload, &wasRegisteredWithEmail); err != nil {
-				log.Panicf("Error while trying to unmarshal user event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = wasRegisteredWithEmail
-		case (WasRegisteredWithFacebook{}).GetType():
-			wasRegisteredWithFacebook := WasRegisteredWithFacebook{}
-			if err := unmarshalPayload(domainEvent.Payload, &wasRegisteredWithFacebook); err != nil {
-				log.Panicf("Error while trying to unmarshal user event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = wasRegisteredWithFacebook
-		case (ConnectedWithFacebook{}).GetType():
-			connectedWithFacebook := ConnectedWithFacebook{}
-			if err := unmarshalPayload(domainEvent.Payload, &connectedWithFacebook); err != nil {
-				log.Panicf("Error while trying to unmarshal user event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = connectedWithFacebook
-		case (WasRegisteredWithGoogle{}).GetType():
-			wasRegisteredWithGoogle := WasRegisteredWithGoogle{}
-			if err := unmarshalPayload(domainEvent.Payload, &wasRegisteredWithGoogle); err != nil {
-				log.Panicf("Error while trying to unmarshal user event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = wasRegisteredWithGoogle
-		case (ConnectedWithGoogle{}).GetType():
-			connectedWithGoogle := ConnectedWithGoogle{}
-			if err := unmarshalPayload(domainEvent.Payload, &connectedWithGoogle); err != nil {
-				log.Panicf("Error while trying to unmarshal user event %s. %s\n", domainEvent.Metadata.Type, err)
-			}
-
-			e = connectedWithGoogle
-		default:
-			log.Panicf("Unhandled user event %s\n", domainEvent.Metadata.Type)
-		}
-
-		u.transition(e)
-		u.version++
+	if err := u.Replay(events); err != nil {
+		return u, errors.Wrap(err)
 	}
 
-	return u
+	return u, nil
 }
 
-// ID returns aggregate root id
-func (u User) ID() uuid.UUID {
-	return u.id
+// Email returns current user email address
+func (u User) Email() EmailAddress {
+	return u.email
 }
 
-// Version returns current aggregate root version
-func (u User) Version() int {
-	return u.version
+// MarshalSnapshot serializes current aggregate root state
+func (u User) MarshalSnapshot() (json.RawMessage, error) {
+	data, err := json.Marshal(snapshot{
+		ID:    u.ID(),
+		Email: u.email,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return data, nil
 }
 
-// Changes returns all new applied events
-func (u User) Changes() []domain.Event {
-	return u.changes
+// UnmarshalSnapshot restores aggregate root state taken at given version
+func (u *User) UnmarshalSnapshot(version int, payload json.RawMessage) error {
+	var s snapshot
+	if err := unmarshalPayload(payload, &s); err != nil {
+		return errors.Wrap(err)
+	}
+
+	u.Restore(s.ID, version)
+	u.email = s.Email
+
+	return nil
 }
 
 // RegisterWithEmail alters current user state and append changes to aggregate root
@@ -144,7 +121,7 @@ func (u *User) RegisterWithGoogle(ctx context.Context, id uuid.UUID, email Email
 // ConnectWithGoogle alters current user state and append changes to aggregate root
 func (u *User) ConnectWithGoogle(ctx context.Context, googleID, accessToken string) error {
 	if _, err := u.trackChange(ctx, ConnectedWithGoogle{
-		ID:          u.id,
+		ID:          u.ID(),
 		GoogleID:    googleID,
 		AccessToken: accessToken,
 	}); err != nil {
@@ -171,7 +148,7 @@ func (u *User) RegisterWithFacebook(ctx context.Context, id uuid.UUID, email Ema
 // ConnectWithFacebook alters current user state and append changes to aggregate root
 func (u *User) ConnectWithFacebook(ctx context.Context, facebookID, accessToken string) error {
 	if _, err := u.trackChange(ctx, ConnectedWithFacebook{
-		ID:          u.id,
+		ID:          u.ID(),
 		FacebookID:  facebookID,
 		AccessToken: accessToken,
 	}); err != nil {
@@ -184,7 +161,7 @@ func (u *User) ConnectWithFacebook(ctx context.Context, facebookID, accessToken
 // ChangeEmailAddress alters current user state and append changes to aggregate root
 func (u *User) ChangeEmailAddress(ctx context.Context, email EmailAddress) error {
 	if _, err := u.trackChange(ctx, EmailAddressWasChanged{
-		ID:    u.id,
+		ID:    u.ID(),
 		Email: email,
 	}); err != nil {
 		return errors.Wrap(err)
@@ -193,10 +170,22 @@ func (u *User) ChangeEmailAddress(ctx context.Context, email EmailAddress) error
 	return nil
 }
 
+// Forget alters current user state and append changes to aggregate root,
+// personal data of the user has to be shredded by destroying its key afterwards
+func (u *User) Forget(ctx context.Context) error {
+	if _, err := u.trackChange(ctx, WasForgotten{
+		ID: u.ID(),
+	}); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
 // RequestAccessToken dispatches AccessTokenWasRequested event
 func (u *User) RequestAccessToken(ctx context.Context) error {
 	if _, err := u.trackChange(ctx, AccessTokenWasRequested{
-		ID:    u.id,
+		ID:    u.ID(),
 		Email: u.email,
 	}); err != nil {
 		return errors.Wrap(err)
@@ -206,38 +195,28 @@ func (u *User) RequestAccessToken(ctx context.Context) error {
 }
 
 func (u *User) trackChange(ctx context.Context, e domain.RawEvent) (domain.Event, error) {
-	u.transition(e)
-
-	var (
-		event domain.Event
-		err   error
-	)
-	if i, hasIdentity := identity.FromContext(ctx); hasIdentity {
-		event, err = domain.NewEvent(u.id, StreamName, u.version, e, &i)
-	} else {
-		event, err = domain.NewEvent(u.id, StreamName, u.version, e, nil)
-	}
-	if err != nil {
-		return event, errors.Wrap(err)
-	}
-
-	u.changes = append(u.changes, event)
+	return u.TrackChange(ctx, u.transition, e)
+}
 
-	return event, nil
+// Replay applies stored events in order
+func (u *User) Replay(events []domain.Event) error {
+	return u.Base.Replay(u.transition, events)
 }
 
 func (u *User) transition(e domain.RawEvent) {
 	switch e := e.(type) {
 	case WasRegisteredWithEmail:
-		u.id = e.ID
+		u.SetID(e.ID)
 		u.email = e.Email
 	case WasRegisteredWithGoogle:
-		u.id = e.ID
+		u.SetID(e.ID)
 		u.email = e.Email
 	case WasRegisteredWithFacebook:
-		u.id = e.ID
+		u.SetID(e.ID)
 		u.email = e.Email
 	case EmailAddressWasChanged:
 		u.email = e.Email
+	case WasForgotten:
+		u.email = ""
 	}
 }
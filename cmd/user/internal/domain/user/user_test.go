@@ -0,0 +1,101 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+)
+
+func TestFromSnapshot(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	u := New()
+	if err := u.RegisterWithEmail(ctx, id, "first@test.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := u.MarshalSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.ChangeEmailAddress(ctx, "second@test.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := FromSnapshot(1, payload, u.Changes()[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.ID() != id {
+		t.Errorf("ID() = %s, want %s", restored.ID(), id)
+	}
+	if restored.Version() != u.Version() {
+		t.Errorf("Version() = %d, want %d", restored.Version(), u.Version())
+	}
+	if restored.email != "second@test.com" {
+		t.Errorf("email = %s, want second@test.com", restored.email)
+	}
+
+	history, err := FromHistory(u.Changes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Version() != restored.Version() || history.email != restored.email {
+		t.Errorf("snapshot state %v does not match history state %v", restored, history)
+	}
+}
+
+func TestForget(t *testing.T) {
+	ctx := context.Background()
+
+	u := New()
+	if err := u.RegisterWithEmail(ctx, uuid.New(), "test@test.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Forget(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if u.email != "" {
+		t.Errorf("email = %s, want empty", u.email)
+	}
+
+	registered := u.Changes()[0]
+	if len(registered.PersonalData) != 1 || registered.PersonalData[0] != "email" {
+		t.Errorf("PersonalData = %v, want [email]", registered.PersonalData)
+	}
+
+	history, err := FromHistory(u.Changes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Version() != 2 || history.email != "" {
+		t.Errorf("unexpected state after replay %v", history)
+	}
+}
+
+func TestFromHistoryUnknownEvent(t *testing.T) {
+	ctx := context.Background()
+
+	u := New()
+	if err := u.RegisterWithEmail(ctx, uuid.New(), "test@test.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	events := u.Changes()
+	if events[0].Metadata.Type != "user.WasRegisteredWithEmail" {
+		t.Errorf("Type = %s, want stable user.WasRegisteredWithEmail", events[0].Metadata.Type)
+	}
+
+	events[0].Metadata.Type = "renamed.WasRegisteredWithEmail"
+	if _, err := FromHistory(events); !errors.Is(err, domain.ErrUnknownEventType) {
+		t.Errorf("expected unknown event type error, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/identity"
+)
+
+// Create command
+type Create struct {
+	ID         uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret"`
+}
+
+// GetName returns command name
+func (c Create) GetName() string {
+	return fmt.Sprintf("%T", c)
+}
+
+// Validate returns error if command payload is not valid
+func (c Create) Validate() error {
+	if err := validateURL(c.URL); err != nil {
+		return errors.Wrap(err)
+	}
+	if err := validateEventTypes(c.EventTypes); err != nil {
+		return errors.Wrap(err)
+	}
+	if err := validateSecret(c.Secret); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// RequiredRole returns role required to create webhook
+func (c Create) RequiredRole() identity.Role {
+	return identity.RoleAdmin
+}
+
+// OnCreate creates command handler
+func OnCreate(repository Repository) commandbus.CommandHandler {
+	fn := func(ctx context.Context, c Create) (commandbus.Result, error) {
+		w := New()
+		if err := w.Create(ctx, c.ID, c.URL, c.EventTypes, c.Secret); err != nil {
+			return commandbus.Result{}, errors.Wrap(err)
+		}
+
+		// we block here until event handler is done
+		// so webhook is listed and receives events as soon as it was created
+		if err := repository.SaveAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), w); err != nil {
+			return commandbus.Result{}, errors.Wrap(err)
+		}
+
+		return commandbus.Result{ID: w.ID(), Version: w.Version()}, nil
+	}
+
+	return commandbus.NewHandler(fn)
+}
+
+// Remove command
+type Remove struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// GetName returns command name
+func (c Remove) GetName() string {
+	return fmt.Sprintf("%T", c)
+}
+
+// RequiredRole returns role required to remove webhook
+func (c Remove) RequiredRole() identity.Role {
+	return identity.RoleAdmin
+}
+
+// OnRemove creates command handler
+func OnRemove(repository Repository) commandbus.CommandHandler {
+	fn := func(ctx context.Context, c Remove) (commandbus.Result, error) {
+		w, err := repository.Get(ctx, c.ID)
+		if err != nil {
+			return commandbus.Result{}, errors.Wrap(err)
+		}
+
+		if err := w.Remove(ctx); err != nil {
+			return commandbus.Result{}, errors.Wrap(err)
+		}
+
+		if err := repository.SaveAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), w); err != nil {
+			return commandbus.Result{}, errors.Wrap(err)
+		}
+
+		return commandbus.Result{ID: w.ID(), Version: w.Version()}, nil
+	}
+
+	return commandbus.NewHandler(fn)
+}
@@ -0,0 +1,19 @@
+package webhook
+
+import (
+	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+)
+
+// ErrInvalidURL is when webhook endpoint is not an absolute http url.
+var ErrInvalidURL = fmt.Errorf("%w: invalid webhook url", application.ErrInvalid)
+
+// ErrInvalidEventTypes is when webhook event types are missing or can not be subscribed to.
+var ErrInvalidEventTypes = fmt.Errorf("%w: invalid webhook event types", application.ErrInvalid)
+
+// ErrInvalidSecret is when webhook secret is too short to sign requests with.
+var ErrInvalidSecret = fmt.Errorf("%w: invalid webhook secret", application.ErrInvalid)
+
+// ErrAlreadyRemoved is when webhook was removed already.
+var ErrAlreadyRemoved = fmt.Errorf("%w: webhook is already removed", application.ErrConflict)
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+)
+
+// eventRegistry maps stable webhook event type names to raw events
+var eventRegistry = newEventRegistry()
+
+func newEventRegistry() *domain.EventRegistry {
+	registry := domain.NewEventRegistry()
+	registry.MustRegister(
+		WasCreated{},
+		WasRemoved{},
+	)
+
+	return registry
+}
+
+// DecodeEvent decodes webhook domain event into its typed raw event
+func DecodeEvent(event domain.Event) (domain.RawEvent, error) {
+	return eventRegistry.Decode(event)
+}
+
+// DecodeEventInto decodes webhook domain event into given raw event pointer
+func DecodeEventInto(event domain.Event, target domain.RawEvent) error {
+	return eventRegistry.DecodeInto(event, target)
+}
+
+// WasCreated event
+type WasCreated struct {
+	ID         uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret"`
+}
+
+// GetType returns event type
+func (e WasCreated) GetType() string {
+	return "webhook.WasCreated"
+}
+
+// WasRemoved event
+type WasRemoved struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// GetType returns event type
+func (e WasRemoved) GetType() string {
+	return "webhook.WasRemoved"
+}
@@ -0,0 +1,18 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository allows to get/save events from/to event store
+// Save returns eventstore.ErrConcurrencyConflict if the stream was modified
+// since aggregate root has been loaded, caller can reload it and retry the command
+type Repository interface {
+	Save(ctx context.Context, w Webhook) error
+	Get(ctx context.Context, id uuid.UUID) (Webhook, error)
+
+	// Block and returns after event handlers are finished
+	SaveAndAcknowledge(ctx context.Context, w Webhook) error
+}
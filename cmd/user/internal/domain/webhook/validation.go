@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// MinSecretLength is a minimal length of secret webhook requests are signed with
+const MinSecretLength = 16
+
+func validateURL(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return errors.Wrap(fmt.Errorf("%w: %s", ErrInvalidURL, err))
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Wrap(fmt.Errorf("%w: absolute http or https url required", ErrInvalidURL))
+	}
+
+	return nil
+}
+
+// validateEventTypes rejects patterns matching webhook events,
+// they carry secrets of other subscriptions
+func validateEventTypes(eventTypes []string) error {
+	if len(eventTypes) == 0 {
+		return errors.Wrap(fmt.Errorf("%w: at least one event type required", ErrInvalidEventTypes))
+	}
+
+	for _, eventType := range eventTypes {
+		if err := eventbus.ValidateTopic(eventType); err != nil {
+			return errors.Wrap(fmt.Errorf("%w: %s", ErrInvalidEventTypes, err))
+		}
+
+		if eventType == eventbus.AllEvents || eventType == eventbus.StreamTopic(StreamName) || strings.HasPrefix(eventType, "webhook.") {
+			return errors.Wrap(fmt.Errorf("%w: %s would include webhook events", ErrInvalidEventTypes, eventType))
+		}
+	}
+
+	return nil
+}
+
+func validateSecret(secret string) error {
+	if len(secret) < MinSecretLength {
+		return errors.Wrap(fmt.Errorf("%w: at least %d characters required", ErrInvalidSecret, MinSecretLength))
+	}
+
+	return nil
+}
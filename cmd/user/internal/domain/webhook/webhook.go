@@ -0,0 +1,165 @@
+/*
+Package webhook holds webhook subscription domain logic
+*/
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+// StreamName for webhook domain
+var StreamName = fmt.Sprintf("%T", Webhook{})
+
+// Webhook aggregate root
+type Webhook struct {
+	aggregate.Base
+
+	url        string
+	eventTypes []string
+	removed    bool
+}
+
+// snapshot is a serialized webhook state, secret is not part of it as no command depends on it
+type snapshot struct {
+	ID         uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Removed    bool      `json:"removed"`
+}
+
+// New creates an Webhook
+func New() Webhook {
+	return Webhook{Base: aggregate.NewBase(StreamName, eventRegistry)}
+}
+
+// FromHistory loads current aggregate root state by applying all events in order
+func FromHistory(events []domain.Event) (Webhook, error) {
+	w := New()
+	if err := w.Replay(events); err != nil {
+		return w, errors.Wrap(err)
+	}
+
+	return w, nil
+}
+
+// FromSnapshot restores aggregate root state from snapshot taken at given version
+// and applies events that occurred after it in order
+func FromSnapshot(version int, payload json.RawMessage, events []domain.Event) (Webhook, error) {
+	w := New()
+	if err := w.UnmarshalSnapshot(version, payload); err != nil {
+		return w, errors.Wrap(err)
+	}
+
+	if err := w.Replay(events); err != nil {
+		return w, errors.Wrap(err)
+	}
+
+	return w, nil
+}
+
+// URL returns current webhook endpoint
+func (w Webhook) URL() string {
+	return w.url
+}
+
+// EventTypes returns event types webhook is subscribed to
+func (w Webhook) EventTypes() []string {
+	return w.eventTypes
+}
+
+// MarshalSnapshot serializes current aggregate root state
+func (w Webhook) MarshalSnapshot() (json.RawMessage, error) {
+	data, err := json.Marshal(snapshot{
+		ID:         w.ID(),
+		URL:        w.url,
+		EventTypes: w.eventTypes,
+		Removed:    w.removed,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return data, nil
+}
+
+// UnmarshalSnapshot restores aggregate root state taken at given version
+func (w *Webhook) UnmarshalSnapshot(version int, payload json.RawMessage) error {
+	var s snapshot
+	if err := unmarshalPayload(payload, &s); err != nil {
+		return errors.Wrap(err)
+	}
+
+	w.Restore(s.ID, version)
+	w.url = s.URL
+	w.eventTypes = s.EventTypes
+	w.removed = s.Removed
+
+	return nil
+}
+
+// Create alters current webhook state and append changes to aggregate root
+func (w *Webhook) Create(ctx context.Context, id uuid.UUID, url string, eventTypes []string, secret string) error {
+	if err := validateURL(url); err != nil {
+		return errors.Wrap(err)
+	}
+	if err := validateEventTypes(eventTypes); err != nil {
+		return errors.Wrap(err)
+	}
+	if err := validateSecret(secret); err != nil {
+		return errors.Wrap(err)
+	}
+
+	if _, err := w.trackChange(ctx, WasCreated{
+		ID:         id,
+		URL:        url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	}); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// Remove alters current webhook state and append changes to aggregate root
+func (w *Webhook) Remove(ctx context.Context) error {
+	if w.removed {
+		return errors.Wrap(fmt.Errorf("%w: %s", ErrAlreadyRemoved, w.ID()))
+	}
+
+	if _, err := w.trackChange(ctx, WasRemoved{
+		ID: w.ID(),
+	}); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (w *Webhook) trackChange(ctx context.Context, e domain.RawEvent) (domain.Event, error) {
+	return w.TrackChange(ctx, w.transition, e)
+}
+
+// Replay applies stored events in order
+func (w *Webhook) Replay(events []domain.Event) error {
+	return w.Base.Replay(w.transition, events)
+}
+
+func (w *Webhook) transition(e domain.RawEvent) {
+	switch e := e.(type) {
+	case WasCreated:
+		w.SetID(e.ID)
+		w.url = e.URL
+		w.eventTypes = e.EventTypes
+	case WasRemoved:
+		w.removed = true
+	}
+}
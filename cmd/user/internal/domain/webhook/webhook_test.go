@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+)
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	secret := "0123456789abcdef"
+
+	tests := []struct {
+		name       string
+		url        string
+		eventTypes []string
+		secret     string
+		err        error
+	}{
+		{"valid", "https://example.com/hook", []string{"user.WasRegisteredWithEmail", "user.*"}, secret, nil},
+		{"relative url", "/hook", []string{"user.*"}, secret, ErrInvalidURL},
+		{"not http url", "ftp://example.com/hook", []string{"user.*"}, secret, ErrInvalidURL},
+		{"no event types", "https://example.com/hook", nil, secret, ErrInvalidEventTypes},
+		{"invalid pattern", "https://example.com/hook", []string{"user*"}, secret, ErrInvalidEventTypes},
+		{"all events", "https://example.com/hook", []string{"*"}, secret, ErrInvalidEventTypes},
+		{"webhook events", "https://example.com/hook", []string{"webhook.*"}, secret, ErrInvalidEventTypes},
+		{"webhook stream", "https://example.com/hook", []string{"stream:" + StreamName}, secret, ErrInvalidEventTypes},
+		{"short secret", "https://example.com/hook", []string{"user.*"}, "secret", ErrInvalidSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Create{ID: uuid.New(), URL: tt.url, EventTypes: tt.eventTypes, Secret: tt.secret}
+			if err := c.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("expected command validation error %v, got %v", tt.err, err)
+			}
+
+			w := New()
+			err := w.Create(ctx, c.ID, c.URL, c.EventTypes, c.Secret)
+
+			if tt.err == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(w.Changes()) != 1 {
+					t.Errorf("expected 1 change, got %d", len(w.Changes()))
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.err) || !errors.Is(err, application.ErrInvalid) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestFromSnapshot(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	w := New()
+	if err := w.Create(ctx, id, "https://example.com/hook", []string{"user.*"}, "0123456789abcdef"); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := w.MarshalSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Remove(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := FromSnapshot(1, payload, w.Changes()[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.ID() != id {
+		t.Errorf("ID() = %s, want %s", restored.ID(), id)
+	}
+	if restored.URL() != "https://example.com/hook" {
+		t.Errorf("URL() = %s, want https://example.com/hook", restored.URL())
+	}
+	if !restored.removed {
+		t.Error("expected restored webhook to be removed")
+	}
+
+	if err := restored.Remove(ctx); !errors.Is(err, ErrAlreadyRemoved) {
+		t.Errorf("expected ErrAlreadyRemoved, got %v", err)
+	}
+}
@@ -25,7 +25,7 @@ type userRepository struct {
 }
 
 func (r *userRepository) FindAll(ctx context.Context, limit, offset int32) ([]persistence.User, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id, email_address, facebook_id, google_id FROM users ORDER BY distinct_id ASC LIMIT ? OFFSET ?`, limit, offset)
+	rows, err := mysql.ExecutorFromContext(ctx, r.db).QueryContext(ctx, fmt.Sprintf(`SELECT id, email_address, facebook_id, google_id FROM %s ORDER BY distinct_id ASC LIMIT ? OFFSET ?`, mysql.TableFromContext(ctx, "users")), limit, offset)
 	if err != nil {
 		return nil, errors.Wrap(err)
 	}
@@ -50,7 +50,7 @@ func (r *userRepository) FindAll(ctx context.Context, limit, offset int32) ([]pe
 }
 
 func (r *userRepository) Get(ctx context.Context, id string) (persistence.User, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, email_address, facebook_id, google_id FROM users WHERE id=? LIMIT 1`, id)
+	row := mysql.ExecutorFromContext(ctx, r.db).QueryRowContext(ctx, fmt.Sprintf(`SELECT id, email_address, facebook_id, google_id FROM %s WHERE id=? LIMIT 1`, mysql.TableFromContext(ctx, "users")), id)
 
 	user := User{}
 
@@ -79,7 +79,7 @@ func (r *userRepository) Add(ctx context.Context, u persistence.User) error {
 		}},
 	}
 
-	stmt, err := r.db.PrepareContext(ctx, `INSERT IGNORE INTO users (id, email_address, facebook_id, google_id) VALUES (?,?,?,?)`)
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`INSERT IGNORE INTO %s (id, email_address, facebook_id, google_id) VALUES (?,?,?,?)`, mysql.TableFromContext(ctx, "users")))
 	if err != nil {
 		return errors.Wrap(err)
 	}
@@ -93,7 +93,7 @@ func (r *userRepository) Add(ctx context.Context, u persistence.User) error {
 }
 
 func (r *userRepository) UpdateEmail(ctx context.Context, id, email string) error {
-	stmt, err := r.db.PrepareContext(ctx, `UPDATE users SET email_address=? WHERE id=?`)
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`UPDATE %s SET email_address=? WHERE id=?`, mysql.TableFromContext(ctx, "users")))
 	if err != nil {
 		return errors.Wrap(err)
 	}
@@ -117,7 +117,7 @@ func (r *userRepository) UpdateEmail(ctx context.Context, id, email string) erro
 }
 
 func (r *userRepository) UpdateFacebookID(ctx context.Context, id, facebookID string) error {
-	stmt, err := r.db.PrepareContext(ctx, `UPDATE users SET facebookID=? WHERE id=?`)
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`UPDATE %s SET facebookID=? WHERE id=?`, mysql.TableFromContext(ctx, "users")))
 	if err != nil {
 		return errors.Wrap(err)
 	}
@@ -141,7 +141,7 @@ func (r *userRepository) UpdateFacebookID(ctx context.Context, id, facebookID st
 }
 
 func (r *userRepository) UpdateGoogleID(ctx context.Context, id, googleID string) error {
-	stmt, err := r.db.PrepareContext(ctx, `UPDATE users SET googleID=? WHERE id=?`)
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`UPDATE %s SET googleID=? WHERE id=?`, mysql.TableFromContext(ctx, "users")))
 	if err != nil {
 		return errors.Wrap(err)
 	}
@@ -165,7 +165,7 @@ func (r *userRepository) UpdateGoogleID(ctx context.Context, id, googleID string
 }
 
 func (r *userRepository) Delete(ctx context.Context, id string) error {
-	stmt, err := r.db.PrepareContext(ctx, `DELETE FROM users WHERE id=?`)
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id=?`, mysql.TableFromContext(ctx, "users")))
 	if err != nil {
 		return errors.Wrap(err)
 	}
@@ -191,7 +191,7 @@ func (r *userRepository) Delete(ctx context.Context, id string) error {
 func (r *userRepository) Count(ctx context.Context) (int32, error) {
 	var totalUsers int32
 
-	row := r.db.QueryRowContext(ctx, `SELECT COUNT(distinct_id) FROM users`)
+	row := mysql.ExecutorFromContext(ctx, r.db).QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(distinct_id) FROM %s`, mysql.TableFromContext(ctx, "users")))
 	if err := row.Scan(&totalUsers); err != nil {
 		return 0, errors.Wrap(err)
 	}
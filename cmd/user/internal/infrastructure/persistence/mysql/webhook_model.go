@@ -0,0 +1,30 @@
+package mysql
+
+// Webhook model
+type Webhook struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// Secret is never exposed, webhook owner knows it already
+	Secret string `json:"-"`
+}
+
+// GetID the id
+func (w Webhook) GetID() string {
+	return w.ID
+}
+
+// GetURL the url
+func (w Webhook) GetURL() string {
+	return w.URL
+}
+
+// GetEventTypes event types
+func (w Webhook) GetEventTypes() []string {
+	return w.EventTypes
+}
+
+// GetSecret the secret
+func (w Webhook) GetSecret() string {
+	return w.Secret
+}
@@ -0,0 +1,136 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	systemErrors "errors"
+	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence"
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/mysql"
+)
+
+// NewWebhookRepository returns mysql view model repository for webhook
+func NewWebhookRepository(db *sql.DB) persistence.WebhookRepository {
+	return &webhookRepository{db}
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func (r *webhookRepository) FindAll(ctx context.Context, limit, offset int32) ([]persistence.Webhook, error) {
+	rows, err := mysql.ExecutorFromContext(ctx, r.db).QueryContext(ctx, fmt.Sprintf(`SELECT id, url, event_types, secret FROM %s ORDER BY distinct_id ASC LIMIT ? OFFSET ?`, mysql.TableFromContext(ctx, "webhooks")), limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defer rows.Close()
+
+	var webhooks []persistence.Webhook
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *webhookRepository) Get(ctx context.Context, id string) (persistence.Webhook, error) {
+	row := mysql.ExecutorFromContext(ctx, r.db).QueryRowContext(ctx, fmt.Sprintf(`SELECT id, url, event_types, secret FROM %s WHERE id=? LIMIT 1`, mysql.TableFromContext(ctx, "webhooks")), id)
+
+	webhook, err := scanWebhook(row)
+	switch {
+	case systemErrors.Is(err, sql.ErrNoRows):
+		return nil, errors.Wrap(fmt.Errorf("%w: %s", application.ErrNotFound, err))
+	case err != nil:
+		return nil, errors.Wrap(err)
+	default:
+		return webhook, nil
+	}
+}
+
+func (r *webhookRepository) Add(ctx context.Context, w persistence.Webhook) error {
+	eventTypes, err := json.Marshal(w.GetEventTypes())
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`INSERT IGNORE INTO %s (id, url, event_types, secret) VALUES (?,?,?,?)`, mysql.TableFromContext(ctx, "webhooks")))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer stmt.Close()
+
+	if _, err := stmt.ExecContext(ctx, w.GetID(), w.GetURL(), eventTypes, w.GetSecret()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id string) error {
+	stmt, err := mysql.ExecutorFromContext(ctx, r.db).PrepareContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id=?`, mysql.TableFromContext(ctx, "webhooks")))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if rows != 1 {
+		return errors.New("Did not delete webhook")
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) Count(ctx context.Context) (int32, error) {
+	var totalWebhooks int32
+
+	row := mysql.ExecutorFromContext(ctx, r.db).QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(distinct_id) FROM %s`, mysql.TableFromContext(ctx, "webhooks")))
+	if err := row.Scan(&totalWebhooks); err != nil {
+		return 0, errors.Wrap(err)
+	}
+
+	return totalWebhooks, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhook(row scanner) (Webhook, error) {
+	var (
+		webhook    Webhook
+		eventTypes []byte
+	)
+	if err := row.Scan(&webhook.ID, &webhook.URL, &eventTypes, &webhook.Secret); err != nil {
+		return webhook, err
+	}
+
+	if err := json.Unmarshal(eventTypes, &webhook.EventTypes); err != nil {
+		return webhook, err
+	}
+
+	return webhook, nil
+}
@@ -0,0 +1,22 @@
+package persistence
+
+import (
+	"context"
+)
+
+// Webhook the webhook persistence model interface
+type Webhook interface {
+	GetID() string
+	GetURL() string
+	GetEventTypes() []string
+	GetSecret() string
+}
+
+// WebhookRepository allows to get/save current state of webhook to mysql storage
+type WebhookRepository interface {
+	FindAll(ctx context.Context, limit, offset int32) ([]Webhook, error)
+	Get(ctx context.Context, id string) (Webhook, error)
+	Add(ctx context.Context, webhook Webhook) error
+	Delete(ctx context.Context, id string) error
+	Count(ctx context.Context) (int32, error)
+}
@@ -5,67 +5,76 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
-	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
 	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
 )
 
 type userRepository struct {
-	eventStore eventstore.EventStore
-	eventBus   eventbus.EventBus
+	repository *aggregate.Repository
 }
 
 // NewUserRepository creates new user event sourced repository
-func NewUserRepository(store eventstore.EventStore, bus eventbus.EventBus) user.Repository {
-	return &userRepository{store, bus}
+// snapshot of aggregate root is taken after save whenever given policy allows it
+func NewUserRepository(store eventstore.EventStore, bus eventbus.EventBus, snapshotStore snapshotstore.SnapshotStore, snapshotPolicy snapshotstore.Policy, logger *log.Logger) user.Repository {
+	return &userRepository{
+		repository: aggregate.NewRepository(store, bus, snapshotStore, snapshotPolicy, logger),
+	}
 }
 
 // Save current user changes to event store and publish each event with an event bus
 func (r *userRepository) Save(ctx context.Context, u user.User) error {
-	if err := r.eventStore.Store(ctx, u.Changes()); err != nil {
+	if err := r.repository.Save(ctx, &u); err != nil {
 		return errors.Wrap(err)
 	}
 
-	for _, event := range u.Changes() {
-		if err := r.eventBus.Publish(ctx, event); err != nil {
-			return errors.Wrap(err)
-		}
-	}
-
 	return nil
 }
 
 // Save current user changes to event store and publish each event with an event bus
 // blocks until event handlers are finished
 func (r *userRepository) SaveAndAcknowledge(ctx context.Context, u user.User) error {
-	if err := r.eventStore.Store(ctx, u.Changes()); err != nil {
+	if err := r.repository.SaveAndAcknowledge(ctx, &u); err != nil {
 		return errors.Wrap(err)
 	}
 
-	for _, event := range u.Changes() {
-		if err := r.eventBus.PublishAndAcknowledge(ctx, event); err != nil {
-			return errors.Wrap(err)
-		}
-	}
-
 	return nil
 }
 
 // Get user with current state applied
 func (r *userRepository) Get(ctx context.Context, id uuid.UUID) (user.User, error) {
-	events, err := r.eventStore.GetStream(ctx, id, user.StreamName)
-	if err != nil {
+	u := user.New()
+	if err := r.repository.Load(ctx, id, &u); err != nil {
 		return user.User{}, errors.Wrap(err)
 	}
 
-	if len(events) == 0 {
-		return user.User{}, application.ErrNotFound
+	return u, nil
+}
+
+// GetAtVersion returns user state after given number of events were applied
+func (r *userRepository) GetAtVersion(ctx context.Context, id uuid.UUID, version int) (user.User, error) {
+	u := user.New()
+	if err := r.repository.LoadAtVersion(ctx, id, version, &u); err != nil {
+		return user.User{}, errors.Wrap(err)
+	}
+
+	return u, nil
+}
+
+// GetAsOf returns user state from events that occurred at or before given time
+func (r *userRepository) GetAsOf(ctx context.Context, id uuid.UUID, at time.Time) (user.User, error) {
+	u := user.New()
+	if err := r.repository.LoadAsOf(ctx, id, at, &u); err != nil {
+		return user.User{}, errors.Wrap(err)
 	}
 
-	return user.FromHistory(events), nil
+	return u, nil
 }
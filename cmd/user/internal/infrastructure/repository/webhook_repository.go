@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/webhook"
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
+)
+
+type webhookRepository struct {
+	repository *aggregate.Repository
+}
+
+// NewWebhookRepository creates new webhook event sourced repository
+// snapshot of aggregate root is taken after save whenever given policy allows it
+func NewWebhookRepository(store eventstore.EventStore, bus eventbus.EventBus, snapshotStore snapshotstore.SnapshotStore, snapshotPolicy snapshotstore.Policy, logger *log.Logger) webhook.Repository {
+	return &webhookRepository{
+		repository: aggregate.NewRepository(store, bus, snapshotStore, snapshotPolicy, logger),
+	}
+}
+
+// Save current webhook changes to event store and publish each event with an event bus
+func (r *webhookRepository) Save(ctx context.Context, w webhook.Webhook) error {
+	if err := r.repository.Save(ctx, &w); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// Save current webhook changes to event store and publish each event with an event bus
+// blocks until event handlers are finished
+func (r *webhookRepository) SaveAndAcknowledge(ctx context.Context, w webhook.Webhook) error {
+	if err := r.repository.SaveAndAcknowledge(ctx, &w); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// Get webhook with current state applied
+func (r *webhookRepository) Get(ctx context.Context, id uuid.UUID) (webhook.Webhook, error) {
+	w := webhook.New()
+	if err := r.repository.Load(ctx, id, &w); err != nil {
+		return webhook.Webhook{}, errors.Wrap(err)
+	}
+
+	return w, nil
+}
@@ -0,0 +1,181 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
+	"github.com/vardius/go-api-boilerplate/cmd/user/proto"
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	grpcerrors "github.com/vardius/go-api-boilerplate/pkg/grpc/errors"
+)
+
+// ErrSubscriberLagged is when subscriber does not receive events as fast as they are published,
+// it should subscribe again from position of the last event it received
+var ErrSubscriberLagged = fmt.Errorf("%w: subscriber could not keep up with events", application.ErrTemporaryDisabled)
+
+const (
+	// liveEventsBuffer is a number of live events kept for a subscriber that did not receive them yet
+	liveEventsBuffer = 100
+	// readBatchSize is a number of events read from event store at once
+	readBatchSize = 100
+)
+
+// SubscribeEvents implements proto.UserServiceServer interface,
+// events stored after requested position are streamed first, then live ones in order of their position
+func (s *userServer) SubscribeEvents(r *proto.SubscribeEventsRequest, stream proto.UserService_SubscribeEventsServer) error {
+	ctx := stream.Context()
+
+	for _, eventType := range r.GetEventTypes() {
+		if err := eventbus.ValidateTopic(eventType); err != nil {
+			return grpcerrors.NewGRPCError(errors.Wrap(err))
+		}
+	}
+
+	live := make(chan domain.Event, liveEventsBuffer)
+	lagged := make(chan struct{})
+	var lag sync.Once
+
+	handler := func(ctx context.Context, event domain.Event) error {
+		select {
+		case live <- event:
+		default:
+			// event bus is not held by slow subscriber, it resumes from event store instead
+			lag.Do(func() { close(lagged) })
+		}
+
+		return nil
+	}
+
+	// subscribed before reading event store so no event is missed in between
+	topic := eventbus.StreamTopic(user.StreamName)
+	if err := s.eventBus.Subscribe(ctx, topic, handler); err != nil {
+		return grpcerrors.NewGRPCError(errors.Wrap(err))
+	}
+	defer func() {
+		_ = s.eventBus.Unsubscribe(context.Background(), topic, handler)
+	}()
+
+	es := &eventStream{
+		store:      s.eventStore,
+		stream:     stream,
+		eventTypes: make(map[string]struct{}, len(r.GetEventTypes())),
+	}
+	for _, eventType := range r.GetEventTypes() {
+		es.eventTypes[eventType] = struct{}{}
+	}
+
+	if from := r.GetFromPosition(); from != nil {
+		es.position = from.GetValue()
+		es.started = true
+
+		if err := es.catchUp(ctx, 0); err != nil {
+			return grpcerrors.NewGRPCError(errors.Wrap(err))
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-lagged:
+			return grpcerrors.NewGRPCError(errors.Wrap(ErrSubscriberLagged))
+		case e := <-live:
+			if es.started && e.Position <= es.position {
+				continue
+			}
+			if es.started && e.Position > es.position+1 {
+				// events published meanwhile may be delivered out of order, preceding ones are read from event store
+				if err := es.catchUp(ctx, e.Position); err != nil {
+					return grpcerrors.NewGRPCError(errors.Wrap(err))
+				}
+			}
+			if !es.started || e.Position > es.position {
+				if err := es.send(e); err != nil {
+					return grpcerrors.NewGRPCError(errors.Wrap(err))
+				}
+			}
+		}
+	}
+}
+
+// eventStream sends user events matching requested types in order of their position
+type eventStream struct {
+	store      eventstore.EventStore
+	stream     proto.UserService_SubscribeEventsServer
+	eventTypes map[string]struct{}
+	// position of the last event read, subscriber without starting position starts with the first live event
+	position uint64
+	started  bool
+}
+
+// catchUp sends stored events after current position up to the given one, zero reads until event store is drained
+func (s *eventStream) catchUp(ctx context.Context, until uint64) error {
+	for {
+		events, err := s.store.ReadAll(ctx, s.position, readBatchSize)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+
+		for _, e := range events {
+			if until > 0 && e.Position > until {
+				return nil
+			}
+			if err := s.send(e); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+
+		if len(events) < readBatchSize {
+			return nil
+		}
+	}
+}
+
+// send streams event if it matches subscription, position moves forward either way
+func (s *eventStream) send(e domain.Event) error {
+	s.position = e.Position
+	s.started = true
+
+	if !s.matches(e) {
+		return nil
+	}
+
+	// identity of event author holds its credentials, they are not sent outside
+	e.Identity = nil
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if err := s.stream.Send(&proto.SubscribeEventsResponse{Payload: payload}); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// matches returns true for user events delivered to any of requested topics, all user events when none was requested
+func (s *eventStream) matches(e domain.Event) bool {
+	if e.Metadata.StreamName != user.StreamName {
+		return false
+	}
+	if len(s.eventTypes) == 0 {
+		return true
+	}
+
+	for _, topic := range eventbus.Topics(e) {
+		if _, ok := s.eventTypes[topic]; ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,138 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	grpcmain "google.golang.org/grpc"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
+	"github.com/vardius/go-api-boilerplate/cmd/user/proto"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	memoryeventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// countingEventBus counts handlers subscribed through it
+type countingEventBus struct {
+	eventbus.EventBus
+	handlers int32
+}
+
+func (b *countingEventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	atomic.AddInt32(&b.handlers, 1)
+
+	return b.EventBus.Subscribe(ctx, eventType, fn)
+}
+
+func (b *countingEventBus) Unsubscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	atomic.AddInt32(&b.handlers, -1)
+
+	return b.EventBus.Unsubscribe(ctx, eventType, fn)
+}
+
+// subscribeEventsServerMock passes streamed events to a channel
+type subscribeEventsServerMock struct {
+	grpcmain.ServerStream
+	ctx    context.Context
+	events chan domain.Event
+}
+
+func (s *subscribeEventsServerMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *subscribeEventsServerMock) Send(r *proto.SubscribeEventsResponse) error {
+	var e domain.Event
+	if err := json.Unmarshal(r.GetPayload(), &e); err != nil {
+		return err
+	}
+
+	s.events <- e
+
+	return nil
+}
+
+func TestSubscribeEventsCancelledStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := memoryeventstore.New()
+	bus := &countingEventBus{EventBus: memoryeventbus.New(1, log.New("development"))}
+	s := &userServer{eventStore: store, eventBus: bus}
+
+	subscribe := func(ctx context.Context) (*subscribeEventsServerMock, chan error) {
+		stream := &subscribeEventsServerMock{ctx: ctx, events: make(chan domain.Event, 10)}
+		done := make(chan error, 1)
+		go func() {
+			done <- s.SubscribeEvents(&proto.SubscribeEventsRequest{}, stream)
+		}()
+
+		return stream, done
+	}
+
+	cancelledCtx, cancelStream := context.WithCancel(ctx)
+	_, cancelled := subscribe(cancelledCtx)
+	stream, _ := subscribe(ctx)
+	waitForHandlers(t, bus, 2)
+
+	cancelStream()
+	if err := <-cancelled; err != nil {
+		t.Fatal(err)
+	}
+	waitForHandlers(t, bus, 1)
+
+	// handler of cancelled stream would block publisher or send to closed channel of acknowledgements
+	userID := uuid.New()
+	for version := 0; version < 3; version++ {
+		e, err := domain.NewEvent(userID, user.StreamName, version, user.EmailAddressWasChanged{ID: userID, Email: "changed@example.com"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events := []domain.Event{e}
+		if err := store.Store(ctx, events); err != nil {
+			t.Fatal(err)
+		}
+
+		published := make(chan error, 1)
+		go func() {
+			published <- bus.PublishAndAcknowledge(ctx, events[0])
+		}()
+
+		select {
+		case err := <-published:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("publishing did not finish")
+		}
+
+		select {
+		case received := <-stream.events:
+			if received.ID != e.ID {
+				t.Errorf("expected event %s, got %s", e.ID, received.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %s was not streamed", e.ID)
+		}
+	}
+}
+
+func waitForHandlers(t *testing.T, bus *countingEventBus, n int32) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&bus.handlers) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d subscribed handlers, got %d", n, atomic.LoadInt32(&bus.handlers))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -6,43 +6,52 @@ package grpc
 import (
 	"context"
 
-	"github.com/golang/protobuf/ptypes/empty"
-
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence"
 	"github.com/vardius/go-api-boilerplate/cmd/user/proto"
 	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
 	grpcerrors "github.com/vardius/go-api-boilerplate/pkg/grpc/errors"
 )
 
 type userServer struct {
 	commandBus     commandbus.CommandBus
 	userRepository persistence.UserRepository
+	eventStore     eventstore.EventStore
+	eventBus       eventbus.EventBus
 }
 
-// NewServer returns new user server object
-func NewServer(cb commandbus.CommandBus, r persistence.UserRepository) proto.UserServiceServer {
+// NewServer returns new user server object,
+// every SubscribeEvents stream subscribes its own handler so event bus has to keep them all, e.g. plain memory bus
+func NewServer(cb commandbus.CommandBus, r persistence.UserRepository, es eventstore.EventStore, eb eventbus.EventBus) proto.UserServiceServer {
 	s := &userServer{
 		commandBus:     cb,
 		userRepository: r,
+		eventStore:     es,
+		eventBus:       eb,
 	}
 
 	return s
 }
 
 // DispatchCommand implements proto.UserServiceServer interface
-func (s *userServer) DispatchCommand(ctx context.Context, r *proto.DispatchCommandRequest) (*empty.Empty, error) {
+func (s *userServer) DispatchCommand(ctx context.Context, r *proto.DispatchCommandRequest) (*proto.DispatchCommandResponse, error) {
 	c, err := user.NewCommandFromPayload(r.GetName(), r.GetPayload())
 	if err != nil {
 		return nil, grpcerrors.NewGRPCError(errors.Wrap(err))
 	}
 
-	if err := s.commandBus.Publish(ctx, c); err != nil {
+	result, err := s.commandBus.Publish(ctx, c)
+	if err != nil {
 		return nil, grpcerrors.NewGRPCError(errors.Wrap(err))
 	}
 
-	return new(empty.Empty), nil
+	return &proto.DispatchCommandResponse{
+		Id:      result.ID.String(),
+		Version: int32(result.Version),
+	}, nil
 }
 
 // GetUser implements proto.UserServiceServer interface
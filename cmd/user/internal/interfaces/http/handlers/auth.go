@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -15,10 +14,6 @@ import (
 	"github.com/vardius/go-api-boilerplate/pkg/http/response"
 )
 
-type requestBody struct {
-	Email string `json:"email"`
-}
-
 // BuildSocialAuthHandler wraps user gRPC client with http.Handler
 func BuildSocialAuthHandler(apiURL string, cb commandbus.CommandBus, commandName string, tokenProvider oauth2.TokenProvider, identityProvider appidentity.Provider) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
@@ -31,19 +26,14 @@ func BuildSocialAuthHandler(apiURL string, cb commandbus.CommandBus, commandName
 			return
 		}
 
-		emailData := requestBody{}
-		if err := json.Unmarshal(profileData, &emailData); err != nil {
-			response.MustJSONError(r.Context(), w, errors.Wrap(err))
-			return
-		}
-
 		c, err := user.NewCommandFromPayload(commandName, profileData)
 		if err != nil {
 			response.MustJSONError(r.Context(), w, errors.Wrap(err))
 			return
 		}
 
-		if err := cb.Publish(r.Context(), c); err != nil {
+		result, err := cb.Publish(r.Context(), c)
+		if err != nil {
 			response.MustJSONError(r.Context(), w, errors.Wrap(err))
 			return
 		}
@@ -51,13 +41,13 @@ func BuildSocialAuthHandler(apiURL string, cb commandbus.CommandBus, commandName
 		// We can do that because command handler acknowledges events when persisting
 		// aggregate root so we know that event handlers have executed and data was
 		// persisted (see: SaveAndAcknowledge method)
-		i, err := identityProvider.GetByUserEmail(r.Context(), emailData.Email, config.Env.App.Domain)
+		i, err := identityProvider.GetByUserIDAndDomain(r.Context(), result.ID, config.Env.App.Domain)
 		if err != nil {
 			response.MustJSONError(r.Context(), w, errors.Wrap(err))
 			return
 		}
 
-		token, err := tokenProvider.RetrievePasswordCredentialsToken(r.Context(), i.ClientID.String(), i.ClientSecret, emailData.Email, []string{"all"})
+		token, err := tokenProvider.RetrievePasswordCredentialsToken(r.Context(), i.ClientID.String(), i.ClientSecret, i.UserEmail, []string{"all"})
 		if err != nil {
 			response.MustJSONError(r.Context(), w, errors.Wrap(err))
 			return
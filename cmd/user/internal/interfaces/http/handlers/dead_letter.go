@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/vardius/gorouter/v4/context"
+
+	"github.com/vardius/go-api-boilerplate/pkg/deadletter"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/http/response"
+)
+
+// BuildListDeadLettersHandler wraps dead letters admin with http.Handler
+func BuildListDeadLettersHandler(admin deadletter.Admin) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		pageInt, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 32)
+		limitInt, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+		page := int(math.Max(float64(pageInt), 1))
+		limit := int(math.Max(float64(limitInt), 20))
+
+		letters, err := admin.DeadLetters(r.Context(), (page*limit)-limit, limit)
+		if err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		list := struct {
+			Letters []deadletter.Letter `json:"letters"`
+			Page    int                 `json:"page"`
+			Limit   int                 `json:"limit"`
+		}{
+			Letters: letters,
+			Page:    page,
+			Limit:   limit,
+		}
+
+		if err := response.JSON(r.Context(), w, http.StatusOK, list); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// BuildRetryDeadLetterHandler wraps dead letters admin with http.Handler
+func BuildRetryDeadLetterHandler(admin deadletter.Admin) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		id, err := deadLetterID(r)
+		if err != nil {
+			response.MustJSONError(r.Context(), w, err)
+			return
+		}
+
+		if err := admin.Retry(r.Context(), id); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		if err := response.JSON(r.Context(), w, http.StatusOK, nil); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// BuildDiscardDeadLetterHandler wraps dead letters admin with http.Handler
+func BuildDiscardDeadLetterHandler(admin deadletter.Admin) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		id, err := deadLetterID(r)
+		if err != nil {
+			response.MustJSONError(r.Context(), w, err)
+			return
+		}
+
+		if err := admin.Discard(r.Context(), id); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+func deadLetterID(r *http.Request) (uuid.UUID, error) {
+	params, ok := context.Parameters(r.Context())
+	if !ok {
+		return uuid.Nil, ErrInvalidURLParams
+	}
+
+	id, err := uuid.Parse(params.Value("id"))
+	if err != nil {
+		return uuid.Nil, ErrInvalidURLParams
+	}
+
+	return id, nil
+}
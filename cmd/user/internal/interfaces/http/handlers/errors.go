@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
 )
 
 // ErrEmptyRequestBody is when an request has empty body.
@@ -9,3 +11,6 @@ var ErrEmptyRequestBody = fmt.Errorf("empty request body")
 
 // ErrInvalidURLParams is when an request has invalid or missing parameters.
 var ErrInvalidURLParams = fmt.Errorf("invalid request URL parameters")
+
+// ErrInvalidHistoryPoint is when an request has invalid or missing point in history.
+var ErrInvalidHistoryPoint = fmt.Errorf("%w: query parameter at has to be a version or RFC3339 time", application.ErrInvalid)
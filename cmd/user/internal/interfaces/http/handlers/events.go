@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	systemErrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/http/response"
+	"github.com/vardius/go-api-boilerplate/pkg/identity"
+)
+
+// ErrInvalidLastEventID is when Last-Event-ID header is not an id of caller's event.
+var ErrInvalidLastEventID = fmt.Errorf("%w: Last-Event-ID has to be an id of your event", application.ErrInvalid)
+
+// liveEventsBuffer is a number of live events kept for a client that did not receive them yet
+const liveEventsBuffer = 100
+
+// BuildMeEventsHandler streams domain events of the authenticated user as server-sent events.
+// Stream ends with server write timeout or when client can not keep up,
+// client reconnecting with Last-Event-ID header gets events stored meanwhile from event store first.
+// Every stream subscribes handler created by this function, event bus has to keep them all, e.g. plain memory bus
+func BuildMeEventsHandler(eventStore eventstore.EventStore, eventBus eventbus.EventBus) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		i, _ := identity.FromContext(r.Context())
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			response.MustJSONError(r.Context(), w, errors.New("streaming is not supported"))
+			return
+		}
+
+		live := make(chan domain.Event, liveEventsBuffer)
+		lagged := make(chan struct{})
+		var lag sync.Once
+
+		handler := func(ctx context.Context, event domain.Event) error {
+			if event.Metadata.StreamID != i.UserID {
+				return nil
+			}
+
+			select {
+			case live <- event:
+			default:
+				// event bus is not held by slow client, it resumes from event store instead
+				lag.Do(func() { close(lagged) })
+			}
+
+			return nil
+		}
+
+		// subscribed before reading event store so no event is missed in between
+		topic := eventbus.StreamTopic(user.StreamName)
+		if err := eventBus.Subscribe(r.Context(), topic, handler); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+		defer func() {
+			_ = eventBus.Unsubscribe(context.Background(), topic, handler)
+		}()
+
+		stored, err := storedEvents(r, eventStore, i.UserID)
+		if err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		version := -1
+		if r.Header.Get("Last-Event-ID") == "" && len(stored) > 0 {
+			// event without data is not dispatched by client but sets its Last-Event-ID,
+			// reconnected client gets events it missed even if none was streamed to it before
+			last := stored[len(stored)-1]
+			if _, err := fmt.Fprintf(w, "id: %s\n\n", last.ID); err != nil {
+				return
+			}
+			version = last.Metadata.StreamVersion
+			stored = nil
+		}
+
+		for _, e := range stored {
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+			version = e.Metadata.StreamVersion
+		}
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-lagged:
+				return
+			case e := <-live:
+				events := []domain.Event{e}
+				if version >= 0 && e.Metadata.StreamVersion > version+1 {
+					// events of the stream may be delivered out of order, missing ones are read from event store
+					if events, err = eventStore.GetStreamFromVersion(r.Context(), i.UserID, user.StreamName, version+1); err != nil {
+						return
+					}
+				}
+
+				for _, e := range events {
+					if e.Metadata.StreamVersion <= version {
+						continue
+					}
+					if err := writeEvent(w, e); err != nil {
+						return
+					}
+					version = e.Metadata.StreamVersion
+				}
+				flusher.Flush()
+			}
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// storedEvents returns user events stored after the one client received last,
+// client connecting for the first time gets whole stream so only its last event sets the cursor
+func storedEvents(r *http.Request, eventStore eventstore.EventStore, userID uuid.UUID) ([]domain.Event, error) {
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		events, err := eventStore.GetStream(r.Context(), userID, user.StreamName)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		return events, nil
+	}
+
+	id, err := uuid.Parse(lastEventID)
+	if err != nil {
+		return nil, ErrInvalidLastEventID
+	}
+
+	last, err := eventStore.Get(r.Context(), id)
+	if systemErrors.Is(err, eventstore.ErrEventNotFound) {
+		return nil, ErrInvalidLastEventID
+	}
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if last.Metadata.StreamID != userID || last.Metadata.StreamName != user.StreamName {
+		return nil, ErrInvalidLastEventID
+	}
+
+	events, err := eventStore.GetStreamFromVersion(r.Context(), userID, user.StreamName, last.Metadata.StreamVersion+1)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return events, nil
+}
+
+func writeEvent(w io.Writer, e domain.Event) error {
+	// identity of event author holds its credentials
+	e.Identity = nil
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Metadata.Type, data); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
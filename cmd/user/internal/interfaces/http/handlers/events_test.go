@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	memoryeventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/identity"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// countingEventBus counts handlers subscribed through it
+type countingEventBus struct {
+	eventbus.EventBus
+	handlers int32
+}
+
+func (b *countingEventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	atomic.AddInt32(&b.handlers, 1)
+
+	return b.EventBus.Subscribe(ctx, eventType, fn)
+}
+
+func (b *countingEventBus) Unsubscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	atomic.AddInt32(&b.handlers, -1)
+
+	return b.EventBus.Unsubscribe(ctx, eventType, fn)
+}
+
+func TestBuildMeEventsHandlerConcurrentStreams(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	userID := uuid.New()
+	store := memoryeventstore.New()
+	bus := memoryeventbus.New(1, log.New("development"))
+	server := newMeEventsServer(t, store, bus, userID)
+
+	streams := []*bufio.Reader{openStream(ctx, t, server.URL), openStream(ctx, t, server.URL)}
+
+	changed := publishEmailChange(ctx, t, store, bus, userID, 1)
+	for i, stream := range streams {
+		if err := waitForEvent(stream, changed.ID); err != nil {
+			t.Errorf("stream %d: %v", i, err)
+		}
+	}
+}
+
+func TestBuildMeEventsHandlerClosedStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	userID := uuid.New()
+	store := memoryeventstore.New()
+	bus := &countingEventBus{EventBus: memoryeventbus.New(1, log.New("development"))}
+	server := newMeEventsServer(t, store, bus, userID)
+
+	closedCtx, closeStream := context.WithCancel(ctx)
+	openStream(closedCtx, t, server.URL)
+	stream := openStream(ctx, t, server.URL)
+
+	closeStream()
+	waitForHandlers(t, bus, 1)
+
+	// handler of closed stream would block publisher or send to closed channel of acknowledgements
+	for version := 1; version <= 3; version++ {
+		published := make(chan domain.Event, 1)
+		go func(version int) {
+			published <- publishEmailChange(ctx, t, store, bus, userID, version)
+		}(version)
+
+		select {
+		case e := <-published:
+			if err := waitForEvent(stream, e.ID); err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("publishing did not finish")
+		}
+	}
+}
+
+// newMeEventsServer serves events of user with given id, user is registered in event store,
+// server is closed after streams the test opened
+func newMeEventsServer(t *testing.T, store eventstore.EventStore, bus eventbus.EventBus, userID uuid.UUID) *httptest.Server {
+	t.Helper()
+
+	registered, err := domain.NewEvent(userID, user.StreamName, 0, user.WasRegisteredWithEmail{ID: userID, Email: "test@example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Store(context.Background(), []domain.Event{registered}); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := BuildMeEventsHandler(store, bus)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(identity.ContextWithIdentity(r.Context(), identity.Identity{UserID: userID})))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// openStream connects to events stream, handler is subscribed before response headers are sent
+func openStream(ctx context.Context, t *testing.T, url string) *bufio.Reader {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return bufio.NewReader(resp.Body)
+}
+
+func publishEmailChange(ctx context.Context, t *testing.T, store eventstore.EventStore, bus eventbus.EventBus, userID uuid.UUID, version int) domain.Event {
+	t.Helper()
+
+	changed, err := domain.NewEvent(userID, user.StreamName, version, user.EmailAddressWasChanged{ID: userID, Email: "changed@example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Store(ctx, []domain.Event{changed}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.PublishAndAcknowledge(ctx, changed); err != nil {
+		t.Fatal(err)
+	}
+
+	return changed
+}
+
+func waitForHandlers(t *testing.T, bus *countingEventBus, n int32) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&bus.handlers) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d subscribed handlers, got %d", n, atomic.LoadInt32(&bus.handlers))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitForEvent reads server-sent events until event with given id is received
+func waitForEvent(stream *bufio.Reader, id uuid.UUID) error {
+	received := make(chan error, 1)
+	go func() {
+		for {
+			line, err := stream.ReadString('\n')
+			if err != nil {
+				received <- err
+				return
+			}
+			if strings.TrimSpace(line) == fmt.Sprintf("id: %s", id) {
+				received <- nil
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-received:
+		return err
+	case <-time.After(time.Second):
+		return fmt.Errorf("event %s was not received", id)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"math"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/vardius/gorouter/v4/context"
 
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
@@ -45,12 +47,16 @@ func BuildCommandDispatchHandler(cb commandbus.CommandBus) http.Handler {
 			return
 		}
 
-		if err := cb.Publish(r.Context(), c); err != nil {
+		result, err := cb.Publish(r.Context(), c)
+		if err != nil {
 			response.MustJSONError(r.Context(), w, errors.Wrap(err))
 			return
 		}
 
-		if err := response.JSON(r.Context(), w, http.StatusCreated, nil); err != nil {
+		// relative to /dispatch/{command} so it resolves to the user no matter where router is mounted
+		w.Header().Set("Location", "../"+result.ID.String())
+
+		if err := response.JSON(r.Context(), w, http.StatusCreated, result); err != nil {
 			response.MustJSONError(r.Context(), w, errors.Wrap(err))
 		}
 	}
@@ -100,6 +106,56 @@ func BuildGetUserHandler(repository persistence.UserRepository) http.Handler {
 	return http.HandlerFunc(fn)
 }
 
+// BuildUserHistoryHandler wraps user repository with http.Handler
+// responds with user state reconstructed at given version or time
+func BuildUserHistoryHandler(repository user.Repository) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		params, ok := context.Parameters(r.Context())
+		if !ok {
+			response.MustJSONError(r.Context(), w, ErrInvalidURLParams)
+			return
+		}
+
+		id, err := uuid.Parse(params.Value("id"))
+		if err != nil {
+			response.MustJSONError(r.Context(), w, ErrInvalidURLParams)
+			return
+		}
+
+		at := r.URL.Query().Get("at")
+
+		var u user.User
+		if version, e := strconv.Atoi(at); e == nil && version >= 0 {
+			u, err = repository.GetAtVersion(r.Context(), id, version)
+		} else if t, e := time.Parse(time.RFC3339Nano, at); e == nil {
+			u, err = repository.GetAsOf(r.Context(), id, t)
+		} else {
+			response.MustJSONError(r.Context(), w, ErrInvalidHistoryPoint)
+			return
+		}
+		if err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		state := struct {
+			ID      uuid.UUID         `json:"id"`
+			Email   user.EmailAddress `json:"email"`
+			Version int               `json:"version"`
+		}{
+			ID:      u.ID(),
+			Email:   u.Email(),
+			Version: u.Version(),
+		}
+
+		if err := response.JSON(r.Context(), w, http.StatusOK, state); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
 // BuildListUserHandler wraps user gRPC client with http.Handler
 func BuildListUserHandler(repository persistence.UserRepository) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
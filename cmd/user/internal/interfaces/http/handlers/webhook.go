@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/vardius/gorouter/v4/context"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/webhook"
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence"
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/http/response"
+	basewebhook "github.com/vardius/go-api-boilerplate/pkg/webhook"
+)
+
+// BuildCreateWebhookHandler wraps command bus with http.Handler
+// responds with id of created webhook, its secret is never returned afterwards
+func BuildCreateWebhookHandler(cb commandbus.CommandBus) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			response.MustJSONError(r.Context(), w, ErrEmptyRequestBody)
+			return
+		}
+		defer r.Body.Close()
+
+		c := webhook.Create{}
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(fmt.Errorf("%w: %s", application.ErrInvalid, err)))
+			return
+		}
+		c.ID = uuid.New()
+
+		if _, err := cb.Publish(r.Context(), c); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		created := struct {
+			ID uuid.UUID `json:"id"`
+		}{
+			ID: c.ID,
+		}
+
+		if err := response.JSON(r.Context(), w, http.StatusCreated, created); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// BuildRemoveWebhookHandler wraps command bus with http.Handler
+func BuildRemoveWebhookHandler(cb commandbus.CommandBus) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		id, err := webhookID(r)
+		if err != nil {
+			response.MustJSONError(r.Context(), w, err)
+			return
+		}
+
+		if _, err := cb.Publish(r.Context(), webhook.Remove{ID: id}); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// BuildGetWebhookHandler wraps webhook repository with http.Handler
+func BuildGetWebhookHandler(repository persistence.WebhookRepository) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		id, err := webhookID(r)
+		if err != nil {
+			response.MustJSONError(r.Context(), w, err)
+			return
+		}
+
+		hook, err := repository.Get(r.Context(), id.String())
+		if err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		if err := response.JSON(r.Context(), w, http.StatusOK, hook); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// BuildListWebhooksHandler wraps webhook repository with http.Handler
+func BuildListWebhooksHandler(repository persistence.WebhookRepository) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		pageInt, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 32)
+		limitInt, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+		page := int32(math.Max(float64(pageInt), 1))
+		limit := int32(math.Max(float64(limitInt), 20))
+
+		totalWebhooks, err := repository.Count(r.Context())
+		if err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		offset := (page * limit) - limit
+
+		paginatedList := struct {
+			Webhooks []persistence.Webhook `json:"webhooks"`
+			Page     int32                 `json:"page"`
+			Limit    int32                 `json:"limit"`
+			Total    int32                 `json:"total"`
+		}{
+			Page:  page,
+			Limit: limit,
+			Total: totalWebhooks,
+		}
+
+		if totalWebhooks > 0 && offset < totalWebhooks {
+			paginatedList.Webhooks, err = repository.FindAll(r.Context(), limit, offset)
+			if err != nil {
+				response.MustJSONError(r.Context(), w, errors.Wrap(err))
+				return
+			}
+		}
+
+		if err := response.JSON(r.Context(), w, http.StatusOK, paginatedList); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// BuildListWebhookDeliveriesHandler wraps webhook delivery log with http.Handler
+// responds with delivery attempts of the webhook, the most recent first
+func BuildListWebhookDeliveriesHandler(deliveries basewebhook.DeliveryLog) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		id, err := webhookID(r)
+		if err != nil {
+			response.MustJSONError(r.Context(), w, err)
+			return
+		}
+
+		pageInt, _ := strconv.ParseInt(r.URL.Query().Get("page"), 10, 32)
+		limitInt, _ := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 32)
+		page := int(math.Max(float64(pageInt), 1))
+		limit := int(math.Max(float64(limitInt), 20))
+
+		list, err := deliveries.List(r.Context(), id, (page*limit)-limit, limit)
+		if err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			return
+		}
+
+		paginatedList := struct {
+			Deliveries []basewebhook.Delivery `json:"deliveries"`
+			Page       int                    `json:"page"`
+			Limit      int                    `json:"limit"`
+		}{
+			Deliveries: list,
+			Page:       page,
+			Limit:      limit,
+		}
+
+		if err := response.JSON(r.Context(), w, http.StatusOK, paginatedList); err != nil {
+			response.MustJSONError(r.Context(), w, errors.Wrap(err))
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+func webhookID(r *http.Request) (uuid.UUID, error) {
+	params, ok := context.Parameters(r.Context())
+	if !ok {
+		return uuid.Nil, ErrInvalidURLParams
+	}
+
+	id, err := uuid.Parse(params.Value("id"))
+	if err != nil {
+		return uuid.Nil, ErrInvalidURLParams
+	}
+
+	return id, nil
+}
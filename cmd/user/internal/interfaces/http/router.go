@@ -18,11 +18,15 @@ import (
 	"github.com/vardius/go-api-boilerplate/pkg/auth"
 	"github.com/vardius/go-api-boilerplate/pkg/auth/oauth2"
 	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/deadletter"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
 	httpmiddleware "github.com/vardius/go-api-boilerplate/pkg/http/middleware"
 	httpauthenticator "github.com/vardius/go-api-boilerplate/pkg/http/middleware/authenticator"
 	"github.com/vardius/go-api-boilerplate/pkg/http/response"
 	"github.com/vardius/go-api-boilerplate/pkg/identity"
 	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/webhook"
 )
 
 const googleAPIURL = "https://www.googleapis.com/oauth2/v2/userinfo"
@@ -32,6 +36,12 @@ const facebookAPIURL = "https://graph.facebook.com/me"
 func NewRouter(logger *log.Logger,
 	tokenAuthorizer auth.TokenAuthorizer,
 	repository userpersistence.UserRepository,
+	userRepository user.Repository,
+	webhookRepository userpersistence.WebhookRepository,
+	webhookDeliveries webhook.DeliveryLog,
+	eventStore eventstore.EventStore,
+	eventBus eventbus.EventBus,
+	deadLetters deadletter.Admin,
 	commandBus commandbus.CommandBus,
 	tokenProvider oauth2.TokenProvider,
 	mysqlConnection *sql.DB,
@@ -73,13 +83,34 @@ func NewRouter(logger *log.Logger,
 
 	router.GET("/", handlers.BuildListUserHandler(repository))
 	router.GET("/me", handlers.BuildMeHandler(repository))
+	router.GET("/me/events", handlers.BuildMeEventsHandler(eventStore, eventBus))
 	router.GET("/{id}", handlers.BuildGetUserHandler(repository))
+	router.GET("/{id}/history", handlers.BuildUserHistoryHandler(userRepository))
 	router.POST("/google/callback", handlers.BuildSocialAuthHandler(googleAPIURL, commandBus, user.RegisterUserWithGoogle, tokenProvider, identityProvider))
 	router.POST("/facebook/callback", handlers.BuildSocialAuthHandler(facebookAPIURL, commandBus, user.RegisterUserWithFacebook, tokenProvider, identityProvider))
 	router.POST("/dispatch/{command}", handlers.BuildCommandDispatchHandler(commandBus))
+	router.GET("/dead-letters", handlers.BuildListDeadLettersHandler(deadLetters))
+	router.POST("/dead-letters/{id}/retry", handlers.BuildRetryDeadLetterHandler(deadLetters))
+	router.DELETE("/dead-letters/{id}", handlers.BuildDiscardDeadLetterHandler(deadLetters))
+	router.GET("/webhooks", handlers.BuildListWebhooksHandler(webhookRepository))
+	router.POST("/webhooks", handlers.BuildCreateWebhookHandler(commandBus))
+	router.GET("/webhooks/{id}", handlers.BuildGetWebhookHandler(webhookRepository))
+	router.DELETE("/webhooks/{id}", handlers.BuildRemoveWebhookHandler(commandBus))
+	router.GET("/webhooks/{id}/deliveries", handlers.BuildListWebhookDeliveriesHandler(webhookDeliveries))
 
 	router.USE(http.MethodGet, "/me", httpmiddleware.GrantAccessFor(identity.RoleUser))
+	router.USE(http.MethodGet, "/me/events", httpmiddleware.GrantAccessFor(identity.RoleUser))
+	router.USE(http.MethodGet, "/{id}/history", httpmiddleware.GrantAccessFor(identity.RoleAdmin))
+	router.USE(http.MethodGet, "/dead-letters", httpmiddleware.GrantAccessFor(identity.RoleAdmin))
+	router.USE(http.MethodPost, "/dead-letters/{id}/retry", httpmiddleware.GrantAccessFor(identity.RoleAdmin))
+	router.USE(http.MethodDelete, "/dead-letters/{id}", httpmiddleware.GrantAccessFor(identity.RoleAdmin))
+	router.USE(http.MethodGet, "/webhooks", httpmiddleware.GrantAccessFor(identity.RoleAdmin))
+	router.USE(http.MethodPost, "/webhooks", httpmiddleware.GrantAccessFor(identity.RoleAdmin))
+	router.USE(http.MethodGet, "/webhooks/{id}", httpmiddleware.GrantAccessFor(identity.RoleAdmin))
+	router.USE(http.MethodDelete, "/webhooks/{id}", httpmiddleware.GrantAccessFor(identity.RoleAdmin))
+	router.USE(http.MethodGet, "/webhooks/{id}/deliveries", httpmiddleware.GrantAccessFor(identity.RoleAdmin))
 	router.USE(http.MethodPost, "/dispatch/"+user.ChangeUserEmailAddress, httpmiddleware.GrantAccessFor(identity.RoleUser))
+	router.USE(http.MethodPost, "/dispatch/"+user.ForgetUser, httpmiddleware.GrantAccessFor(identity.RoleUser))
 
 	mainRouter := gorouter.New()
 	mainRouter.NotFound(response.NotFound())
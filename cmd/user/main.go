@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"math/rand"
+	"net/http"
+	"os"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
 	"github.com/vardius/gocontainer"
 	"google.golang.org/grpc"
 	grpchealth "google.golang.org/grpc/health"
@@ -15,7 +19,9 @@ import (
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/application/config"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/application/eventhandler"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/application/identity"
+	appwebhook "github.com/vardius/go-api-boilerplate/cmd/user/internal/application/webhook"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/webhook"
 	persistence "github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/persistence/mysql"
 	"github.com/vardius/go-api-boilerplate/cmd/user/internal/infrastructure/repository"
 	usergrpc "github.com/vardius/go-api-boilerplate/cmd/user/internal/interfaces/grpc"
@@ -25,11 +31,37 @@ import (
 	oauth2util "github.com/vardius/go-api-boilerplate/pkg/auth/oauth2"
 	"github.com/vardius/go-api-boilerplate/pkg/buildinfo"
 	commandbus "github.com/vardius/go-api-boilerplate/pkg/commandbus/memory"
+	commandbusmiddleware "github.com/vardius/go-api-boilerplate/pkg/commandbus/middleware"
+	"github.com/vardius/go-api-boilerplate/pkg/consumergroup"
+	mysqlconsumergroup "github.com/vardius/go-api-boilerplate/pkg/consumergroup/mysql"
+	"github.com/vardius/go-api-boilerplate/pkg/deadletter"
+	mysqldeadletter "github.com/vardius/go-api-boilerplate/pkg/deadletter/mysql"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	baseeventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus"
 	eventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
-	eventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/mysql"
+	eventbusmiddleware "github.com/vardius/go-api-boilerplate/pkg/eventbus/middleware"
+	baseeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	fileeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/file"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	mysqleventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/mysql"
+	postgreseventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/postgres"
 	grpcutils "github.com/vardius/go-api-boilerplate/pkg/grpc"
+	"github.com/vardius/go-api-boilerplate/pkg/idempotency"
+	mysqlidempotency "github.com/vardius/go-api-boilerplate/pkg/idempotency/mysql"
+	keystore "github.com/vardius/go-api-boilerplate/pkg/keystore/mysql"
 	"github.com/vardius/go-api-boilerplate/pkg/log"
 	"github.com/vardius/go-api-boilerplate/pkg/mysql"
+	baseoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox"
+	memoryoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox/memory"
+	mysqloutbox "github.com/vardius/go-api-boilerplate/pkg/outbox/mysql"
+	postgresoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox/postgres"
+	"github.com/vardius/go-api-boilerplate/pkg/postgres"
+	"github.com/vardius/go-api-boilerplate/pkg/projection"
+	mysqlprojection "github.com/vardius/go-api-boilerplate/pkg/projection/mysql"
+	basesnapshotstore "github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
+	snapshotstore "github.com/vardius/go-api-boilerplate/pkg/snapshotstore/mysql"
+	basewebhook "github.com/vardius/go-api-boilerplate/pkg/webhook"
+	mysqlwebhook "github.com/vardius/go-api-boilerplate/pkg/webhook/mysql"
 )
 
 func init() {
@@ -60,7 +92,14 @@ func main() {
 		// 	firewall.GrantAccessForStreamRequest(identity.RoleUser),
 		// },
 	)
-	commandBus := commandbus.New(config.Env.CommandBus.QueueSize, logger)
+	commandBus := commandbusmiddleware.NewCommandBus(
+		commandbus.New(config.Env.CommandBus.QueueSize, logger),
+		commandbusmiddleware.Logger(logger),
+		commandbusmiddleware.Metrics(),
+		commandbusmiddleware.Recover(logger),
+		commandbusmiddleware.Authorize(),
+		commandbusmiddleware.Validate(),
+	)
 
 	mysqlConnection := mysql.NewConnection(
 		ctx,
@@ -100,12 +139,109 @@ func main() {
 	)
 	defer grpcAuthConn.Close()
 
-	eventStore := eventstore.New(mysqlConnection)
-	eventBus := eventbus.New(config.Env.EventBus.QueueSize, logger)
+	var (
+		eventStore  baseeventstore.EventStore
+		eventOutbox baseoutbox.Outbox
+	)
+	switch config.Env.EventStore.Driver {
+	case "mysql":
+		if config.Env.EventBus.Outbox {
+			eventStore = mysqleventstore.NewWithOutbox(mysqlConnection, "user")
+			eventOutbox = mysqloutbox.New(mysqlConnection, "user")
+		} else {
+			eventStore = mysqleventstore.New(mysqlConnection)
+		}
+	case "postgres":
+		postgresConnection := postgres.NewConnection(
+			ctx,
+			postgres.ConnectionConfig{
+				Host:            config.Env.Postgres.Host,
+				Port:            config.Env.Postgres.Port,
+				User:            config.Env.Postgres.User,
+				Pass:            config.Env.Postgres.Pass,
+				Database:        config.Env.Postgres.Database,
+				SSLMode:         config.Env.Postgres.SSLMode,
+				ConnMaxLifetime: config.Env.Postgres.ConnMaxLifetime,
+				MaxIdleConns:    config.Env.Postgres.MaxIdleConns,
+				MaxOpenConns:    config.Env.Postgres.MaxOpenConns,
+			},
+			logger,
+		)
+		defer postgresConnection.Close()
+		if config.Env.EventBus.Outbox {
+			eventStore = postgreseventstore.NewWithOutbox(postgresConnection, "user")
+			eventOutbox = postgresoutbox.New(postgresConnection, "user")
+		} else {
+			eventStore = postgreseventstore.New(postgresConnection)
+		}
+	case "file":
+		fileEventStore, err := fileeventstore.New(config.Env.EventStore.FileDir, fileeventstore.Config{
+			SegmentSize:  config.Env.EventStore.FileSegmentSize,
+			Sync:         fileeventstore.SyncMode(config.Env.EventStore.FileSync),
+			SyncInterval: config.Env.EventStore.FileSyncInterval,
+		})
+		if err != nil {
+			panic(err)
+		}
+		defer fileEventStore.Close()
+		eventStore = fileEventStore
+	case "memory":
+		eventStore = memoryeventstore.New()
+	default:
+		panic(fmt.Errorf("unsupported event store driver: %s", config.Env.EventStore.Driver))
+	}
+	if config.Env.EventBus.Outbox && eventOutbox == nil {
+		// file and memory event stores can not share transaction with the outbox
+		memoryOutbox := memoryoutbox.New()
+		eventStore = baseoutbox.WithOutbox(eventStore, memoryOutbox)
+		eventOutbox = memoryOutbox
+	}
+	// personal data is encrypted with per user keys, forgetting user destroys the key
+	keyStore := keystore.New(mysqlConnection)
+	eventStore = baseeventstore.WithCryptoShredding(eventStore, keyStore)
+	// upcasters transform payloads of evolved events read back from the event store to their current schema version,
+	// they wrap decrypting store so personal data fields are upcasted as plaintext
+	eventUpcasters := domain.NewUpcasterRegistry()
+	eventStore = baseeventstore.WithUpcasting(eventStore, eventUpcasters)
+	snapshotStore := basesnapshotstore.WithEncryption(snapshotstore.New(mysqlConnection), keyStore)
+	snapshotPolicy := basesnapshotstore.EveryNEvents(config.Env.EventStore.SnapshotFrequency)
+	memoryEventBus := eventbus.New(config.Env.EventBus.QueueSize, logger)
+	if config.Env.EventBus.OrderedDelivery {
+		memoryEventBus = eventbus.NewOrdered(config.Env.EventBus.QueueSize, logger)
+	}
+	// failing event handlers are retried, events they could not handle are kept as dead letters
+	eventBus := deadletter.NewEventBus(
+		// middleware wraps handlers together with their retries, timeout applies to all attempts
+		eventbusmiddleware.NewEventBus(
+			memoryEventBus,
+			eventbusmiddleware.Trace(),
+			eventbusmiddleware.Logger(logger),
+			eventbusmiddleware.Metrics(),
+			eventbusmiddleware.Recover(logger),
+			eventbusmiddleware.Timeout(config.Env.App.EventHandlerTimeout),
+		),
+		mysqldeadletter.New(mysqlConnection, "user"),
+		eventStore,
+		deadletter.RetryPolicy{
+			MaxAttempts:    config.Env.EventBus.RetryMaxAttempts,
+			InitialBackoff: config.Env.EventBus.RetryInitialBackoff,
+			MaxBackoff:     config.Env.EventBus.RetryMaxBackoff,
+		},
+		logger,
+	)
+	// repositories publish through outbox relay so events are not lost when process dies after storing them
+	var repositoryEventBus baseeventbus.EventBus = eventBus
+	if eventOutbox != nil {
+		repositoryEventBus = baseoutbox.WithDeferredPublish(eventBus, eventOutbox, config.Env.EventBus.OutboxClaimTimeout)
+	}
 	userPersistenceRepository := persistence.NewUserRepository(mysqlConnection)
-	userRepository := repository.NewUserRepository(eventStore, eventBus)
+	userRepository := repository.NewUserRepository(eventStore, repositoryEventBus, snapshotStore, snapshotPolicy, logger)
+	webhookPersistenceRepository := persistence.NewWebhookRepository(mysqlConnection)
+	webhookRepository := repository.NewWebhookRepository(eventStore, repositoryEventBus, snapshotStore, snapshotPolicy, logger)
+	webhookDeliveries := mysqlwebhook.New(mysqlConnection)
 	grpcHealthServer := grpchealth.NewServer()
-	grpcUserServer := usergrpc.NewServer(commandBus, userPersistenceRepository)
+	// each subscriber stream subscribes its own handler, dead-letter bus would replace handlers created by the same function
+	grpcUserServer := usergrpc.NewServer(commandBus, userPersistenceRepository, eventStore, memoryEventBus)
 	grpAuthClient := authproto.NewAuthenticationServiceClient(grpcAuthConn)
 	authenticator := auth.NewSecretAuthenticator([]byte(config.Env.Auth.Secret))
 	tokenProvider := oauth2util.NewCredentialsAuthenticator(config.Env.Auth.Host, config.Env.HTTP.Port, config.Env.Auth.Secret)
@@ -116,6 +252,13 @@ func main() {
 		logger,
 		tokenAuthorizer,
 		userPersistenceRepository,
+		userRepository,
+		webhookPersistenceRepository,
+		webhookDeliveries,
+		eventStore,
+		// each stream subscribes its own handler, dead-letter bus would replace handlers created by the same function
+		memoryEventBus,
+		eventBus,
 		commandBus,
 		tokenProvider,
 		mysqlConnection,
@@ -141,24 +284,81 @@ func main() {
 	if err := commandBus.Subscribe(ctx, (user.RequestAccessToken{}).GetName(), user.OnRequestAccessToken(userRepository, mysqlConnection)); err != nil {
 		panic(err)
 	}
-
-	if err := eventBus.Subscribe(ctx, (user.WasRegisteredWithEmail{}).GetType(), eventhandler.WhenUserWasRegisteredWithEmail(mysqlConnection, userPersistenceRepository, tokenProvider, grpAuthClient)); err != nil {
+	if err := commandBus.Subscribe(ctx, (user.Forget{}).GetName(), user.OnForget(userRepository, keyStore)); err != nil {
 		panic(err)
 	}
-	if err := eventBus.Subscribe(ctx, (user.WasRegisteredWithGoogle{}).GetType(), eventhandler.WhenUserWasRegisteredWithGoogle(mysqlConnection, userPersistenceRepository, grpAuthClient)); err != nil {
+	if err := commandBus.Subscribe(ctx, (webhook.Create{}).GetName(), webhook.OnCreate(webhookRepository)); err != nil {
 		panic(err)
 	}
-	if err := eventBus.Subscribe(ctx, (user.WasRegisteredWithFacebook{}).GetType(), eventhandler.WhenUserWasRegisteredWithFacebook(mysqlConnection, userPersistenceRepository, grpAuthClient)); err != nil {
+	if err := commandBus.Subscribe(ctx, (webhook.Remove{}).GetName(), webhook.OnRemove(webhookRepository)); err != nil {
 		panic(err)
 	}
-	if err := eventBus.Subscribe(ctx, (user.EmailAddressWasChanged{}).GetType(), eventhandler.WhenUserEmailAddressWasChanged(mysqlConnection, userPersistenceRepository)); err != nil {
-		panic(err)
+
+	// projections resume from their checkpoint after restart and handle events in order they were stored
+	projections := consumergroup.New("user-projections", eventStore, eventBus, mysqlconsumergroup.New(mysqlConnection), logger, consumergroup.Config{
+		BatchSize:    config.Env.EventBus.ConsumerGroupBatchSize,
+		PollInterval: config.Env.EventBus.ConsumerGroupPollInterval,
+		// projections were built by handlers subscribed to the bus before, past events are already handled
+		StartAtEnd: true,
+	})
+	// redelivered events are skipped, events handlers failed to handle are handled again so they have to be safe to repeat
+	ledger := mysqlidempotency.New(mysqlConnection)
+	projections.Subscribe((user.WasRegisteredWithEmail{}).GetType(), idempotency.Handler(ledger, "user.WhenUserWasRegisteredWithEmail", eventhandler.WhenUserWasRegisteredWithEmail(mysqlConnection, userPersistenceRepository, tokenProvider, identityProvider, grpAuthClient)))
+	projections.Subscribe((user.WasRegisteredWithGoogle{}).GetType(), idempotency.Handler(ledger, "user.WhenUserWasRegisteredWithGoogle", eventhandler.WhenUserWasRegisteredWithGoogle(mysqlConnection, userPersistenceRepository, identityProvider, grpAuthClient)))
+	projections.Subscribe((user.WasRegisteredWithFacebook{}).GetType(), idempotency.Handler(ledger, "user.WhenUserWasRegisteredWithFacebook", eventhandler.WhenUserWasRegisteredWithFacebook(mysqlConnection, userPersistenceRepository, identityProvider, grpAuthClient)))
+	projections.Subscribe((user.EmailAddressWasChanged{}).GetType(), idempotency.Handler(ledger, "user.WhenUserEmailAddressWasChanged", eventhandler.WhenUserEmailAddressWasChanged(mysqlConnection, userPersistenceRepository)))
+	projections.Subscribe((user.WasForgotten{}).GetType(), idempotency.Handler(ledger, "user.WhenUserWasForgotten", eventhandler.WhenUserWasForgotten(userPersistenceRepository)))
+	projections.Subscribe((webhook.WasCreated{}).GetType(), idempotency.Handler(ledger, "user.WhenWebhookWasCreated", eventhandler.WhenWebhookWasCreated(webhookPersistenceRepository)))
+	projections.Subscribe((webhook.WasRemoved{}).GetType(), idempotency.Handler(ledger, "user.WhenWebhookWasRemoved", eventhandler.WhenWebhookWasRemoved(webhookPersistenceRepository)))
+
+	// read model is rebuilt with handlers writing to shadow table, see pkg/projection
+	projectionRunner := projection.NewRunner(eventStore, mysqlprojection.New(mysqlConnection), logger, projection.Config{
+		BatchSize: config.Env.EventBus.ConsumerGroupBatchSize,
+	}, projection.Projection{
+		Name:  "users",
+		Table: "users",
+		Handlers: map[string]baseeventbus.EventHandler{
+			(user.WasRegisteredWithEmail{}).GetType():    eventhandler.WhenUserWasRegisteredWithEmail(mysqlConnection, userPersistenceRepository, tokenProvider, identityProvider, grpAuthClient),
+			(user.WasRegisteredWithGoogle{}).GetType():   eventhandler.WhenUserWasRegisteredWithGoogle(mysqlConnection, userPersistenceRepository, identityProvider, grpAuthClient),
+			(user.WasRegisteredWithFacebook{}).GetType(): eventhandler.WhenUserWasRegisteredWithFacebook(mysqlConnection, userPersistenceRepository, identityProvider, grpAuthClient),
+			(user.EmailAddressWasChanged{}).GetType():    eventhandler.WhenUserEmailAddressWasChanged(mysqlConnection, userPersistenceRepository),
+			(user.WasForgotten{}).GetType():              eventhandler.WhenUserWasForgotten(userPersistenceRepository),
+		},
+	}, projection.Projection{
+		Name:  "webhooks",
+		Table: "webhooks",
+		Handlers: map[string]baseeventbus.EventHandler{
+			(webhook.WasCreated{}).GetType(): eventhandler.WhenWebhookWasCreated(webhookPersistenceRepository),
+			(webhook.WasRemoved{}).GetType(): eventhandler.WhenWebhookWasRemoved(webhookPersistenceRepository),
+		},
+	})
+
+	// user rebuild-projection <name> rebuilds read model and exits, projections of running service are not held
+	// so it has to be stopped meanwhile, debug adapter rebuilds them without downtime
+	if flag.Arg(0) == "rebuild-projection" {
+		if err := projectionRunner.Rebuild(ctx, flag.Arg(1), nil); err != nil {
+			logger.Critical(ctx, "%v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
+
 	if err := eventBus.Subscribe(ctx, (user.AccessTokenWasRequested{}).GetType(), eventhandler.WhenUserAccessTokenWasRequested(tokenProvider, identityProvider)); err != nil {
 		panic(err)
 	}
 
 	app.AddAdapters(
+		projections,
+		// events are sent to webhooks subscribed to them, each attempt is kept in delivery log
+		basewebhook.NewDispatcher(eventBus, appwebhook.NewSubscriptions(webhookPersistenceRepository), webhookDeliveries, http.DefaultClient, logger, basewebhook.Config{
+			Retry: deadletter.RetryPolicy{
+				MaxAttempts:    config.Env.Webhook.RetryMaxAttempts,
+				InitialBackoff: config.Env.Webhook.RetryInitialBackoff,
+				MaxBackoff:     config.Env.Webhook.RetryMaxBackoff,
+			},
+			Timeout:     config.Env.Webhook.Timeout,
+			Concurrency: config.Env.Webhook.Concurrency,
+		}),
 		userhttp.NewAdapter(
 			fmt.Sprintf("%s:%d", config.Env.HTTP.Host, config.Env.HTTP.Port),
 			router,
@@ -171,12 +371,24 @@ func main() {
 		),
 	)
 
-	if config.Env.App.Environment == "development" {
+	if eventOutbox != nil {
 		app.AddAdapters(
-			application.NewDebugAdapter(
-				fmt.Sprintf("%s:%d", config.Env.Debug.Host, config.Env.Debug.Port),
-			),
+			baseoutbox.NewRelay(eventOutbox, eventStore, eventBus, logger, baseoutbox.RelayConfig{
+				PollInterval: config.Env.EventBus.OutboxPollInterval,
+				BatchSize:    config.Env.EventBus.OutboxBatchSize,
+				MaxAttempts:  config.Env.EventBus.OutboxMaxAttempts,
+				ClaimTimeout: config.Env.EventBus.OutboxClaimTimeout,
+			}),
+		)
+	}
+
+	if config.Env.App.Environment == "development" {
+		debugAdapter := application.NewDebugAdapter(
+			fmt.Sprintf("%s:%d", config.Env.Debug.Host, config.Env.Debug.Port),
 		)
+		debugAdapter.Handle("/debug/projections", projection.NewDebugHandler(projectionRunner, projections))
+
+		app.AddAdapters(debugAdapter)
 	}
 
 	app.WithShutdownTimeout(config.Env.App.ShutdownTimeout)
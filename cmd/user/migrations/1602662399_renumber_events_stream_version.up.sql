@@ -0,0 +1,15 @@
+START TRANSACTION;
+-- events saved together used to share stream version,
+-- versions are renumbered in order events were stored so unique key can be added
+UPDATE `events` AS e
+    INNER JOIN (
+        SELECT e1.`distinct_id`, COUNT(e2.`distinct_id`) AS `stream_version`
+        FROM `events` AS e1
+                 LEFT JOIN `events` AS e2
+                           ON e2.`stream_id` = e1.`stream_id`
+                               AND e2.`stream_name` = e1.`stream_name`
+                               AND e2.`distinct_id` < e1.`distinct_id`
+        GROUP BY e1.`distinct_id`
+    ) AS v ON v.`distinct_id` = e.`distinct_id`
+SET e.`stream_version` = v.`stream_version`;
+COMMIT;
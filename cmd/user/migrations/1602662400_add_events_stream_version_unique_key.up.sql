@@ -0,0 +1,4 @@
+START TRANSACTION;
+ALTER TABLE `events`
+    ADD UNIQUE KEY `u_stream_id_stream_name_stream_version` (`stream_id`, `stream_name`, `stream_version`);
+COMMIT;
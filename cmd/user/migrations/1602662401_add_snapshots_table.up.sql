@@ -0,0 +1,16 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS `snapshots`
+(
+    `distinct_id`    INT          NOT NULL AUTO_INCREMENT,
+    `stream_id`      CHAR(36)     NOT NULL,
+    `stream_name`    VARCHAR(255) NOT NULL,
+    `stream_version` INT          NOT NULL,
+    `taken_at`       DATETIME DEFAULT NULL,
+    `payload`        JSON     DEFAULT NULL,
+    PRIMARY KEY (`distinct_id`),
+    UNIQUE KEY `u_stream_id_stream_name` (`stream_id`, `stream_name`)
+)
+    ENGINE = InnoDB
+    DEFAULT CHARSET = utf8
+    COLLATE = utf8_bin;
+COMMIT;
@@ -0,0 +1,4 @@
+START TRANSACTION;
+ALTER TABLE `events`
+    ADD COLUMN `schema_version` INT NOT NULL DEFAULT 1 AFTER `stream_version`;
+COMMIT;
@@ -0,0 +1,14 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS `subject_keys`
+(
+    `distinct_id`  INT           NOT NULL AUTO_INCREMENT,
+    `subject_id`   CHAR(36)      NOT NULL,
+    `subject_key`  VARBINARY(32) DEFAULT NULL,
+    `forgotten_at` DATETIME      DEFAULT NULL,
+    PRIMARY KEY (`distinct_id`),
+    UNIQUE KEY `u_subject_id` (`subject_id`)
+)
+    ENGINE = InnoDB
+    DEFAULT CHARSET = utf8
+    COLLATE = utf8_bin;
+COMMIT;
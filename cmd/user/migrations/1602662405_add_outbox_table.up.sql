@@ -0,0 +1,18 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS `outbox`
+(
+    `distinct_id`   BIGINT       NOT NULL AUTO_INCREMENT,
+    `outbox_name`   VARCHAR(255) NOT NULL,
+    `event_id`      CHAR(36)     NOT NULL,
+    `attempts`      INT          NOT NULL DEFAULT 0,
+    `last_error`    TEXT     DEFAULT NULL,
+    `created_at`    DATETIME     NOT NULL,
+    `dispatched_at` DATETIME DEFAULT NULL,
+    PRIMARY KEY (`distinct_id`),
+    UNIQUE KEY `u_outbox_name_event_id` (`outbox_name`, `event_id`),
+    INDEX `i_outbox_name_dispatched_at` (`outbox_name`, `dispatched_at`)
+)
+    ENGINE = InnoDB
+    DEFAULT CHARSET = utf8
+    COLLATE = utf8_bin;
+COMMIT;
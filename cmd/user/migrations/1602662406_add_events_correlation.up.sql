@@ -0,0 +1,6 @@
+START TRANSACTION;
+ALTER TABLE `events`
+    ADD COLUMN `correlation_id` VARCHAR(255) DEFAULT NULL AFTER `schema_version`,
+    ADD COLUMN `causation_id`   VARCHAR(255) DEFAULT NULL AFTER `correlation_id`,
+    ADD INDEX `i_correlation_id` (`correlation_id`);
+COMMIT;
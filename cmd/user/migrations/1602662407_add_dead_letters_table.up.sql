@@ -0,0 +1,19 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS `dead_letters`
+(
+    `distinct_id` BIGINT       NOT NULL AUTO_INCREMENT,
+    `id`          CHAR(36)     NOT NULL,
+    `store_name`  VARCHAR(255) NOT NULL,
+    `event`       JSON         NOT NULL,
+    `handler`     VARCHAR(255) NOT NULL,
+    `attempts`    INT          NOT NULL DEFAULT 0,
+    `last_error`  TEXT         NOT NULL,
+    `failed_at`   DATETIME     NOT NULL,
+    PRIMARY KEY (`distinct_id`),
+    UNIQUE KEY `u_id` (`id`),
+    INDEX `i_store_name` (`store_name`)
+)
+    ENGINE = InnoDB
+    DEFAULT CHARSET = utf8
+    COLLATE = utf8_bin;
+COMMIT;
@@ -0,0 +1,12 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS `consumer_group_checkpoints`
+(
+    `group_name` VARCHAR(255)    NOT NULL,
+    `position`   BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    `updated_at` DATETIME        NOT NULL,
+    PRIMARY KEY (`group_name`)
+)
+    ENGINE = InnoDB
+    DEFAULT CHARSET = utf8
+    COLLATE = utf8_bin;
+COMMIT;
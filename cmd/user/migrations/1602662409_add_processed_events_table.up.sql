@@ -0,0 +1,12 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS `processed_events`
+(
+    `handler`      VARCHAR(255) NOT NULL,
+    `event_id`     CHAR(36)     NOT NULL,
+    `processed_at` DATETIME     NOT NULL,
+    PRIMARY KEY (`handler`, `event_id`)
+)
+    ENGINE = InnoDB
+    DEFAULT CHARSET = utf8
+    COLLATE = utf8_bin;
+COMMIT;
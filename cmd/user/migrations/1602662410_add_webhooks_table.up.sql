@@ -0,0 +1,15 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS `webhooks`
+(
+    `distinct_id` INT          NOT NULL AUTO_INCREMENT,
+    `id`          CHAR(36)     NOT NULL,
+    `url`         TEXT         NOT NULL,
+    `event_types` JSON         NOT NULL,
+    `secret`      VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`distinct_id`),
+    UNIQUE KEY `id` (`id`)
+)
+    ENGINE = InnoDB
+    DEFAULT CHARSET = utf8
+    COLLATE = utf8_bin;
+COMMIT;
@@ -0,0 +1,22 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS `webhook_deliveries`
+(
+    `distinct_id`     BIGINT       NOT NULL AUTO_INCREMENT,
+    `id`              CHAR(36)     NOT NULL,
+    `subscription_id` CHAR(36)     NOT NULL,
+    `event_id`        CHAR(36)     NOT NULL,
+    `event_type`      VARCHAR(255) NOT NULL,
+    `attempt`         INT          NOT NULL,
+    `status_code`     INT          NOT NULL DEFAULT 0,
+    `error`           TEXT         NOT NULL,
+    `succeeded`       BOOLEAN      NOT NULL DEFAULT FALSE,
+    `duration_ms`     BIGINT       NOT NULL DEFAULT 0,
+    `delivered_at`    DATETIME     NOT NULL,
+    PRIMARY KEY (`distinct_id`),
+    UNIQUE KEY `u_id` (`id`),
+    INDEX `i_subscription_id` (`subscription_id`)
+)
+    ENGINE = InnoDB
+    DEFAULT CHARSET = utf8
+    COLLATE = utf8_bin;
+COMMIT;
@@ -0,0 +1,11 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS `events_append_lock`
+(
+    `id` INT NOT NULL,
+    PRIMARY KEY (`id`)
+)
+    ENGINE = InnoDB
+    DEFAULT CHARSET = utf8
+    COLLATE = utf8_bin;
+INSERT IGNORE INTO `events_append_lock` (`id`) VALUES (1);
+COMMIT;
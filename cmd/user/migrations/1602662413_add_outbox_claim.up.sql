@@ -0,0 +1,6 @@
+START TRANSACTION;
+ALTER TABLE `outbox`
+    ADD COLUMN `claim_id`      CHAR(36)    DEFAULT NULL AFTER `last_error`,
+    ADD COLUMN `claimed_until` DATETIME(6) DEFAULT NULL AFTER `claim_id`,
+    ADD INDEX `i_outbox_name_claim_id` (`outbox_name`, `claim_id`);
+COMMIT;
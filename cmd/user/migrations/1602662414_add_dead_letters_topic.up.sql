@@ -0,0 +1,7 @@
+START TRANSACTION;
+ALTER TABLE `dead_letters`
+    ADD COLUMN `topic` VARCHAR(255) NOT NULL DEFAULT '' AFTER `event`;
+-- handlers of dead letters saved so far were looked up by event type
+UPDATE `dead_letters`
+SET `topic` = JSON_UNQUOTE(JSON_EXTRACT(`event`, '$.metadata.type'));
+COMMIT;
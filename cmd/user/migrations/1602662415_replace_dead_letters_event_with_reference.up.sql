@@ -0,0 +1,11 @@
+START TRANSACTION;
+-- events are read from event store to retry dead letters, their personal data is not kept outside of it
+ALTER TABLE `dead_letters`
+    ADD COLUMN `event_id`   CHAR(36)     NOT NULL DEFAULT '' AFTER `store_name`,
+    ADD COLUMN `event_type` VARCHAR(255) NOT NULL DEFAULT '' AFTER `event_id`;
+UPDATE `dead_letters`
+SET `event_id`   = JSON_UNQUOTE(JSON_EXTRACT(`event`, '$.id')),
+    `event_type` = JSON_UNQUOTE(JSON_EXTRACT(`event`, '$.metadata.type'));
+ALTER TABLE `dead_letters`
+    DROP COLUMN `event`;
+COMMIT;
@@ -7,7 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
-	empty "github.com/golang/protobuf/ptypes/empty"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -73,6 +73,55 @@ func (m *DispatchCommandRequest) GetPayload() []byte {
 	return nil
 }
 
+// DispatchCommandResponse is a result of dispatched command,
+// id and version of aggregate root command created or changed
+type DispatchCommandResponse struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version              int32    `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DispatchCommandResponse) Reset()         { *m = DispatchCommandResponse{} }
+func (m *DispatchCommandResponse) String() string { return proto.CompactTextString(m) }
+func (*DispatchCommandResponse) ProtoMessage()    {}
+func (*DispatchCommandResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{1}
+}
+
+func (m *DispatchCommandResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DispatchCommandResponse.Unmarshal(m, b)
+}
+func (m *DispatchCommandResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DispatchCommandResponse.Marshal(b, m, deterministic)
+}
+func (m *DispatchCommandResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DispatchCommandResponse.Merge(m, src)
+}
+func (m *DispatchCommandResponse) XXX_Size() int {
+	return xxx_messageInfo_DispatchCommandResponse.Size(m)
+}
+func (m *DispatchCommandResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DispatchCommandResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DispatchCommandResponse proto.InternalMessageInfo
+
+func (m *DispatchCommandResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *DispatchCommandResponse) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
 // User object
 type User struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -88,7 +137,7 @@ func (m *User) Reset()         { *m = User{} }
 func (m *User) String() string { return proto.CompactTextString(m) }
 func (*User) ProtoMessage()    {}
 func (*User) Descriptor() ([]byte, []int) {
-	return fileDescriptor_116e343673f7ffaf, []int{1}
+	return fileDescriptor_116e343673f7ffaf, []int{2}
 }
 
 func (m *User) XXX_Unmarshal(b []byte) error {
@@ -149,7 +198,7 @@ func (m *GetUserRequest) Reset()         { *m = GetUserRequest{} }
 func (m *GetUserRequest) String() string { return proto.CompactTextString(m) }
 func (*GetUserRequest) ProtoMessage()    {}
 func (*GetUserRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_116e343673f7ffaf, []int{2}
+	return fileDescriptor_116e343673f7ffaf, []int{3}
 }
 
 func (m *GetUserRequest) XXX_Unmarshal(b []byte) error {
@@ -190,7 +239,7 @@ func (m *ListUserRequest) Reset()         { *m = ListUserRequest{} }
 func (m *ListUserRequest) String() string { return proto.CompactTextString(m) }
 func (*ListUserRequest) ProtoMessage()    {}
 func (*ListUserRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_116e343673f7ffaf, []int{3}
+	return fileDescriptor_116e343673f7ffaf, []int{4}
 }
 
 func (m *ListUserRequest) XXX_Unmarshal(b []byte) error {
@@ -240,7 +289,7 @@ func (m *ListUserResponse) Reset()         { *m = ListUserResponse{} }
 func (m *ListUserResponse) String() string { return proto.CompactTextString(m) }
 func (*ListUserResponse) ProtoMessage()    {}
 func (*ListUserResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_116e343673f7ffaf, []int{4}
+	return fileDescriptor_116e343673f7ffaf, []int{5}
 }
 
 func (m *ListUserResponse) XXX_Unmarshal(b []byte) error {
@@ -289,12 +338,104 @@ func (m *ListUserResponse) GetTotal() int32 {
 	return 0
 }
 
+// SubscribeEventsRequest is a request data to stream user events,
+// events stored after fromPosition are streamed before live ones, without it only live events are streamed
+type SubscribeEventsRequest struct {
+	EventTypes           []string              `protobuf:"bytes,1,rep,name=eventTypes,proto3" json:"eventTypes,omitempty"`
+	FromPosition         *wrappers.UInt64Value `protobuf:"bytes,2,opt,name=fromPosition,proto3" json:"fromPosition,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *SubscribeEventsRequest) Reset()         { *m = SubscribeEventsRequest{} }
+func (m *SubscribeEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeEventsRequest) ProtoMessage()    {}
+func (*SubscribeEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{6}
+}
+
+func (m *SubscribeEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeEventsRequest.Unmarshal(m, b)
+}
+func (m *SubscribeEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *SubscribeEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeEventsRequest.Merge(m, src)
+}
+func (m *SubscribeEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_SubscribeEventsRequest.Size(m)
+}
+func (m *SubscribeEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeEventsRequest proto.InternalMessageInfo
+
+func (m *SubscribeEventsRequest) GetEventTypes() []string {
+	if m != nil {
+		return m.EventTypes
+	}
+	return nil
+}
+
+func (m *SubscribeEventsRequest) GetFromPosition() *wrappers.UInt64Value {
+	if m != nil {
+		return m.FromPosition
+	}
+	return nil
+}
+
+// SubscribeEventsResponse holds streamed event
+type SubscribeEventsResponse struct {
+	Payload              []byte   `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscribeEventsResponse) Reset()         { *m = SubscribeEventsResponse{} }
+func (m *SubscribeEventsResponse) String() string { return proto.CompactTextString(m) }
+func (*SubscribeEventsResponse) ProtoMessage()    {}
+func (*SubscribeEventsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_116e343673f7ffaf, []int{7}
+}
+
+func (m *SubscribeEventsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeEventsResponse.Unmarshal(m, b)
+}
+func (m *SubscribeEventsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeEventsResponse.Marshal(b, m, deterministic)
+}
+func (m *SubscribeEventsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeEventsResponse.Merge(m, src)
+}
+func (m *SubscribeEventsResponse) XXX_Size() int {
+	return xxx_messageInfo_SubscribeEventsResponse.Size(m)
+}
+func (m *SubscribeEventsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeEventsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeEventsResponse proto.InternalMessageInfo
+
+func (m *SubscribeEventsResponse) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*DispatchCommandRequest)(nil), "proto.DispatchCommandRequest")
+	proto.RegisterType((*DispatchCommandResponse)(nil), "proto.DispatchCommandResponse")
 	proto.RegisterType((*User)(nil), "proto.User")
 	proto.RegisterType((*GetUserRequest)(nil), "proto.GetUserRequest")
 	proto.RegisterType((*ListUserRequest)(nil), "proto.ListUserRequest")
 	proto.RegisterType((*ListUserResponse)(nil), "proto.ListUserResponse")
+	proto.RegisterType((*SubscribeEventsRequest)(nil), "proto.SubscribeEventsRequest")
+	proto.RegisterType((*SubscribeEventsResponse)(nil), "proto.SubscribeEventsResponse")
 }
 
 func init() {
@@ -302,31 +443,38 @@ func init() {
 }
 
 var fileDescriptor_116e343673f7ffaf = []byte{
-	// 381 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x51, 0x4f, 0xef, 0xd2, 0x40,
-	0x10, 0x4d, 0x4b, 0x2b, 0x76, 0x30, 0x60, 0x26, 0x8a, 0x4d, 0x89, 0xa6, 0xf6, 0x44, 0x4c, 0x2c,
-	0x09, 0x1e, 0xf5, 0x84, 0x7f, 0x49, 0x3c, 0x90, 0x1a, 0x3f, 0xc0, 0xb6, 0x5d, 0xca, 0xc6, 0x96,
-	0x5d, 0xbb, 0x5b, 0x13, 0xbe, 0x9f, 0x1f, 0xcc, 0xec, 0x6e, 0x8b, 0xc0, 0x8f, 0xd3, 0xce, 0x9b,
-	0x79, 0x3b, 0x6f, 0xe6, 0x0d, 0x40, 0x27, 0x69, 0x9b, 0x8a, 0x96, 0x2b, 0x8e, 0xbe, 0x79, 0xa2,
-	0x45, 0xc5, 0x79, 0x55, 0xd3, 0x95, 0x41, 0x79, 0xb7, 0x5f, 0xd1, 0x46, 0xa8, 0x93, 0xe5, 0x24,
-	0x5f, 0x60, 0xfe, 0x89, 0x49, 0x41, 0x54, 0x71, 0xf8, 0xc8, 0x9b, 0x86, 0x1c, 0xcb, 0x8c, 0xfe,
-	0xee, 0xa8, 0x54, 0x88, 0xe0, 0x1d, 0x49, 0x43, 0x43, 0x27, 0x76, 0x96, 0x41, 0x66, 0x62, 0x0c,
-	0x61, 0x2c, 0xc8, 0xa9, 0xe6, 0xa4, 0x0c, 0xdd, 0xd8, 0x59, 0x3e, 0xc9, 0x06, 0x98, 0x1c, 0xc0,
-	0xfb, 0x29, 0x69, 0x8b, 0x53, 0x70, 0x59, 0xd9, 0xff, 0x71, 0x59, 0x89, 0xcf, 0xc0, 0xa7, 0x0d,
-	0x61, 0xb5, 0xe1, 0x07, 0x99, 0x05, 0xf8, 0x0a, 0x60, 0x4f, 0x0a, 0x9a, 0x73, 0xfe, 0x6b, 0x5b,
-	0x86, 0x23, 0x53, 0xba, 0xc8, 0x60, 0x04, 0x8f, 0xed, 0xd0, 0xdb, 0x32, 0xf4, 0x4c, 0xf5, 0x8c,
-	0x93, 0x18, 0xa6, 0x5f, 0xa9, 0xd2, 0x62, 0xc3, 0xa4, 0x37, 0x9a, 0xc9, 0x7b, 0x98, 0x7d, 0x67,
-	0xf2, 0x8a, 0x82, 0xe0, 0x09, 0x52, 0xd9, 0x65, 0xfc, 0xcc, 0xc4, 0x7a, 0xb4, 0x9a, 0x35, 0x4c,
-	0x99, 0xd1, 0xfc, 0xcc, 0x82, 0xa4, 0x83, 0xa7, 0xff, 0x3f, 0x4b, 0xc1, 0x8f, 0x92, 0xe2, 0x6b,
-	0xf0, 0xb5, 0xad, 0x32, 0x74, 0xe2, 0xd1, 0x72, 0xb2, 0x9e, 0x58, 0xef, 0x52, 0xc3, 0xb1, 0x95,
-	0xb3, 0x80, 0x7b, 0x4f, 0x60, 0x74, 0x21, 0xa0, 0xb3, 0x8a, 0x2b, 0x52, 0x9b, 0xc5, 0xfc, 0xcc,
-	0x82, 0xf5, 0x5f, 0x07, 0x26, 0xba, 0xdf, 0x0f, 0xda, 0xfe, 0x61, 0x05, 0xc5, 0x6f, 0x30, 0xbb,
-	0xb9, 0x0b, 0xbe, 0xec, 0x65, 0xef, 0xdf, 0x2b, 0x9a, 0xa7, 0xd6, 0xa2, 0x74, 0xb8, 0x73, 0xfa,
-	0x59, 0xdf, 0x19, 0xdf, 0xc2, 0xb8, 0xf7, 0x0b, 0x9f, 0xf7, 0x1d, 0xae, 0xfd, 0x8b, 0x2e, 0xf7,
-	0xc1, 0x0f, 0x10, 0x0c, 0xfb, 0x4b, 0x9c, 0xf7, 0x95, 0x1b, 0x3b, 0xa3, 0x17, 0x0f, 0xf2, 0xd6,
-	0xa9, 0xcd, 0x1b, 0x58, 0x54, 0x9c, 0x08, 0x96, 0x73, 0x56, 0xd3, 0x56, 0xd4, 0x44, 0xd1, 0xb4,
-	0x6a, 0x45, 0x61, 0xf9, 0x9b, 0x40, 0x93, 0x77, 0x3a, 0xdc, 0x39, 0xf9, 0x23, 0x93, 0x7b, 0xf7,
-	0x2f, 0x00, 0x00, 0xff, 0xff, 0xb9, 0x8e, 0xe8, 0xbe, 0xb3, 0x02, 0x00, 0x00,
+	// 491 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x52, 0x5b, 0x6f, 0xd3, 0x30,
+	0x14, 0x56, 0xda, 0x86, 0xd1, 0xd3, 0x69, 0x45, 0x16, 0xb4, 0x51, 0x80, 0xaa, 0xe4, 0xa9, 0x42,
+	0x22, 0x43, 0x1d, 0xe2, 0x05, 0x1e, 0xd0, 0xc6, 0x45, 0x95, 0x78, 0xa8, 0x32, 0xc6, 0xbb, 0xd3,
+	0x9c, 0x76, 0x16, 0x49, 0x6c, 0x6c, 0xa7, 0x68, 0xfc, 0x1a, 0x7e, 0x2a, 0x8a, 0xed, 0x74, 0xbd,
+	0xed, 0xc9, 0xfe, 0xce, 0xed, 0x3b, 0xe7, 0x7c, 0x07, 0xa0, 0x52, 0x28, 0x63, 0x21, 0xb9, 0xe6,
+	0xc4, 0x37, 0x4f, 0x38, 0x5a, 0x71, 0xbe, 0xca, 0xf1, 0xdc, 0xa0, 0xb4, 0x5a, 0x9e, 0xff, 0x91,
+	0x54, 0x08, 0x94, 0xca, 0x86, 0x45, 0x5f, 0x61, 0xf0, 0x99, 0x29, 0x41, 0xf5, 0xe2, 0xf6, 0x8a,
+	0x17, 0x05, 0x2d, 0xb3, 0x04, 0x7f, 0x57, 0xa8, 0x34, 0x21, 0xd0, 0x29, 0x69, 0x81, 0x81, 0x37,
+	0xf6, 0x26, 0xdd, 0xc4, 0xfc, 0x49, 0x00, 0x27, 0x82, 0xde, 0xe5, 0x9c, 0x66, 0x41, 0x6b, 0xec,
+	0x4d, 0x4e, 0x93, 0x06, 0x46, 0x57, 0x30, 0x3c, 0xa8, 0xa3, 0x04, 0x2f, 0x15, 0x92, 0x33, 0x68,
+	0xb1, 0xcc, 0x95, 0x69, 0xb1, 0xac, 0x2e, 0xb2, 0x46, 0xa9, 0x18, 0x2f, 0x4d, 0x11, 0x3f, 0x69,
+	0x60, 0x74, 0x0b, 0x9d, 0x1b, 0x85, 0xf2, 0x20, 0xe3, 0x29, 0xf8, 0x58, 0x50, 0x96, 0x9b, 0xf8,
+	0x6e, 0x62, 0x01, 0x19, 0x01, 0x2c, 0xe9, 0x02, 0x53, 0xce, 0x7f, 0xcd, 0xb2, 0xa0, 0x6d, 0x5c,
+	0x5b, 0x16, 0x12, 0xc2, 0x63, 0x3b, 0xfc, 0x2c, 0x0b, 0x3a, 0xc6, 0xbb, 0xc1, 0xd1, 0x18, 0xce,
+	0xbe, 0xa1, 0xae, 0xc9, 0x9a, 0x71, 0xf7, 0x38, 0xa3, 0x0f, 0xd0, 0xff, 0xce, 0xd4, 0x4e, 0x08,
+	0x81, 0x8e, 0xa0, 0x2b, 0xbb, 0x11, 0x3f, 0x31, 0xff, 0xba, 0xb5, 0x9c, 0x15, 0x4c, 0xbb, 0x51,
+	0x2c, 0x88, 0x2a, 0x78, 0x72, 0x9f, 0xec, 0xd6, 0xf0, 0x0a, 0xfc, 0x5a, 0x1e, 0x15, 0x78, 0xe3,
+	0xf6, 0xa4, 0x37, 0xed, 0x59, 0x01, 0x62, 0x13, 0x63, 0x3d, 0x1b, 0x82, 0xd6, 0x31, 0x82, 0xf6,
+	0x16, 0x41, 0x6d, 0xd5, 0x5c, 0xd3, 0xdc, 0x0c, 0xe6, 0x27, 0x16, 0x44, 0x7f, 0x61, 0x70, 0x5d,
+	0xa5, 0x6a, 0x21, 0x59, 0x8a, 0x5f, 0xd6, 0x58, 0x6a, 0xd5, 0xb4, 0x3e, 0x02, 0xc0, 0xda, 0xf0,
+	0xe3, 0x4e, 0xa0, 0xed, 0xa0, 0x9b, 0x6c, 0x59, 0xc8, 0x27, 0x38, 0x5d, 0x4a, 0x5e, 0xcc, 0xb9,
+	0x62, 0xba, 0x11, 0xa6, 0x37, 0x7d, 0x11, 0xdb, 0x85, 0xc5, 0xcd, 0xf5, 0xc4, 0x37, 0xb3, 0x52,
+	0xbf, 0x7f, 0xf7, 0x93, 0xe6, 0x15, 0x26, 0x3b, 0x19, 0xd1, 0x05, 0x0c, 0x0f, 0xb8, 0xdd, 0xe4,
+	0x5b, 0x57, 0xe3, 0xed, 0x5c, 0xcd, 0xf4, 0x5f, 0x0b, 0x7a, 0xf5, 0x02, 0xae, 0x51, 0xae, 0xd9,
+	0x02, 0xc9, 0x1c, 0xfa, 0x7b, 0x57, 0x44, 0x5e, 0xba, 0x3d, 0x1d, 0xbf, 0xd2, 0x70, 0xf4, 0x90,
+	0xdb, 0x71, 0xbf, 0x81, 0x13, 0x27, 0x34, 0x79, 0xe6, 0x42, 0x77, 0x85, 0x0f, 0xb7, 0x85, 0x20,
+	0x1f, 0xa1, 0xdb, 0x08, 0xa7, 0xc8, 0xc0, 0x79, 0xf6, 0xee, 0x20, 0x1c, 0x1e, 0xd8, 0x1d, 0x59,
+	0x02, 0xfd, 0xbd, 0x1d, 0x6c, 0xda, 0x3f, 0xae, 0x4b, 0x38, 0x7a, 0xc8, 0x6d, 0x2b, 0xbe, 0xf5,
+	0x2e, 0x5f, 0xc3, 0xf3, 0x15, 0xa7, 0x82, 0xa5, 0x9c, 0xe5, 0x28, 0x45, 0x4e, 0x35, 0xc6, 0x2b,
+	0x29, 0x16, 0x36, 0xf1, 0xb2, 0x5b, 0x37, 0x30, 0xaf, 0xbf, 0x73, 0x2f, 0x7d, 0x64, 0x6c, 0x17,
+	0xff, 0x07, 0x00, 0x96, 0x18, 0xdc, 0xac, 0x08, 0x04, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -341,9 +489,10 @@ const _ = grpc.SupportPackageIsVersion6
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type UserServiceClient interface {
-	DispatchCommand(ctx context.Context, in *DispatchCommandRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	DispatchCommand(ctx context.Context, in *DispatchCommandRequest, opts ...grpc.CallOption) (*DispatchCommandResponse, error)
 	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
 	ListUsers(ctx context.Context, in *ListUserRequest, opts ...grpc.CallOption) (*ListUserResponse, error)
+	SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (UserService_SubscribeEventsClient, error)
 }
 
 type userServiceClient struct {
@@ -354,8 +503,8 @@ func NewUserServiceClient(cc grpc.ClientConnInterface) UserServiceClient {
 	return &userServiceClient{cc}
 }
 
-func (c *userServiceClient) DispatchCommand(ctx context.Context, in *DispatchCommandRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
+func (c *userServiceClient) DispatchCommand(ctx context.Context, in *DispatchCommandRequest, opts ...grpc.CallOption) (*DispatchCommandResponse, error) {
+	out := new(DispatchCommandResponse)
 	err := c.cc.Invoke(ctx, "/proto.UserService/DispatchCommand", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -381,18 +530,51 @@ func (c *userServiceClient) ListUsers(ctx context.Context, in *ListUserRequest,
 	return out, nil
 }
 
+func (c *userServiceClient) SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (UserService_SubscribeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_UserService_serviceDesc.Streams[0], "/proto.UserService/SubscribeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceSubscribeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserService_SubscribeEventsClient interface {
+	Recv() (*SubscribeEventsResponse, error)
+	grpc.ClientStream
+}
+
+type userServiceSubscribeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceSubscribeEventsClient) Recv() (*SubscribeEventsResponse, error) {
+	m := new(SubscribeEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // UserServiceServer is the server API for UserService service.
 type UserServiceServer interface {
-	DispatchCommand(context.Context, *DispatchCommandRequest) (*empty.Empty, error)
+	DispatchCommand(context.Context, *DispatchCommandRequest) (*DispatchCommandResponse, error)
 	GetUser(context.Context, *GetUserRequest) (*User, error)
 	ListUsers(context.Context, *ListUserRequest) (*ListUserResponse, error)
+	SubscribeEvents(*SubscribeEventsRequest, UserService_SubscribeEventsServer) error
 }
 
 // UnimplementedUserServiceServer can be embedded to have forward compatible implementations.
 type UnimplementedUserServiceServer struct {
 }
 
-func (*UnimplementedUserServiceServer) DispatchCommand(ctx context.Context, req *DispatchCommandRequest) (*empty.Empty, error) {
+func (*UnimplementedUserServiceServer) DispatchCommand(ctx context.Context, req *DispatchCommandRequest) (*DispatchCommandResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DispatchCommand not implemented")
 }
 func (*UnimplementedUserServiceServer) GetUser(ctx context.Context, req *GetUserRequest) (*User, error) {
@@ -401,6 +583,9 @@ func (*UnimplementedUserServiceServer) GetUser(ctx context.Context, req *GetUser
 func (*UnimplementedUserServiceServer) ListUsers(ctx context.Context, req *ListUserRequest) (*ListUserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
 }
+func (*UnimplementedUserServiceServer) SubscribeEvents(req *SubscribeEventsRequest, srv UserService_SubscribeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
 
 func RegisterUserServiceServer(s *grpc.Server, srv UserServiceServer) {
 	s.RegisterService(&_UserService_serviceDesc, srv)
@@ -460,6 +645,27 @@ func _UserService_ListUsers_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServiceServer).SubscribeEvents(m, &userServiceSubscribeEventsServer{stream})
+}
+
+type UserService_SubscribeEventsServer interface {
+	Send(*SubscribeEventsResponse) error
+	grpc.ServerStream
+}
+
+type userServiceSubscribeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceSubscribeEventsServer) Send(m *SubscribeEventsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _UserService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.UserService",
 	HandlerType: (*UserServiceServer)(nil),
@@ -477,6 +683,12 @@ var _UserService_serviceDesc = grpc.ServiceDesc{
 			Handler:    _UserService_ListUsers_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _UserService_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "user.proto",
 }
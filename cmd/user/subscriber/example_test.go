@@ -0,0 +1,35 @@
+package subscriber_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/subscriber"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	grpcutils "github.com/vardius/go-api-boilerplate/pkg/grpc"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+func ExampleRegisterGRPCHandlers() {
+	ctx := context.Background()
+	logger := log.New("development")
+
+	conn := grpcutils.NewConnection(ctx, "user", 3001, grpcutils.ConnectionConfig{
+		ConnTime:    10 * time.Second,
+		ConnTimeout: 20 * time.Second,
+	}, logger)
+	defer conn.Close()
+
+	// position of the last event handled before restart, e.g. loaded from a checkpoint store
+	var position uint64
+
+	subscriber.RegisterGRPCHandlers("user", conn, map[string]eventbus.EventHandler{
+		"user.WasRegisteredWithEmail": func(ctx context.Context, event domain.Event) error {
+			fmt.Printf("%s registered at position %d\n", event.Metadata.StreamID, event.Position)
+
+			return nil
+		},
+	}, &position, 5*time.Second)
+}
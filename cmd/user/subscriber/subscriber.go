@@ -0,0 +1,141 @@
+/*
+Package subscriber provides client of user events streamed by user service
+*/
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/vardius/gollback"
+	"google.golang.org/grpc"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/proto"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	grpcutils "github.com/vardius/go-api-boilerplate/pkg/grpc"
+)
+
+// resubscribeInterval is a pause before subscribing again, so failing handler does not spin
+const resubscribeInterval = time.Second
+
+// Subscriber handles user events of given types streamed by user service SubscribeEvents,
+// position of the last handled event is kept so next subscription resumes after it
+type Subscriber struct {
+	client     proto.UserServiceClient
+	eventTypes []string
+	handler    eventbus.EventHandler
+
+	mtx      sync.Mutex
+	position *uint64
+}
+
+// New provides new user events subscriber, events stored after fromPosition are handled before live ones,
+// without it subscriber starts with live events and resumes from event store once it handled any
+func New(conn grpc.ClientConnInterface, eventTypes []string, fromPosition *uint64, handler eventbus.EventHandler) *Subscriber {
+	s := &Subscriber{
+		client:     proto.NewUserServiceClient(conn),
+		eventTypes: eventTypes,
+		handler:    handler,
+	}
+
+	if fromPosition != nil {
+		position := *fromPosition
+		s.position = &position
+	}
+
+	return s
+}
+
+// Position returns global position of the last handled event, false if subscriber has none to resume from
+func (s *Subscriber) Position() (uint64, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.position == nil {
+		return 0, false
+	}
+
+	return *s.position, true
+}
+
+// Subscribe handles streamed events until stream ends or handler fails,
+// stream waits for connection to be ready, failed event is delivered again by the next subscription
+func (s *Subscriber) Subscribe(ctx context.Context) error {
+	r := &proto.SubscribeEventsRequest{
+		EventTypes: s.eventTypes,
+	}
+	if position, ok := s.Position(); ok {
+		r.FromPosition = &wrappers.UInt64Value{Value: position}
+	}
+
+	stream, err := s.client.SubscribeEvents(ctx, r, grpc.WaitForReady(true))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+
+		var event domain.Event
+		if err := json.Unmarshal(resp.GetPayload(), &event); err != nil {
+			return errors.Wrap(err)
+		}
+
+		if err := s.handler(domain.ContextWithCause(ctx, event), event); err != nil {
+			return errors.Wrap(fmt.Errorf("%s at position %d: %w", event.Metadata.Type, event.Position, err))
+		}
+
+		s.mtx.Lock()
+		s.position = &event.Position
+		s.mtx.Unlock()
+	}
+}
+
+// RegisterGRPCHandlers subscribes event handlers to user events of their topics,
+// each handler is resubscribed infinitely, resuming after the last event it handled
+func RegisterGRPCHandlers(serviceName string, conn *grpc.ClientConn, topicToHandlerMap map[string]eventbus.EventHandler, fromPosition *uint64, timeout time.Duration) {
+	for topic := range topicToHandlerMap {
+		if err := eventbus.ValidateTopic(topic); err != nil {
+			panic(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Will retry infinitely until timeouts by context
+	_, err := gollback.Retry(ctx, 0, func(ctx context.Context) (interface{}, error) {
+		if !grpcutils.IsConnectionServing(ctx, serviceName, conn) {
+			return nil, fmt.Errorf(" %s gRPC connection is not serving", serviceName)
+		}
+
+		for topic, handler := range topicToHandlerMap {
+			// Will resubscribe to handler on error infinitely
+			go func(topic string, handler eventbus.EventHandler) {
+				s := New(conn, []string{topic}, fromPosition, handler)
+
+				_, _ = gollback.Retry(context.Background(), 0, func(ctx context.Context) (interface{}, error) {
+					err := s.Subscribe(ctx)
+					time.Sleep(resubscribeInterval)
+
+					return nil, fmt.Errorf("EventHandler %s unsubscribed (%v)", topic, err)
+				})
+			}(topic, handler)
+		}
+
+		return nil, nil
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
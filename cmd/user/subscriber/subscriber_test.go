@@ -0,0 +1,196 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/user"
+	"github.com/vardius/go-api-boilerplate/cmd/user/internal/domain/webhook"
+	usergrpc "github.com/vardius/go-api-boilerplate/cmd/user/internal/interfaces/grpc"
+	"github.com/vardius/go-api-boilerplate/cmd/user/proto"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	memoryeventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/identity"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+func TestSubscriber(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := memoryeventstore.New()
+	bus := memoryeventbus.New(10, log.New("development"))
+	conn := dial(t, store, bus)
+
+	userID := uuid.New()
+	appendEvent := func(streamName string, version int, rawEvent domain.RawEvent) domain.Event {
+		e, err := domain.NewEvent(userID, streamName, version, rawEvent, &identity.Identity{Token: "token"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		events := []domain.Event{e}
+		if err := store.Store(ctx, events); err != nil {
+			t.Fatal(err)
+		}
+		if err := bus.Publish(ctx, events[0]); err != nil {
+			t.Fatal(err)
+		}
+		return events[0]
+	}
+
+	registered := appendEvent(user.StreamName, 0, user.WasRegisteredWithEmail{ID: userID, Email: "test@example.com"})
+	appendEvent(webhook.StreamName, 0, webhook.WasCreated{ID: userID, URL: "https://example.com", Secret: "secret"})
+
+	received := make(chan domain.Event, 10)
+	errHandler := errors.New("handler failed")
+	failOnce := map[uuid.UUID]bool{}
+	handler := func(ctx context.Context, event domain.Event) error {
+		if failOnce[event.ID] {
+			delete(failOnce, event.ID)
+			return errHandler
+		}
+		received <- event
+		return nil
+	}
+
+	var from uint64
+	s := New(conn, nil, &from, handler)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Subscribe(ctx) }()
+
+	expect := func(want domain.Event) {
+		t.Helper()
+		select {
+		case got := <-received:
+			if got.ID != want.ID || got.Position != want.Position {
+				t.Fatalf("received %s at %d, expected %s at %d", got.Metadata.Type, got.Position, want.Metadata.Type, want.Position)
+			}
+			if got.Identity != nil {
+				t.Errorf("identity of event author was streamed: %+v", got.Identity)
+			}
+		case <-ctx.Done():
+			t.Fatalf("%s was not received", want.Metadata.Type)
+		}
+	}
+
+	expect(registered)
+
+	changed := appendEvent(user.StreamName, 1, user.EmailAddressWasChanged{ID: userID, Email: "new@example.com"})
+	expect(changed)
+
+	forgotten, err := domain.NewEvent(userID, user.StreamName, 2, user.WasForgotten{ID: userID}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failOnce[forgotten.ID] = true
+	events := []domain.Event{forgotten}
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(ctx, events[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; !errors.Is(err, errHandler) {
+		t.Fatalf("Subscribe returned %v, expected handler error", err)
+	}
+	if position, ok := s.Position(); !ok || position != changed.Position {
+		t.Fatalf("position %d (%v), expected %d", position, ok, changed.Position)
+	}
+
+	// resubscribed from the last handled event
+	go func() { done <- s.Subscribe(ctx) }()
+	expect(events[0])
+
+	cancel()
+	<-done
+}
+
+func TestSubscriberEventTypes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := memoryeventstore.New()
+	bus := memoryeventbus.New(10, log.New("development"))
+	conn := dial(t, store, bus)
+
+	userID := uuid.New()
+	var events []domain.Event
+	for i, rawEvent := range []domain.RawEvent{
+		user.WasRegisteredWithEmail{ID: userID, Email: "test@example.com"},
+		user.EmailAddressWasChanged{ID: userID, Email: "new@example.com"},
+		user.WasForgotten{ID: userID},
+	} {
+		e, err := domain.NewEvent(userID, user.StreamName, i, rawEvent, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan domain.Event, 10)
+	s := New(conn, []string{events[1].Metadata.Type, events[2].Metadata.Type}, new(uint64), func(ctx context.Context, event domain.Event) error {
+		received <- event
+		return nil
+	})
+	go func() { _ = s.Subscribe(ctx) }()
+
+	for _, want := range events[1:] {
+		select {
+		case got := <-received:
+			if got.ID != want.ID {
+				t.Fatalf("received %s, expected %s", got.Metadata.Type, want.Metadata.Type)
+			}
+		case <-ctx.Done():
+			t.Fatalf("%s was not received", want.Metadata.Type)
+		}
+	}
+}
+
+func TestSubscriberInvalidEventType(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn := dial(t, memoryeventstore.New(), memoryeventbus.New(10, log.New("development")))
+
+	s := New(conn, []string{"user.*.*"}, nil, func(ctx context.Context, event domain.Event) error {
+		return nil
+	})
+	if err := s.Subscribe(ctx); err == nil {
+		t.Error("expected invalid event type to be rejected")
+	}
+}
+
+func dial(t *testing.T, store eventstore.EventStore, bus eventbus.EventBus) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	proto.RegisterUserServiceServer(server, usergrpc.NewServer(nil, nil, store, bus))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
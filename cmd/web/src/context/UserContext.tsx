@@ -2,6 +2,14 @@ import React, {createContext, ReactChild, useCallback, useEffect,} from "react";
 import {User} from "src/types";
 import {UnauthorizedHttpError} from "src/errors";
 import {useApi, useAuthToken} from "src/hooks";
+import {API_URL} from "src/constants";
+
+// events changing user details returned by /me
+const USER_CHANGED_EVENTS = [
+  "user.EmailAddressWasChanged",
+  "user.ConnectedWithGoogle",
+  "user.ConnectedWithFacebook",
+];
 
 type user = User | null;
 
@@ -49,6 +57,30 @@ const UserContextProvider = (props: Props) => {
     }
   }, [authToken, fetchMe]);
 
+  useEffect(() => {
+    if (!authToken) {
+      return;
+    }
+
+    // EventSource can not send headers, token is passed as query parameter
+    const url = new URL(`/users/v1/me/events`, API_URL);
+    url.searchParams.set("authToken", authToken);
+
+    // reconnects on its own and resumes after the last received event
+    const source = new EventSource(url.toString());
+    const reload = async () => {
+      try {
+        setUser(await fetchMe());
+      } catch (err) {
+        setUser(null);
+      }
+    };
+
+    USER_CHANGED_EVENTS.forEach((type) => source.addEventListener(type, reload));
+
+    return () => source.close();
+  }, [authToken, fetchMe]);
+
   return (
     <UserContext.Provider value={[user, setUser]}>
       {props.children}
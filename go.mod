@@ -11,6 +11,7 @@ require (
 	github.com/golang/protobuf v1.4.2
 	github.com/google/uuid v1.1.1
 	github.com/grpc-ecosystem/go-grpc-middleware v1.2.0
+	github.com/lib/pq v1.8.0
 	github.com/rs/cors v1.7.0
 	github.com/vardius/gocontainer v1.0.3
 	github.com/vardius/gollback v1.1.0
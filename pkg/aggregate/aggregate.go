@@ -0,0 +1,111 @@
+package aggregate
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+// Root is an event sourced aggregate root
+type Root interface {
+	// ID returns aggregate root id
+	ID() uuid.UUID
+	// StreamName returns name of the stream aggregate root events belong to
+	StreamName() string
+	// Version returns number of events applied to aggregate root
+	Version() int
+	// Changes returns all new applied events
+	Changes() []domain.Event
+	// Replay applies stored events in order
+	Replay(events []domain.Event) error
+}
+
+// Transition changes aggregate root state by applying raw event
+type Transition func(e domain.RawEvent)
+
+// Base keeps aggregate root id, version and changes,
+// aggregate roots embed it and provide their own Transition
+type Base struct {
+	id         uuid.UUID
+	streamName string
+	version    int
+	changes    []domain.Event
+	registry   *domain.EventRegistry
+}
+
+// NewBase creates aggregate root base for given stream,
+// registry is used to decode stored events when replaying them
+func NewBase(streamName string, registry *domain.EventRegistry) Base {
+	return Base{
+		streamName: streamName,
+		registry:   registry,
+	}
+}
+
+// ID returns aggregate root id
+func (b Base) ID() uuid.UUID {
+	return b.id
+}
+
+// StreamName returns name of the stream aggregate root events belong to
+func (b Base) StreamName() string {
+	return b.streamName
+}
+
+// Version returns current aggregate root version
+func (b Base) Version() int {
+	return b.version
+}
+
+// Changes returns all new applied events
+func (b Base) Changes() []domain.Event {
+	return b.changes
+}
+
+// SetID sets aggregate root id, transitions call it when applying event creating aggregate root
+func (b *Base) SetID(id uuid.UUID) {
+	b.id = id
+}
+
+// Restore sets aggregate root id and version, used when restoring aggregate root from snapshot
+func (b *Base) Restore(id uuid.UUID, version int) {
+	b.id = id
+	b.version = version
+}
+
+// TrackChange applies raw event with transition and appends it to aggregate root changes
+func (b *Base) TrackChange(ctx context.Context, transition Transition, e domain.RawEvent) (domain.Event, error) {
+	transition(e)
+
+	event, err := domain.NewEventFromContext(ctx, b.id, b.streamName, b.version, e)
+	if err != nil {
+		return event, errors.Wrap(err)
+	}
+
+	b.changes = append(b.changes, event)
+	b.version++
+
+	return event, nil
+}
+
+// Replay decodes stored events and applies them with transition in order
+func (b *Base) Replay(transition Transition, events []domain.Event) error {
+	if b.registry == nil {
+		return errors.New("aggregate root base was created without event registry")
+	}
+
+	for _, domainEvent := range events {
+		e, err := b.registry.Decode(domainEvent)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+
+		transition(e)
+		b.version++
+	}
+
+	return nil
+}
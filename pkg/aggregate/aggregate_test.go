@@ -0,0 +1,144 @@
+package aggregate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+)
+
+const accountStreamName = "account"
+
+var accountEvents = newAccountEventRegistry()
+
+func newAccountEventRegistry() *domain.EventRegistry {
+	registry := domain.NewEventRegistry()
+	registry.MustRegister(wasOpened{}, wasDeposited{})
+
+	return registry
+}
+
+type wasOpened struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func (e wasOpened) GetType() string {
+	return "account.WasOpened"
+}
+
+type wasDeposited struct {
+	Amount int `json:"amount"`
+}
+
+func (e wasDeposited) GetType() string {
+	return "account.WasDeposited"
+}
+
+type account struct {
+	aggregate.Base
+
+	balance int
+}
+
+func newAccount() account {
+	return account{Base: aggregate.NewBase(accountStreamName, accountEvents)}
+}
+
+func (a *account) Replay(events []domain.Event) error {
+	return a.Base.Replay(a.transition, events)
+}
+
+func (a *account) Open(ctx context.Context, id uuid.UUID) error {
+	_, err := a.TrackChange(ctx, a.transition, wasOpened{ID: id})
+	return err
+}
+
+func (a *account) Deposit(ctx context.Context, amount int) error {
+	_, err := a.TrackChange(ctx, a.transition, wasDeposited{Amount: amount})
+	return err
+}
+
+func (a account) MarshalSnapshot() (json.RawMessage, error) {
+	return json.Marshal(map[string]interface{}{"id": a.ID(), "balance": a.balance})
+}
+
+func (a *account) UnmarshalSnapshot(version int, payload json.RawMessage) error {
+	var s struct {
+		ID      uuid.UUID `json:"id"`
+		Balance int       `json:"balance"`
+	}
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return err
+	}
+
+	a.Restore(s.ID, version)
+	a.balance = s.Balance
+
+	return nil
+}
+
+func (a *account) transition(e domain.RawEvent) {
+	switch e := e.(type) {
+	case wasOpened:
+		a.SetID(e.ID)
+	case wasDeposited:
+		a.balance += e.Amount
+	}
+}
+
+func TestBaseTrackChange(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	a := newAccount()
+	if err := a.Open(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Deposit(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if a.ID() != id {
+		t.Errorf("ID() = %s, want %s", a.ID(), id)
+	}
+	if a.Version() != 2 || len(a.Changes()) != 2 {
+		t.Fatalf("expected version 2 with 2 changes, got %d with %d", a.Version(), len(a.Changes()))
+	}
+
+	for i, e := range a.Changes() {
+		if e.Metadata.StreamID != id || e.Metadata.StreamName != accountStreamName || e.Metadata.StreamVersion != i {
+			t.Errorf("unexpected metadata of change %d: %v", i, e.Metadata)
+		}
+	}
+
+	replayed := newAccount()
+	if err := replayed.Replay(a.Changes()); err != nil {
+		t.Fatal(err)
+	}
+	if replayed.ID() != id || replayed.Version() != 2 || replayed.balance != 10 {
+		t.Errorf("unexpected replayed state %v", replayed)
+	}
+	if len(replayed.Changes()) != 0 {
+		t.Errorf("replay should not track changes, got %d", len(replayed.Changes()))
+	}
+}
+
+func TestBaseReplayUnknownEvent(t *testing.T) {
+	a := newAccount()
+	if err := a.Open(context.Background(), uuid.New()); err != nil {
+		t.Fatal(err)
+	}
+
+	events := a.Changes()
+	events[0].Metadata.Type = "account.Unknown"
+
+	replayed := newAccount()
+	if err := replayed.Replay(events); !errors.Is(err, domain.ErrUnknownEventType) {
+		t.Errorf("expected unknown event type error, got %v", err)
+	}
+}
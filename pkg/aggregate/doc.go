@@ -0,0 +1,4 @@
+/*
+Package aggregate provides event sourced aggregate root contract along with generic repository
+*/
+package aggregate
@@ -0,0 +1,176 @@
+package aggregate
+
+import (
+	"context"
+	systemErrors "errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
+)
+
+// Repository loads and saves any event sourced aggregate root,
+// saved changes are published with an event bus
+// Save returns eventstore.ErrConcurrencyConflict if the stream was modified
+// since aggregate root has been loaded, caller can reload it and retry the command
+type Repository struct {
+	eventStore     eventstore.EventStore
+	eventBus       eventbus.EventBus
+	snapshotStore  snapshotstore.SnapshotStore
+	snapshotPolicy snapshotstore.Policy
+	logger         *log.Logger
+}
+
+// NewRepository creates new event sourced repository
+// snapshot of aggregate root is taken after save whenever given policy allows it,
+// snapshot store and policy are optional, logger reports snapshots that could not be taken
+func NewRepository(store eventstore.EventStore, bus eventbus.EventBus, snapshotStore snapshotstore.SnapshotStore, snapshotPolicy snapshotstore.Policy, logger *log.Logger) *Repository {
+	return &Repository{
+		eventStore:     store,
+		eventBus:       bus,
+		snapshotStore:  snapshotStore,
+		snapshotPolicy: snapshotPolicy,
+		logger:         logger,
+	}
+}
+
+// Save aggregate root changes to event store and publish each event with an event bus
+func (r *Repository) Save(ctx context.Context, root Root) error {
+	if err := r.eventStore.Store(ctx, root.Changes()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	r.takeSnapshot(ctx, root)
+
+	for _, event := range root.Changes() {
+		if err := r.eventBus.Publish(ctx, event); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+// SaveAndAcknowledge saves aggregate root changes to event store and publish each event with an event bus
+// blocks until event handlers are finished
+func (r *Repository) SaveAndAcknowledge(ctx context.Context, root Root) error {
+	if err := r.eventStore.Store(ctx, root.Changes()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	r.takeSnapshot(ctx, root)
+
+	for _, event := range root.Changes() {
+		if err := r.eventBus.PublishAndAcknowledge(ctx, event); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+// Load applies current state of aggregate root with given id to empty root,
+// latest snapshot is used when root implements snapshotstore.Snapshotter
+func (r *Repository) Load(ctx context.Context, id uuid.UUID, root Root) error {
+	if snapshotter, ok := root.(snapshotstore.Snapshotter); ok && r.snapshotStore != nil {
+		snapshot, err := r.snapshotStore.Get(ctx, id, root.StreamName())
+		switch {
+		case err == nil:
+			if err := snapshotter.UnmarshalSnapshot(snapshot.StreamVersion, snapshot.Payload); err != nil {
+				return errors.Wrap(err)
+			}
+
+			events, err := r.eventStore.GetStreamFromVersion(ctx, id, root.StreamName(), snapshot.StreamVersion)
+			if err != nil {
+				return errors.Wrap(err)
+			}
+
+			if err := root.Replay(events); err != nil {
+				return errors.Wrap(err)
+			}
+
+			return nil
+		case !systemErrors.Is(err, snapshotstore.ErrSnapshotNotFound):
+			return errors.Wrap(err)
+		}
+	}
+
+	events, err := r.eventStore.GetStream(ctx, id, root.StreamName())
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	return r.replay(root, events)
+}
+
+// LoadAtVersion applies state of aggregate root after given number of events to empty root,
+// snapshots are skipped because they hold only the latest state
+func (r *Repository) LoadAtVersion(ctx context.Context, id uuid.UUID, version int, root Root) error {
+	events, err := r.eventStore.GetStreamToVersion(ctx, id, root.StreamName(), version)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	return r.replay(root, events)
+}
+
+// LoadAsOf applies state of aggregate root from events that occurred at or before given time to empty root
+func (r *Repository) LoadAsOf(ctx context.Context, id uuid.UUID, at time.Time, root Root) error {
+	events, err := r.eventStore.GetStreamAsOf(ctx, id, root.StreamName(), at)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	return r.replay(root, events)
+}
+
+func (r *Repository) replay(root Root, events []domain.Event) error {
+	if len(events) == 0 {
+		return application.ErrNotFound
+	}
+
+	if err := root.Replay(events); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// takeSnapshot stores aggregate root state if saved changes crossed snapshot policy threshold,
+// snapshot is only an optimization so failure is logged instead of failing the save of already stored events
+func (r *Repository) takeSnapshot(ctx context.Context, root Root) {
+	if r.snapshotStore == nil || r.snapshotPolicy == nil {
+		return
+	}
+
+	snapshotter, ok := root.(snapshotstore.Snapshotter)
+	if !ok {
+		return
+	}
+	if !r.snapshotPolicy(root.Version()-len(root.Changes()), root.Version()) {
+		return
+	}
+
+	payload, err := snapshotter.MarshalSnapshot()
+	if err != nil {
+		r.logger.Error(ctx, "[Repository] could not marshal snapshot of %s %s at version %d: %v\n", root.StreamName(), root.ID(), root.Version(), err)
+		return
+	}
+
+	if err := r.snapshotStore.Store(ctx, snapshotstore.Snapshot{
+		StreamID:      root.ID(),
+		StreamName:    root.StreamName(),
+		StreamVersion: root.Version(),
+		TakenAt:       time.Now(),
+		Payload:       payload,
+	}); err != nil {
+		r.logger.Error(ctx, "[Repository] could not store snapshot of %s %s at version %d: %v\n", root.StreamName(), root.ID(), root.Version(), err)
+	}
+}
@@ -0,0 +1,132 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/aggregate"
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	memoryeventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
+	memorysnapshotstore "github.com/vardius/go-api-boilerplate/pkg/snapshotstore/memory"
+)
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	bus := memoryeventbus.New(1, log.New("development"))
+
+	var published []domain.Event
+	if err := bus.Subscribe(ctx, (wasDeposited{}).GetType(), func(ctx context.Context, event domain.Event) error {
+		published = append(published, event)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshotStore := memorysnapshotstore.New()
+	repository := aggregate.NewRepository(memoryeventstore.New(), bus, snapshotStore, snapshotstore.EveryNEvents(2), log.New("development"))
+
+	id := uuid.New()
+	a := newAccount()
+	if err := a.Open(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Deposit(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.SaveAndAcknowledge(ctx, &a); err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 1 {
+		t.Errorf("expected deposit to be published, got %d events", len(published))
+	}
+
+	snapshot, err := snapshotStore.Get(ctx, id, accountStreamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.StreamVersion != 2 {
+		t.Errorf("snapshot StreamVersion = %d, want 2", snapshot.StreamVersion)
+	}
+
+	loaded := newAccount()
+	if err := repository.Load(ctx, id, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Deposit(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.SaveAndAcknowledge(ctx, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	current := newAccount()
+	if err := repository.Load(ctx, id, &current); err != nil {
+		t.Fatal(err)
+	}
+	if current.ID() != id || current.Version() != 3 || current.balance != 15 {
+		t.Errorf("unexpected loaded state %v", current)
+	}
+
+	past := newAccount()
+	if err := repository.LoadAtVersion(ctx, id, 2, &past); err != nil {
+		t.Fatal(err)
+	}
+	if past.Version() != 2 || past.balance != 10 {
+		t.Errorf("unexpected state at version 2 %v", past)
+	}
+
+	asOf := newAccount()
+	if err := repository.LoadAsOf(ctx, id, time.Now(), &asOf); err != nil {
+		t.Fatal(err)
+	}
+	if asOf.Version() != 3 {
+		t.Errorf("unexpected state as of now %v", asOf)
+	}
+
+	missing := newAccount()
+	if err := repository.Load(ctx, uuid.New(), &missing); !errors.Is(err, application.ErrNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+type failingSnapshotStore struct{}
+
+func (failingSnapshotStore) Store(ctx context.Context, snapshot snapshotstore.Snapshot) error {
+	return errors.New("snapshot store is down")
+}
+
+func (failingSnapshotStore) Get(ctx context.Context, streamID uuid.UUID, streamName string) (snapshotstore.Snapshot, error) {
+	return snapshotstore.Snapshot{}, snapshotstore.ErrSnapshotNotFound
+}
+
+func TestRepositorySnapshotFailure(t *testing.T) {
+	ctx := context.Background()
+	repository := aggregate.NewRepository(memoryeventstore.New(), memoryeventbus.New(1, log.New("development")), failingSnapshotStore{}, snapshotstore.EveryNEvents(1), log.New("development"))
+
+	id := uuid.New()
+	a := newAccount()
+	if err := a.Open(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repository.Save(ctx, &a); err != nil {
+		t.Fatalf("snapshot failure should not fail save of stored events: %v", err)
+	}
+
+	loaded := newAccount()
+	if err := repository.Load(ctx, id, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Version() != 1 {
+		t.Errorf("loaded Version = %d, want 1", loaded.Version())
+	}
+}
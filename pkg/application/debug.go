@@ -24,6 +24,11 @@ func NewDebugAdapter(address string) *DebugAdapter {
 	}
 }
 
+// Handle registers debug handler for the given pattern
+func (adapter *DebugAdapter) Handle(pattern string, handler http.Handler) {
+	http.DefaultServeMux.Handle(pattern, handler)
+}
+
 // Start start http application adapter
 func (adapter *DebugAdapter) Start(ctx context.Context) error {
 	if err := adapter.ListenAndServe(); err != http.ErrServerClosed {
@@ -12,4 +12,5 @@ var (
 	ErrInternal          = errors.New("internal system error")
 	ErrTemporaryDisabled = errors.New("temporary disabled")
 	ErrTimeout           = errors.New("timeout")
+	ErrConflict          = errors.New("conflict")
 )
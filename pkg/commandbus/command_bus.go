@@ -3,17 +3,25 @@ package commandbus
 import (
 	"context"
 
+	"github.com/google/uuid"
+
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 )
 
+// Result of handled command, id and version of aggregate root command created or changed
+type Result struct {
+	ID      uuid.UUID `json:"id"`
+	Version int       `json:"version"`
+}
+
 // CommandHandler function
-type CommandHandler func(ctx context.Context, command domain.Command) error
+type CommandHandler func(ctx context.Context, command domain.Command) (Result, error)
 
 // CommandBus allows to subscribe/dispatch commands
 // Subscribing to the same command twice will unsubscribe previous handler
 // command handler should be one to one
 type CommandBus interface {
-	Publish(ctx context.Context, command domain.Command) error
+	Publish(ctx context.Context, command domain.Command) (Result, error)
 	Subscribe(ctx context.Context, commandName string, fn CommandHandler) error
 	Unsubscribe(ctx context.Context, commandName string) error
 }
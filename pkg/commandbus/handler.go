@@ -0,0 +1,48 @@
+package commandbus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	commandType = reflect.TypeOf((*domain.Command)(nil)).Elem()
+	resultType  = reflect.TypeOf(Result{})
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// NewHandler adapts typed handler to CommandHandler so command type is asserted in one place,
+// fn has to be func(ctx context.Context, c C) (Result, error) where C implements domain.Command,
+// commands of other types are rejected. Panics if fn is of other type
+func NewHandler(fn interface{}) CommandHandler {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func ||
+		t.NumIn() != 2 || t.In(0) != contextType || !t.In(1).Implements(commandType) ||
+		t.NumOut() != 2 || t.Out(0) != resultType || t.Out(1) != errorType {
+		panic(fmt.Sprintf("commandbus: handler has to be func(context.Context, domain.Command) (commandbus.Result, error), got %T", fn))
+	}
+
+	handled := t.In(1)
+
+	return func(ctx context.Context, command domain.Command) (Result, error) {
+		if command == nil || !reflect.TypeOf(command).AssignableTo(handled) {
+			return Result{}, errors.Wrap(fmt.Errorf("%w: handler of %s received invalid command %T", application.ErrInternal, handled, command))
+		}
+
+		out := v.Call([]reflect.Value{reflect.ValueOf(&ctx).Elem(), reflect.ValueOf(command)})
+
+		result := out[0].Interface().(Result)
+		if err, _ := out[1].Interface().(error); err != nil {
+			return result, err
+		}
+
+		return result, nil
+	}
+}
@@ -0,0 +1,77 @@
+package commandbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+)
+
+type commandMock struct {
+	ID uuid.UUID
+}
+
+func (c commandMock) GetName() string {
+	return "commandMock"
+}
+
+type otherCommandMock struct{}
+
+func (c otherCommandMock) GetName() string {
+	return "otherCommandMock"
+}
+
+func TestNewHandler(t *testing.T) {
+	handlerErr := errors.New("handler failed")
+
+	h := NewHandler(func(ctx context.Context, c commandMock) (Result, error) {
+		if c.ID == uuid.Nil {
+			return Result{}, handlerErr
+		}
+
+		return Result{ID: c.ID, Version: 1}, nil
+	})
+
+	id := uuid.New()
+	result, err := h(context.Background(), commandMock{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != id || result.Version != 1 {
+		t.Errorf("unexpected result %v", result)
+	}
+
+	if _, err := h(context.Background(), commandMock{}); err != handlerErr {
+		t.Errorf("expected handler error, got %v", err)
+	}
+
+	if _, err := h(context.Background(), otherCommandMock{}); !errors.Is(err, application.ErrInternal) {
+		t.Errorf("expected internal error for command of other type, got %v", err)
+	}
+	if _, err := h(context.Background(), nil); !errors.Is(err, application.ErrInternal) {
+		t.Errorf("expected internal error for nil command, got %v", err)
+	}
+}
+
+func TestNewHandlerPanicsForInvalidFunction(t *testing.T) {
+	for name, fn := range map[string]interface{}{
+		"not a function":   commandMock{},
+		"untyped command":  func(ctx context.Context, c interface{}) (Result, error) { return Result{}, nil },
+		"missing context":  func(c commandMock) (Result, error) { return Result{}, nil },
+		"missing result":   func(ctx context.Context, c commandMock) error { return nil },
+		"wrong error type": func(ctx context.Context, c commandMock) (Result, bool) { return Result{}, false },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+
+			NewHandler(fn)
+		})
+	}
+}
@@ -18,27 +18,33 @@ func New(maxConcurrentCalls int, logger *log.Logger) commandbus.CommandBus {
 	return &commandBus{messagebus.New(maxConcurrentCalls), logger}
 }
 
+// reply carries handler result back to publisher
+type reply struct {
+	result commandbus.Result
+	err    error
+}
+
 type commandBus struct {
 	messageBus messagebus.MessageBus
 	logger     *log.Logger
 }
 
-func (bus *commandBus) Publish(ctx context.Context, command domain.Command) error {
-	out := make(chan error, 1)
+func (bus *commandBus) Publish(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+	out := make(chan reply, 1)
 	defer close(out)
 
-	bus.logger.Debug(ctx, "[CommandBus] Publish: %s %+v\n", command.GetName(), command)
+	bus.logger.Debug(ctx, "[CommandBus] Publish: %s\n", command.GetName())
 	bus.messageBus.Publish(command.GetName(), ctx, command, out)
 
 	ctxDoneCh := ctx.Done()
 	select {
 	case <-ctxDoneCh:
-		return errors.Wrap(fmt.Errorf("%w: %s", application.ErrTimeout, ctx.Err()))
-	case err := <-out:
-		if err != nil {
-			return errors.Wrap(fmt.Errorf("create client failed: %w", err))
+		return commandbus.Result{}, errors.Wrap(fmt.Errorf("%w: %s", application.ErrTimeout, ctx.Err()))
+	case r := <-out:
+		if r.err != nil {
+			return r.result, errors.Wrap(fmt.Errorf("create client failed: %w", r.err))
 		}
-		return nil
+		return r.result, nil
 	}
 }
 
@@ -48,8 +54,9 @@ func (bus *commandBus) Subscribe(ctx context.Context, commandName string, fn com
 	// unsubscribe all other handlers
 	bus.messageBus.Close(commandName)
 
-	return bus.messageBus.Subscribe(commandName, func(ctx context.Context, command domain.Command, out chan<- error) {
-		out <- fn(ctx, command)
+	return bus.messageBus.Subscribe(commandName, func(ctx context.Context, command domain.Command, out chan<- reply) {
+		result, err := fn(ctx, command)
+		out <- reply{result, err}
 	})
 }
 
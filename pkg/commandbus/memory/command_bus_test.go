@@ -7,9 +7,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/metadata"
 )
 
 type commandMock struct{}
@@ -33,13 +37,45 @@ func TestSubscribePublish(t *testing.T) {
 
 	bus := New(runtime.NumCPU(), log.New("development"))
 
-	bus.Subscribe(ctx, "command", func(ctx context.Context, _ domain.Command) error {
-		return nil
+	want := commandbus.Result{ID: uuid.New(), Version: 1}
+
+	bus.Subscribe(ctx, "command", func(ctx context.Context, _ domain.Command) (commandbus.Result, error) {
+		return want, nil
 	})
 
-	bus.Publish(ctx, &commandMock{})
+	_, _ = bus.Publish(ctx, &commandMock{})
+
+	result, err := bus.Publish(ctx, &commandMock{})
+	if err != nil {
+		t.Error(err)
+	}
+	if result != want {
+		t.Errorf("expected result %+v, got %+v", want, result)
+	}
+}
+
+func TestPublishCarriesMetadata(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	m := metadata.New()
+	m.CausationID = "event-id"
+	ctx = metadata.ContextWithMetadata(ctx, m)
+
+	bus := New(runtime.NumCPU(), log.New("development"))
+
+	if err := bus.Subscribe(ctx, "command", func(ctx context.Context, _ domain.Command) (commandbus.Result, error) {
+		handlerMetadata, ok := metadata.FromContext(ctx)
+		if !ok || handlerMetadata.TraceID != m.TraceID || handlerMetadata.CausationID != m.CausationID {
+			return commandbus.Result{}, errors.New("command handler did not receive correlation and causation of the publisher")
+		}
+
+		return commandbus.Result{}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
 
-	if err := bus.Publish(ctx, &commandMock{}); err != nil {
+	if _, err := bus.Publish(ctx, &commandMock{}); err != nil {
 		t.Error(err)
 	}
 }
@@ -50,16 +86,16 @@ func TestUnsubscribe(t *testing.T) {
 
 	bus := New(runtime.NumCPU(), log.New("development"))
 
-	handler := func(ctx context.Context, _ domain.Command) error {
+	handler := func(ctx context.Context, _ domain.Command) (commandbus.Result, error) {
 		t.Fail()
 
-		return nil
+		return commandbus.Result{}, nil
 	}
 
 	bus.Subscribe(ctx, "command", handler)
 	bus.Unsubscribe(ctx, "command")
 
-	if err := bus.Publish(ctx, &commandMock{}); err != nil && !errors.Is(err, application.ErrTimeout) {
+	if _, err := bus.Publish(ctx, &commandMock{}); err != nil && !errors.Is(err, application.ErrTimeout) {
 		t.Error(err)
 	}
 }
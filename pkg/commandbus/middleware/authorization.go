@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/identity"
+)
+
+// RoleRestricted is a command only identities with required role can dispatch
+type RoleRestricted interface {
+	RequiredRole() identity.Role
+}
+
+// OwnerRestricted is a command only identity of its owner can dispatch, e.g. user changing own data
+type OwnerRestricted interface {
+	OwnerID() uuid.UUID
+}
+
+// Authorize checks identity from context against commands implementing RoleRestricted or OwnerRestricted,
+// other commands are handled without identity. Missing identity is unauthorized, insufficient one is forbidden
+func Authorize() Middleware {
+	m := func(next commandbus.CommandHandler) commandbus.CommandHandler {
+		fn := func(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+			if err := authorize(ctx, command); err != nil {
+				return commandbus.Result{}, errors.Wrap(err)
+			}
+
+			return next(ctx, command)
+		}
+
+		return fn
+	}
+
+	return m
+}
+
+func authorize(ctx context.Context, command domain.Command) error {
+	r, roleRestricted := command.(RoleRestricted)
+	o, ownerRestricted := command.(OwnerRestricted)
+	if !roleRestricted && !ownerRestricted {
+		return nil
+	}
+
+	i, ok := identity.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: %s requires identity", application.ErrUnauthorized, command.GetName())
+	}
+
+	if roleRestricted && !i.HasRole(r.RequiredRole()) {
+		return fmt.Errorf("%w: %s requires %s role", application.ErrForbidden, command.GetName(), r.RequiredRole())
+	}
+	if ownerRestricted && i.UserID != o.OwnerID() {
+		return fmt.Errorf("%w: %s can be dispatched only by its owner", application.ErrForbidden, command.GetName())
+	}
+
+	return nil
+}
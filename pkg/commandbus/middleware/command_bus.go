@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+// CommandBus wraps command bus applying middleware to every handler subscribed
+type CommandBus struct {
+	commandbus.CommandBus
+	middleware Middleware
+}
+
+// NewCommandBus wraps command bus, middleware is applied in the given order, the first one is the outermost
+func NewCommandBus(bus commandbus.CommandBus, middlewares ...Middleware) *CommandBus {
+	return &CommandBus{
+		CommandBus: bus,
+		middleware: Chain(middlewares...),
+	}
+}
+
+// Subscribe handler wrapped with middleware
+func (b *CommandBus) Subscribe(ctx context.Context, commandName string, fn commandbus.CommandHandler) error {
+	if err := b.CommandBus.Subscribe(ctx, commandName, b.middleware(fn)); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
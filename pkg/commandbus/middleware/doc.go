@@ -0,0 +1,4 @@
+/*
+Package middleware provides command handler middleware
+*/
+package middleware
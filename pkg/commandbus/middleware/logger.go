@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// Logger logs start, end and error of every handled command
+func Logger(logger *log.Logger) Middleware {
+	m := func(next commandbus.CommandHandler) commandbus.CommandHandler {
+		fn := func(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+			now := time.Now()
+
+			logger.Info(ctx, "[CommandHandler] Start: %s\n", command.GetName())
+
+			result, err := next(ctx, command)
+			if err != nil {
+				logger.Error(ctx, "[CommandHandler] Failed: %s (%s): %v\n", command.GetName(), time.Since(now), err)
+
+				return result, err
+			}
+
+			logger.Info(ctx, "[CommandHandler] End: %s -> %s (%s)\n", command.GetName(), result.ID, time.Since(now))
+
+			return result, nil
+		}
+
+		return fn
+	}
+
+	return m
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+)
+
+// m contains the global program counters for command handlers, keyed by command name.
+var m = struct {
+	handled  *expvar.Map
+	failed   *expvar.Map
+	duration *expvar.Map
+}{
+	handled:  expvar.NewMap("commandsHandled"),
+	failed:   expvar.NewMap("commandsFailed"),
+	duration: expvar.NewMap("commandsHandlingSeconds"),
+}
+
+// Metrics updates program counters of handled and failed commands and total time spent handling them
+func Metrics() Middleware {
+	mw := func(next commandbus.CommandHandler) commandbus.CommandHandler {
+		fn := func(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+			now := time.Now()
+
+			result, err := next(ctx, command)
+
+			m.duration.AddFloat(command.GetName(), time.Since(now).Seconds())
+			if err != nil {
+				m.failed.Add(command.GetName(), 1)
+			} else {
+				m.handled.Add(command.GetName(), 1)
+			}
+
+			return result, err
+		}
+
+		return fn
+	}
+
+	return mw
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+)
+
+// Middleware wraps command handler
+type Middleware func(next commandbus.CommandHandler) commandbus.CommandHandler
+
+// Chain composes middleware into one, the first middleware is the outermost
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next commandbus.CommandHandler) commandbus.CommandHandler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+
+		return next
+	}
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"context"
+	systemErrors "errors"
+	"expvar"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/identity"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+type commandMock struct {
+	valid bool
+}
+
+func (c commandMock) GetName() string {
+	return "command"
+}
+
+func (c commandMock) Validate() error {
+	if !c.valid {
+		return systemErrors.New("command is not valid")
+	}
+
+	return nil
+}
+
+type adminCommandMock struct{}
+
+func (c adminCommandMock) GetName() string {
+	return "admin-command"
+}
+
+func (c adminCommandMock) RequiredRole() identity.Role {
+	return identity.RoleAdmin
+}
+
+type ownedCommandMock struct {
+	ownerID uuid.UUID
+}
+
+func (c ownedCommandMock) GetName() string {
+	return "owned-command"
+}
+
+func (c ownedCommandMock) OwnerID() uuid.UUID {
+	return c.ownerID
+}
+
+func handled(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+	return commandbus.Result{}, nil
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next commandbus.CommandHandler) commandbus.CommandHandler {
+			return func(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+				calls = append(calls, name)
+				return next(ctx, command)
+			}
+		}
+	}
+
+	h := Chain(record("first"), record("second"))(func(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+		calls = append(calls, "handler")
+		return commandbus.Result{}, nil
+	})
+
+	if _, err := h(context.Background(), commandMock{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(calls, ","); got != "first,second,handler" {
+		t.Errorf("unexpected call order: %s", got)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	h := Recover(log.New("development"))(func(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+		panic("boom")
+	})
+
+	if _, err := h(context.Background(), commandMock{}); !systemErrors.Is(err, application.ErrInternal) {
+		t.Errorf("expected internal error, got: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	h := Validate()(handled)
+
+	if _, err := h(context.Background(), commandMock{valid: true}); err != nil {
+		t.Errorf("expected valid command to be handled, got: %v", err)
+	}
+	if _, err := h(context.Background(), commandMock{valid: false}); !systemErrors.Is(err, application.ErrInvalid) {
+		t.Errorf("expected invalid error, got: %v", err)
+	}
+	if _, err := h(context.Background(), adminCommandMock{}); err != nil {
+		t.Errorf("expected command without validation to be handled, got: %v", err)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	ownerID := uuid.New()
+	user := identity.New(ownerID, uuid.New(), "test@example.com", "", "")
+	admin := identity.New(uuid.New(), uuid.New(), "admin@example.com", "", "").WithRole(identity.RoleAdmin)
+
+	tests := []struct {
+		name     string
+		identity *identity.Identity
+		command  domain.Command
+		err      error
+	}{
+		{"unrestricted without identity", nil, commandMock{}, nil},
+		{"role without identity", nil, adminCommandMock{}, application.ErrUnauthorized},
+		{"role missing", &user, adminCommandMock{}, application.ErrForbidden},
+		{"role granted", &admin, adminCommandMock{}, nil},
+		{"owner without identity", nil, ownedCommandMock{ownerID: ownerID}, application.ErrUnauthorized},
+		{"other owner", &admin, ownedCommandMock{ownerID: ownerID}, application.ErrForbidden},
+		{"owner", &user, ownedCommandMock{ownerID: ownerID}, nil},
+	}
+
+	h := Authorize()(handled)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.identity != nil {
+				ctx = identity.ContextWithIdentity(ctx, *tt.identity)
+			}
+
+			_, err := h(ctx, tt.command)
+			if tt.err == nil && err != nil {
+				t.Errorf("expected command to be handled, got: %v", err)
+			}
+			if tt.err != nil && !systemErrors.Is(err, tt.err) {
+				t.Errorf("expected %v, got: %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	h := Metrics()(handled)
+
+	var before int64
+	if v, ok := m.handled.Get("command").(*expvar.Int); ok {
+		before = v.Value()
+	}
+
+	if _, err := h(context.Background(), commandMock{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := m.handled.Get("command").(*expvar.Int); !ok || v.Value() != before+1 {
+		t.Errorf("expected handled counter to be incremented, got: %v", m.handled.Get("command"))
+	}
+}
+
+func TestCommandBus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	bus := NewCommandBus(memory.New(runtime.NumCPU(), log.New("development")), Recover(log.New("development")), Validate())
+
+	if err := bus.Subscribe(ctx, "command", func(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+		panic("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bus.Publish(ctx, commandMock{valid: false}); !systemErrors.Is(err, application.ErrInvalid) {
+		t.Errorf("expected invalid error, got: %v", err)
+	}
+	if _, err := bus.Publish(ctx, commandMock{valid: true}); !systemErrors.Is(err, application.ErrInternal) {
+		t.Errorf("expected recovered panic error, got: %v", err)
+	}
+}
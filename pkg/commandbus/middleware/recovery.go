@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// Recover recovers from handler panic returning it as an internal error
+func Recover(logger *log.Logger) Middleware {
+	m := func(next commandbus.CommandHandler) commandbus.CommandHandler {
+		fn := func(ctx context.Context, command domain.Command) (result commandbus.Result, err error) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					logger.Critical(ctx, "[CommandHandler] Recovered in %v\n%s\n", rec, debug.Stack())
+
+					err = errors.Wrap(fmt.Errorf("%w: recovered from panic while handling %s: %v", application.ErrInternal, command.GetName(), rec))
+				}
+			}()
+
+			return next(ctx, command)
+		}
+
+		return fn
+	}
+
+	return m
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	systemErrors "errors"
+	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/commandbus"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+// Validator is a command which payload can be checked before it is handled
+type Validator interface {
+	Validate() error
+}
+
+// Validate rejects commands implementing Validator which payload is not valid,
+// validation error is returned as invalid one so it maps to bad request
+func Validate() Middleware {
+	m := func(next commandbus.CommandHandler) commandbus.CommandHandler {
+		fn := func(ctx context.Context, command domain.Command) (commandbus.Result, error) {
+			if v, ok := command.(Validator); ok {
+				if err := v.Validate(); err != nil {
+					if systemErrors.Is(err, application.ErrInvalid) {
+						return commandbus.Result{}, errors.Wrap(err)
+					}
+
+					return commandbus.Result{}, errors.Wrap(fmt.Errorf("%w: %s", application.ErrInvalid, err))
+				}
+			}
+
+			return next(ctx, command)
+		}
+
+		return fn
+	}
+
+	return m
+}
@@ -0,0 +1,13 @@
+package consumergroup
+
+import (
+	"context"
+)
+
+// CheckpointStore keeps global position of the last event handled by each consumer group
+type CheckpointStore interface {
+	// Get returns checkpoint of the group, ErrCheckpointNotFound if group has not saved any yet
+	Get(ctx context.Context, group string) (uint64, error)
+	// Save sets checkpoint of the group
+	Save(ctx context.Context, group string, position uint64) error
+}
@@ -0,0 +1,4 @@
+/*
+Package consumergroup provides named groups of event handlers resuming from persistent checkpoints
+*/
+package consumergroup
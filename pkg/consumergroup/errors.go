@@ -0,0 +1,10 @@
+package consumergroup
+
+import (
+	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+)
+
+// ErrCheckpointNotFound is when consumer group has not saved any checkpoint yet
+var ErrCheckpointNotFound = fmt.Errorf("%w: checkpoint", application.ErrNotFound)
@@ -0,0 +1,457 @@
+package consumergroup
+
+import (
+	"context"
+	systemErrors "errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// Config configures consumer group
+type Config struct {
+	// BatchSize is a number of events read from event store at once while catching up
+	BatchSize int
+	// PollInterval is a time to wait before checking event store for events bus did not deliver
+	PollInterval time.Duration
+	// StartAtEnd makes group without checkpoint start after the last stored event instead of the first one,
+	// for handlers that already handled past events before they were moved to the group
+	StartAtEnd bool
+	// GapTimeout is a time group keeps looking for positions missing between stored events,
+	// positions are missing when transaction storing events rolled back or was not committed yet
+	GapTimeout time.Duration
+}
+
+// Group is an app adapter handling events in order of their global position,
+// position of the last handled event is saved as group checkpoint so restarted group resumes where it stopped
+type Group struct {
+	name        string
+	store       eventstore.EventStore
+	bus         eventbus.EventBus
+	checkpoints CheckpointStore
+	logger      *log.Logger
+	cfg         Config
+	live        eventbus.EventHandler
+
+	// mtx serializes handling, live events and catching up share the position
+	mtx      sync.Mutex
+	position uint64
+	handlers map[string][]eventbus.EventHandler
+	// gaps are positions group passed without reading them, by time they were noticed
+	gaps map[uint64]time.Time
+	// awaited are positions of live events delivered before event store returned them, they are handled as live once read
+	awaited map[uuid.UUID]uint64
+
+	runMtx sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New provides new consumer group adapter,
+// events are read from the given store so it should be wrapped with the same decorators repositories use
+func New(name string, store eventstore.EventStore, bus eventbus.EventBus, checkpoints CheckpointStore, logger *log.Logger, cfg Config) *Group {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.GapTimeout <= 0 {
+		cfg.GapTimeout = time.Minute
+	}
+
+	g := &Group{
+		name:        name,
+		store:       store,
+		bus:         bus,
+		checkpoints: checkpoints,
+		logger:      logger,
+		cfg:         cfg,
+		handlers:    make(map[string][]eventbus.EventHandler),
+		gaps:        make(map[uint64]time.Time),
+		awaited:     make(map[uuid.UUID]uint64),
+	}
+	// handler has to be the same value to unsubscribe it
+	g.live = g.handleLive
+
+	return g
+}
+
+// Name returns group name its checkpoint is saved under
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Position returns global position of the last event handled by the group
+func (g *Group) Position() uint64 {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	return g.position
+}
+
+// Exclusive calls fn while group handles no events, fn gets position of the last handled event,
+// e.g. to swap in rebuilt projection table without losing events handled meanwhile
+func (g *Group) Exclusive(ctx context.Context, fn func(ctx context.Context, position uint64) error) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	return fn(ctx, g.position)
+}
+
+// Subscribe adds handler of given event type or pattern to the group, has to be called before group is started,
+// see eventbus.Topics for patterns
+func (g *Group) Subscribe(eventType string, fn eventbus.EventHandler) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	g.handlers[eventType] = append(g.handlers[eventType], fn)
+}
+
+// Start loads group checkpoint, subscribes to live events and catches up with events stored after the checkpoint,
+// event store is polled until group is stopped so events bus did not deliver are handled as well
+func (g *Group) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	defer close(done)
+
+	g.runMtx.Lock()
+	g.cancel = cancel
+	g.done = done
+	g.runMtx.Unlock()
+
+	position, err := g.checkpoint(ctx)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	g.mtx.Lock()
+	g.position = position
+	g.gaps = make(map[uint64]time.Time)
+	g.awaited = make(map[uuid.UUID]uint64)
+	eventTypes := make([]string, 0, len(g.handlers))
+	for eventType := range g.handlers {
+		eventTypes = append(eventTypes, eventType)
+	}
+	g.mtx.Unlock()
+
+	g.logger.Info(ctx, "[consumer group] %s: starting from position %d\n", g.name, position)
+
+	for _, eventType := range eventTypes {
+		// remote buses block while subscribed
+		go func(eventType string) {
+			if err := g.bus.Subscribe(ctx, eventType, g.live); err != nil && ctx.Err() == nil {
+				g.logger.Error(ctx, "[consumer group] %s: could not subscribe to %s: %v\n", g.name, eventType, err)
+			}
+		}(eventType)
+	}
+	defer func() {
+		for _, eventType := range eventTypes {
+			if err := g.bus.Unsubscribe(context.Background(), eventType, g.live); err != nil {
+				g.logger.Error(ctx, "[consumer group] %s: could not unsubscribe from %s: %v\n", g.name, eventType, err)
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(g.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := g.CatchUp(ctx); err != nil && ctx.Err() == nil {
+			g.logger.Error(ctx, "[consumer group] %s: %v\n", g.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop unsubscribes group from live events and waits for currently handled event
+func (g *Group) Stop(ctx context.Context) error {
+	g.runMtx.Lock()
+	cancel, done := g.cancel, g.done
+	g.runMtx.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err())
+	}
+}
+
+// CatchUp handles events that appeared at positions group passed without them
+// and events stored after the group checkpoint until event store is drained,
+// stops at first failure so events are handled in order they were stored
+func (g *Group) CatchUp(ctx context.Context) (int, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	handled, err := g.fillGaps(ctx)
+	if err != nil {
+		return handled, errors.Wrap(err)
+	}
+
+	n, err := g.catchUp(ctx, nil)
+
+	return handled + n, err
+}
+
+// checkpoint returns saved group position, group without checkpoint starts according to config
+func (g *Group) checkpoint(ctx context.Context) (uint64, error) {
+	position, err := g.checkpoints.Get(ctx, g.name)
+	if err == nil {
+		return position, nil
+	}
+	if !systemErrors.Is(err, ErrCheckpointNotFound) {
+		return 0, errors.Wrap(err)
+	}
+	if !g.cfg.StartAtEnd {
+		return 0, nil
+	}
+
+	for {
+		events, err := g.store.ReadAll(ctx, position, g.cfg.BatchSize)
+		if err != nil {
+			return 0, errors.Wrap(err)
+		}
+		if len(events) > 0 {
+			position = events[len(events)-1].Position
+		}
+		if len(events) < g.cfg.BatchSize {
+			break
+		}
+	}
+
+	if err := g.checkpoints.Save(ctx, g.name, position); err != nil {
+		return 0, errors.Wrap(err)
+	}
+
+	return position, nil
+}
+
+func (g *Group) handleLive(ctx context.Context, event domain.Event) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if event.Position != 0 && event.Position <= g.position {
+		// group read every position it passed except gaps, event at other position was handled already
+		if _, ok := g.gaps[event.Position]; !ok {
+			return nil
+		}
+
+		stored, err := g.storedAfter(ctx, event.Position-1)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if stored == nil || stored.Position != event.Position {
+			// gap is filled once event store returns the event
+			g.await(ctx, event)
+			return nil
+		}
+
+		return g.handleGap(ctx, *stored)
+	}
+
+	if _, err := g.catchUp(ctx, &event); err != nil {
+		return errors.Wrap(err)
+	}
+
+	if event.Position > g.position {
+		// event store does not return the event yet, group polls it until it does
+		g.await(ctx, event)
+	}
+
+	return nil
+}
+
+// await remembers live event event store did not return yet, it is handled as live once read
+func (g *Group) await(ctx context.Context, event domain.Event) {
+	g.logger.Debug(ctx, "[consumer group] %s: %s at position %d is not in event store yet\n", g.name, event.Metadata.Type, event.Position)
+	g.awaited[event.ID] = event.Position
+}
+
+// handlerContext returns context event read from event store is handled with,
+// live events group waited for are handled as live
+func (g *Group) handlerContext(ctx context.Context, event domain.Event) context.Context {
+	if _, ok := g.awaited[event.ID]; ok {
+		return executioncontext.WithFlag(executioncontext.ClearFlag(ctx, executioncontext.REPLAY), executioncontext.LIVE)
+	}
+
+	return executioncontext.WithFlag(executioncontext.ClearFlag(ctx, executioncontext.LIVE), executioncontext.REPLAY)
+}
+
+// catchUp handles stored events after current position, live event is handled with its own context
+// and catching up stops once it is handled, other events are handled as replay
+func (g *Group) catchUp(ctx context.Context, live *domain.Event) (int, error) {
+	saved := g.position
+
+	var handled int
+	for {
+		events, err := g.store.ReadAll(ctx, g.position, g.cfg.BatchSize)
+		if err != nil {
+			return handled, errors.Wrap(err)
+		}
+
+		for _, e := range events {
+			if fns := g.handlersOf(e); len(fns) > 0 {
+				handlerCtx := g.handlerContext(ctx, e)
+				if live != nil && e.ID == live.ID {
+					handlerCtx = ctx
+				}
+
+				for _, fn := range fns {
+					if err := fn(domain.ContextWithCause(handlerCtx, e), e); err != nil {
+						return handled, errors.Wrap(fmt.Errorf("group %s failed to handle %s at position %d: %w", g.name, e.Metadata.Type, e.Position, err))
+					}
+				}
+
+				if err := g.checkpoints.Save(ctx, g.name, e.Position); err != nil {
+					return handled, errors.Wrap(err)
+				}
+				saved = e.Position
+				handled++
+			}
+
+			g.noticeGaps(e.Position)
+			g.position = e.Position
+			delete(g.awaited, e.ID)
+
+			if live != nil && e.ID == live.ID {
+				return handled, g.saveSkipped(ctx, saved)
+			}
+		}
+
+		if len(events) < g.cfg.BatchSize {
+			return handled, g.saveSkipped(ctx, saved)
+		}
+	}
+}
+
+// noticeGaps records positions between current position and position of the next read event,
+// events stored at them may still appear
+func (g *Group) noticeGaps(next uint64) {
+	now := time.Now()
+	for p := g.position + 1; p < next; p++ {
+		g.gaps[p] = now
+	}
+}
+
+// fillGaps handles events that appeared at positions group passed without them,
+// positions still missing after gap timeout are not looked for anymore
+func (g *Group) fillGaps(ctx context.Context) (int, error) {
+	positions := make([]uint64, 0, len(g.gaps))
+	for p := range g.gaps {
+		positions = append(positions, p)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+
+	var (
+		handled int
+		// position of the first stored event after the last gap looked for, gaps before it are still missing
+		next uint64
+	)
+	for _, p := range positions {
+		if p >= next {
+			stored, err := g.storedAfter(ctx, p-1)
+			if err != nil {
+				return handled, errors.Wrap(err)
+			}
+			if stored == nil {
+				next = g.position + 1
+			} else {
+				next = stored.Position
+			}
+
+			if stored != nil && stored.Position == p {
+				if err := g.handleGap(g.handlerContext(ctx, *stored), *stored); err != nil {
+					return handled, errors.Wrap(err)
+				}
+				handled++
+				continue
+			}
+		}
+
+		if time.Since(g.gaps[p]) >= g.cfg.GapTimeout {
+			g.logger.Warning(ctx, "[consumer group] %s: no event appeared at position %d within %s\n", g.name, p, g.cfg.GapTimeout)
+			delete(g.gaps, p)
+			for id, position := range g.awaited {
+				if position == p {
+					delete(g.awaited, id)
+				}
+			}
+		}
+	}
+
+	return handled, nil
+}
+
+// storedAfter returns the first stored event after given position or nil if there is none
+func (g *Group) storedAfter(ctx context.Context, position uint64) (*domain.Event, error) {
+	events, err := g.store.ReadAll(ctx, position, 1)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	return &events[0], nil
+}
+
+// handleGap handles event stored at position group passed without it,
+// checkpoint is not changed as group already saved later position
+func (g *Group) handleGap(ctx context.Context, event domain.Event) error {
+	for _, fn := range g.handlersOf(event) {
+		if err := fn(domain.ContextWithCause(ctx, event), event); err != nil {
+			return errors.Wrap(fmt.Errorf("group %s failed to handle %s at missed position %d: %w", g.name, event.Metadata.Type, event.Position, err))
+		}
+	}
+	delete(g.gaps, event.Position)
+	delete(g.awaited, event.ID)
+
+	return nil
+}
+
+// handlersOf returns group handlers of every topic event is delivered to
+func (g *Group) handlersOf(event domain.Event) []eventbus.EventHandler {
+	var fns []eventbus.EventHandler
+	for _, topic := range eventbus.Topics(event) {
+		fns = append(fns, g.handlers[topic]...)
+	}
+
+	return fns
+}
+
+// saveSkipped saves position of events group has no handlers for,
+// they are not saved one by one to spare checkpoint store writes
+func (g *Group) saveSkipped(ctx context.Context, saved uint64) error {
+	if g.position == saved {
+		return nil
+	}
+
+	if err := g.checkpoints.Save(ctx, g.name, g.position); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,419 @@
+package consumergroup_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/consumergroup"
+	memoryconsumergroup "github.com/vardius/go-api-boilerplate/pkg/consumergroup/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	memoryeventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+type rawEventMock struct{}
+
+func (e rawEventMock) GetType() string {
+	return "test.Mock"
+}
+
+type otherRawEventMock struct{}
+
+func (e otherRawEventMock) GetType() string {
+	return "test.Other"
+}
+
+// recorder records events handled by the group and whether they were live
+type recorder struct {
+	mtx     sync.Mutex
+	handled []domain.Event
+	live    []bool
+	fail    map[uuid.UUID]int
+}
+
+func (r *recorder) handle(ctx context.Context, event domain.Event) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.fail[event.ID] > 0 {
+		r.fail[event.ID]--
+		return errors.New("handler failed")
+	}
+
+	r.handled = append(r.handled, event)
+	r.live = append(r.live, executioncontext.Has(ctx, executioncontext.LIVE))
+
+	return nil
+}
+
+func (r *recorder) positions() []uint64 {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	positions := make([]uint64, len(r.handled))
+	for i, e := range r.handled {
+		positions[i] = e.Position
+	}
+
+	return positions
+}
+
+func storeEvents(t *testing.T, store eventstore.EventStore, rawEvents ...domain.RawEvent) []domain.Event {
+	t.Helper()
+
+	events := make([]domain.Event, len(rawEvents))
+	for i, rawEvent := range rawEvents {
+		e, err := domain.NewEvent(uuid.New(), "test", 0, rawEvent, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events[i] = e
+	}
+
+	if err := store.Store(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+
+	return events
+}
+
+// lateStore hides events from ReadAll until they are released, as if transaction storing them committed late
+type lateStore struct {
+	eventstore.EventStore
+
+	mtx    sync.Mutex
+	hidden map[uuid.UUID]bool
+}
+
+func (s *lateStore) ReadAll(ctx context.Context, fromPosition uint64, limit int) ([]domain.Event, error) {
+	events, err := s.EventStore.ReadAll(ctx, fromPosition, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	visible := events[:0]
+	for _, e := range events {
+		if !s.hidden[e.ID] {
+			visible = append(visible, e)
+		}
+	}
+
+	return visible, nil
+}
+
+func (s *lateStore) release(id uuid.UUID) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.hidden, id)
+}
+
+func equalPositions(got []uint64, want ...uint64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestCatchUpResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	store := memoryeventstore.New()
+	checkpoints := memoryconsumergroup.New()
+	bus := memoryeventbus.New(runtime.NumCPU(), log.New("development"))
+
+	storeEvents(t, store, rawEventMock{}, rawEventMock{}, otherRawEventMock{}, rawEventMock{}, otherRawEventMock{})
+
+	if err := checkpoints.Save(ctx, "test", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{}
+	group := consumergroup.New("test", store, bus, checkpoints, log.New("development"), consumergroup.Config{BatchSize: 2})
+	group.Subscribe("test.Mock", r.handle)
+
+	go group.Start(ctx)
+	defer group.Stop(ctx)
+
+	waitFor(t, func() bool { return group.Position() == 5 })
+
+	if got := r.positions(); !equalPositions(got, 4) {
+		t.Errorf("expected only events after checkpoint to be handled, got positions: %v", got)
+	}
+	for _, live := range r.live {
+		if live {
+			t.Error("events handled while catching up should not be live")
+		}
+	}
+
+	position, err := checkpoints.Get(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position != 5 {
+		t.Errorf("expected checkpoint to include skipped events, got: %d", position)
+	}
+}
+
+func TestLiveEventsAreHandledInOrder(t *testing.T) {
+	ctx := context.Background()
+	store := memoryeventstore.New()
+	checkpoints := memoryconsumergroup.New()
+	bus := memoryeventbus.New(runtime.NumCPU(), log.New("development"))
+
+	r := &recorder{}
+	group := consumergroup.New("test", store, bus, checkpoints, log.New("development"), consumergroup.Config{PollInterval: time.Hour})
+	group.Subscribe("test.Mock", r.handle)
+
+	go group.Start(ctx)
+	defer group.Stop(ctx)
+
+	// let group subscribe to the bus
+	time.Sleep(50 * time.Millisecond)
+
+	events := storeEvents(t, store, rawEventMock{}, rawEventMock{})
+
+	// second event is delivered first, group has to handle the first one before it
+	if err := bus.PublishAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), events[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := r.positions(); !equalPositions(got, 1, 2) {
+		t.Fatalf("expected events to be handled in order, got positions: %v", got)
+	}
+	if r.live[0] || !r.live[1] {
+		t.Errorf("expected only delivered event to be live, got: %v", r.live)
+	}
+
+	// already handled event is not handled again
+	if err := bus.PublishAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), events[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.positions(); !equalPositions(got, 1, 2) {
+		t.Errorf("expected event to be handled once, got positions: %v", got)
+	}
+}
+
+func TestStartAtEnd(t *testing.T) {
+	ctx := context.Background()
+	store := memoryeventstore.New()
+	checkpoints := memoryconsumergroup.New()
+	bus := memoryeventbus.New(runtime.NumCPU(), log.New("development"))
+
+	storeEvents(t, store, rawEventMock{}, rawEventMock{}, rawEventMock{})
+
+	r := &recorder{}
+	group := consumergroup.New("test", store, bus, checkpoints, log.New("development"), consumergroup.Config{BatchSize: 2, StartAtEnd: true})
+	group.Subscribe("test.Mock", r.handle)
+
+	go group.Start(ctx)
+	defer group.Stop(ctx)
+
+	waitFor(t, func() bool {
+		position, err := checkpoints.Get(ctx, "test")
+		return err == nil && position == 3
+	})
+
+	if _, err := group.CatchUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.positions(); len(got) != 0 {
+		t.Errorf("expected past events to be skipped, got positions: %v", got)
+	}
+}
+
+func TestGroupStopsAtFailedEvent(t *testing.T) {
+	ctx := context.Background()
+	store := memoryeventstore.New()
+	checkpoints := memoryconsumergroup.New()
+	bus := memoryeventbus.New(runtime.NumCPU(), log.New("development"))
+
+	events := storeEvents(t, store, rawEventMock{}, rawEventMock{}, rawEventMock{})
+
+	r := &recorder{fail: map[uuid.UUID]int{events[1].ID: 1}}
+	group := consumergroup.New("test", store, bus, checkpoints, log.New("development"), consumergroup.Config{})
+	group.Subscribe("test.Mock", r.handle)
+
+	if _, err := group.CatchUp(ctx); err == nil {
+		t.Fatal("expected handler error")
+	}
+	if position, _ := checkpoints.Get(ctx, "test"); position != 1 {
+		t.Errorf("expected checkpoint at the last handled event, got: %d", position)
+	}
+
+	handled, err := group.CatchUp(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handled != 2 {
+		t.Errorf("expected remaining events to be handled, handled: %d", handled)
+	}
+	if got := r.positions(); !equalPositions(got, 1, 2, 3) {
+		t.Errorf("expected events to be handled in order, got positions: %v", got)
+	}
+}
+
+func TestLateEventsAreNotSkipped(t *testing.T) {
+	ctx := context.Background()
+	store := &lateStore{EventStore: memoryeventstore.New(), hidden: make(map[uuid.UUID]bool)}
+	checkpoints := memoryconsumergroup.New()
+	bus := memoryeventbus.New(runtime.NumCPU(), log.New("development"))
+
+	events := storeEvents(t, store, rawEventMock{}, rawEventMock{}, rawEventMock{}, rawEventMock{})
+	store.hidden[events[1].ID] = true
+	store.hidden[events[2].ID] = true
+
+	r := &recorder{}
+	group := consumergroup.New("test", store, bus, checkpoints, log.New("development"), consumergroup.Config{PollInterval: time.Hour})
+	group.Subscribe("test.Mock", r.handle)
+
+	go group.Start(ctx)
+	defer group.Stop(ctx)
+
+	waitFor(t, func() bool { return group.Position() == 4 })
+
+	// delivered before it is visible in event store, group waits for it instead of failing
+	if err := bus.PublishAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), events[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.positions(); !equalPositions(got, 1, 4) {
+		t.Fatalf("expected event which is not in event store yet not to be handled, got positions: %v", got)
+	}
+
+	store.release(events[1].ID)
+	store.release(events[2].ID)
+
+	if err := bus.PublishAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), events[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.positions(); !equalPositions(got, 1, 4, 2) {
+		t.Fatalf("expected late delivered event to be handled, got positions: %v", got)
+	}
+
+	// event bus did not deliver is found while polling
+	handled, err := group.CatchUp(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handled != 1 {
+		t.Errorf("expected late stored event to be handled, handled: %d", handled)
+	}
+
+	// late events are not handled again
+	if err := bus.PublishAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), events[2]); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.positions(); !equalPositions(got, 1, 4, 2, 3) {
+		t.Errorf("expected each event to be handled once, got positions: %v", got)
+	}
+	if r.live[3] {
+		t.Error("event found while polling should not be live")
+	}
+}
+
+func TestLiveEventAheadOfEventStore(t *testing.T) {
+	ctx := context.Background()
+	store := &lateStore{EventStore: memoryeventstore.New(), hidden: make(map[uuid.UUID]bool)}
+	checkpoints := memoryconsumergroup.New()
+	bus := memoryeventbus.New(runtime.NumCPU(), log.New("development"))
+
+	events := storeEvents(t, store, rawEventMock{}, rawEventMock{})
+	store.hidden[events[1].ID] = true
+
+	r := &recorder{}
+	group := consumergroup.New("test", store, bus, checkpoints, log.New("development"), consumergroup.Config{PollInterval: time.Hour})
+	group.Subscribe("test.Mock", r.handle)
+
+	go group.Start(ctx)
+	defer group.Stop(ctx)
+
+	waitFor(t, func() bool { return group.Position() == 1 })
+
+	// error would make the bus retry the event and file it as a dead letter
+	if err := bus.PublishAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), events[1]); err != nil {
+		t.Fatalf("expected group to wait for event which is not in event store yet, got: %v", err)
+	}
+	if got := r.positions(); !equalPositions(got, 1) {
+		t.Fatalf("expected event which is not in event store yet not to be handled, got positions: %v", got)
+	}
+
+	store.release(events[1].ID)
+
+	if _, err := group.CatchUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.positions(); !equalPositions(got, 1, 2) {
+		t.Fatalf("expected awaited event to be handled, got positions: %v", got)
+	}
+	if !r.live[1] {
+		t.Error("expected awaited event to be handled as live")
+	}
+}
+
+func TestStoppedGroupDoesNotHandleLiveEvents(t *testing.T) {
+	ctx := context.Background()
+	store := memoryeventstore.New()
+	checkpoints := memoryconsumergroup.New()
+	bus := memoryeventbus.New(runtime.NumCPU(), log.New("development"))
+
+	r := &recorder{}
+	group := consumergroup.New("test", store, bus, checkpoints, log.New("development"), consumergroup.Config{PollInterval: time.Hour})
+	group.Subscribe("test.Mock", r.handle)
+
+	go group.Start(ctx)
+
+	// let group subscribe to the bus
+	time.Sleep(50 * time.Millisecond)
+
+	if err := group.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// other handler keeps the event type published
+	other := &recorder{}
+	if err := bus.Subscribe(ctx, "test.Mock", other.handle); err != nil {
+		t.Fatal(err)
+	}
+
+	events := storeEvents(t, store, rawEventMock{})
+	if err := bus.PublishAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), events[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := other.positions(); !equalPositions(got, 1) {
+		t.Fatalf("expected event to be published, got positions: %v", got)
+	}
+	if got := r.positions(); len(got) != 0 {
+		t.Errorf("expected stopped group not to handle events, got positions: %v", got)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatal("condition was not met in time")
+}
@@ -0,0 +1,46 @@
+/*
+Package consumergroup provides memory implementation of consumer group checkpoint store
+*/
+package consumergroup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	baseconsumergroup "github.com/vardius/go-api-boilerplate/pkg/consumergroup"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+type checkpointStore struct {
+	mtx         sync.RWMutex
+	checkpoints map[string]uint64
+}
+
+func (s *checkpointStore) Get(ctx context.Context, group string) (uint64, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	position, ok := s.checkpoints[group]
+	if !ok {
+		return 0, errors.Wrap(fmt.Errorf("%w: %s", baseconsumergroup.ErrCheckpointNotFound, group))
+	}
+
+	return position, nil
+}
+
+func (s *checkpointStore) Save(ctx context.Context, group string, position uint64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.checkpoints[group] = position
+
+	return nil
+}
+
+// New creates in memory checkpoint store
+func New() baseconsumergroup.CheckpointStore {
+	return &checkpointStore{
+		checkpoints: make(map[string]uint64),
+	}
+}
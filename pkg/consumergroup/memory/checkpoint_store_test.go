@@ -0,0 +1,33 @@
+package consumergroup
+
+import (
+	"context"
+	systemErrors "errors"
+	"testing"
+
+	baseconsumergroup "github.com/vardius/go-api-boilerplate/pkg/consumergroup"
+)
+
+func TestCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	if _, err := s.Get(ctx, "group"); !systemErrors.Is(err, baseconsumergroup.ErrCheckpointNotFound) {
+		t.Errorf("expected checkpoint not found, got: %v", err)
+	}
+
+	if err := s.Save(ctx, "group", 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(ctx, "other", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	position, err := s.Get(ctx, "group")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if position != 7 {
+		t.Errorf("expected checkpoint 7, got: %d", position)
+	}
+}
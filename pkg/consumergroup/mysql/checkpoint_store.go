@@ -0,0 +1,49 @@
+/*
+Package consumergroup provides mysql implementation of consumer group checkpoint store
+*/
+package consumergroup
+
+import (
+	"context"
+	"database/sql"
+	systemErrors "errors"
+	"fmt"
+
+	baseconsumergroup "github.com/vardius/go-api-boilerplate/pkg/consumergroup"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+type checkpointStore struct {
+	db *sql.DB
+}
+
+func (s *checkpointStore) Get(ctx context.Context, group string) (uint64, error) {
+	var position uint64
+
+	row := s.db.QueryRowContext(ctx, `SELECT position FROM consumer_group_checkpoints WHERE group_name=?`, group)
+	if err := row.Scan(&position); err != nil {
+		if systemErrors.Is(err, sql.ErrNoRows) {
+			return 0, errors.Wrap(fmt.Errorf("%w: %s", baseconsumergroup.ErrCheckpointNotFound, group))
+		}
+
+		return 0, errors.Wrap(err)
+	}
+
+	return position, nil
+}
+
+func (s *checkpointStore) Save(ctx context.Context, group string, position uint64) error {
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO consumer_group_checkpoints (group_name, position, updated_at) VALUES (?,?,UTC_TIMESTAMP()) ON DUPLICATE KEY UPDATE position=VALUES(position), updated_at=VALUES(updated_at)`,
+		group, position,
+	); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// New creates mysql checkpoint store,
+// services sharing database keep their checkpoints apart by using different group names
+func New(db *sql.DB) baseconsumergroup.CheckpointStore {
+	return &checkpointStore{db: db}
+}
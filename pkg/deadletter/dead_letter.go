@@ -0,0 +1,44 @@
+package deadletter
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Letter is an event handler failed to handle after all retry attempts,
+// event is referenced by id and read from event store again to retry it so its personal data is not copied
+type Letter struct {
+	ID        uuid.UUID `json:"id"`
+	EventID   uuid.UUID `json:"event_id"`
+	EventType string    `json:"event_type"`
+	// Topic is an event type or pattern handler was subscribed to, see eventbus.Topics
+	Topic     string    `json:"topic"`
+	Handler   string    `json:"handler"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// Store methods allow to keep and resolve dead letters
+type Store interface {
+	// Save adds dead letter replacing the one with the same id
+	Save(ctx context.Context, letter Letter) error
+	// Get returns dead letter or ErrLetterNotFound
+	Get(ctx context.Context, id uuid.UUID) (Letter, error)
+	// List returns up to limit dead letters in order they failed
+	List(ctx context.Context, offset, limit int) ([]Letter, error)
+	// Delete removes dead letter, deleting not existing dead letter is not an error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// Admin allows to inspect and resolve dead letters
+type Admin interface {
+	// DeadLetters returns up to limit dead letters in order they failed
+	DeadLetters(ctx context.Context, offset, limit int) ([]Letter, error)
+	// Retry calls handler of dead letter once again, dead letter is removed if it succeeds
+	Retry(ctx context.Context, id uuid.UUID) error
+	// Discard removes dead letter without handling it
+	Discard(ctx context.Context, id uuid.UUID) error
+}
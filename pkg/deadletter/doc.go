@@ -0,0 +1,4 @@
+/*
+Package deadletter provides event bus delivery layer retrying failed handlers and keeping events they could not handle
+*/
+package deadletter
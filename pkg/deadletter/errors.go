@@ -0,0 +1,16 @@
+package deadletter
+
+import (
+	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+)
+
+var (
+	// ErrLetterNotFound is when dead letter does not exist
+	ErrLetterNotFound = fmt.Errorf("%w: dead letter", application.ErrNotFound)
+	// ErrHandlerNotFound is when handler of dead letter is not subscribed to the event bus
+	ErrHandlerNotFound = fmt.Errorf("%w: dead letter handler", application.ErrNotFound)
+	// ErrDuplicateHandler is when other handler is already subscribed to event type under the same name
+	ErrDuplicateHandler = fmt.Errorf("%w: dead letter handler", application.ErrConflict)
+)
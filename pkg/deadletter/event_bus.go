@@ -0,0 +1,272 @@
+package deadletter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+type subscription struct {
+	topic   string
+	name    string
+	policy  RetryPolicy
+	fn      eventbus.EventHandler
+	wrapped eventbus.EventHandler
+}
+
+// EventBus wraps event bus retrying each handler according to its retry policy,
+// events handler failed to handle after all attempts are saved to dead-letter store
+type EventBus struct {
+	eventbus.EventBus
+	store      Store
+	eventStore eventstore.EventStore
+	policy     RetryPolicy
+	logger     *log.Logger
+
+	mtx sync.RWMutex
+	// subscriptions by event type and handler name
+	subscriptions map[string]map[string]subscription
+}
+
+// NewEventBus wraps event bus, handlers subscribed with Subscribe use given default retry policy,
+// events of dead letters are read from event store to retry them so it should be wrapped with the same decorators repositories use
+func NewEventBus(bus eventbus.EventBus, store Store, eventStore eventstore.EventStore, policy RetryPolicy, logger *log.Logger) *EventBus {
+	return &EventBus{
+		EventBus:      bus,
+		store:         store,
+		eventStore:    eventStore,
+		policy:        policy,
+		logger:        logger,
+		subscriptions: make(map[string]map[string]subscription),
+	}
+}
+
+// Subscribe handler with default retry policy, handler is named after function that created it,
+// handlers created by the same function have to be subscribed with SubscribeWithPolicy under distinct names
+func (b *EventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	return b.SubscribeWithPolicy(ctx, eventType, HandlerName(fn), fn, b.policy)
+}
+
+// SubscribeWithPolicy subscribes handler with given name and retry policy,
+// name is saved with dead letters and has to stay the same to retry them,
+// subscribing other handler under name already subscribed to event type fails with ErrDuplicateHandler
+func (b *EventBus) SubscribeWithPolicy(ctx context.Context, eventType, handlerName string, fn eventbus.EventHandler, policy RetryPolicy) error {
+	s := subscription{
+		topic:  eventType,
+		name:   handlerName,
+		policy: policy,
+		fn:     fn,
+	}
+	s.wrapped = b.withRetries(s)
+
+	b.mtx.Lock()
+	if _, ok := b.subscriptions[eventType]; !ok {
+		b.subscriptions[eventType] = make(map[string]subscription)
+	}
+	previous, resubscribed := b.subscriptions[eventType][handlerName]
+	if resubscribed && reflect.ValueOf(previous.fn) != reflect.ValueOf(fn) {
+		b.mtx.Unlock()
+
+		return errors.Wrap(fmt.Errorf("%w: %s for %s", ErrDuplicateHandler, handlerName, eventType))
+	}
+	b.subscriptions[eventType][handlerName] = s
+	b.mtx.Unlock()
+
+	if resubscribed {
+		if err := b.EventBus.Unsubscribe(ctx, eventType, previous.wrapped); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	if err := b.EventBus.Subscribe(ctx, eventType, s.wrapped); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// Unsubscribe handler
+func (b *EventBus) Unsubscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	rv := reflect.ValueOf(fn)
+
+	b.mtx.Lock()
+	var found *subscription
+	for name, s := range b.subscriptions[eventType] {
+		if reflect.ValueOf(s.fn) == rv {
+			s := s
+			found = &s
+			delete(b.subscriptions[eventType], name)
+			break
+		}
+	}
+	b.mtx.Unlock()
+
+	if found == nil {
+		return nil
+	}
+
+	if err := b.EventBus.Unsubscribe(ctx, eventType, found.wrapped); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// DeadLetters returns up to limit dead letters in order they failed
+func (b *EventBus) DeadLetters(ctx context.Context, offset, limit int) ([]Letter, error) {
+	letters, err := b.store.List(ctx, offset, limit)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return letters, nil
+}
+
+// Retry calls handler of dead letter once, dead letter is removed when handler succeeds
+// otherwise its attempts and last error are updated
+func (b *EventBus) Retry(ctx context.Context, id uuid.UUID) error {
+	letter, err := b.store.Get(ctx, id)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	b.mtx.RLock()
+	s, ok := b.subscriptions[letter.Topic][letter.Handler]
+	b.mtx.RUnlock()
+
+	if !ok {
+		return errors.Wrap(fmt.Errorf("%w: %s for %s", ErrHandlerNotFound, letter.Handler, letter.Topic))
+	}
+
+	event, err := b.eventStore.Get(ctx, letter.EventID)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if handlerErr := s.fn(domain.ContextWithCause(ctx, event), event); handlerErr != nil {
+		letter.Attempts++
+		letter.LastError = handlerErr.Error()
+		letter.FailedAt = time.Now()
+
+		if err := b.store.Save(ctx, letter); err != nil {
+			return errors.Wrap(err)
+		}
+
+		return errors.Wrap(handlerErr)
+	}
+
+	if err := b.store.Delete(ctx, id); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// Discard removes dead letter without handling it
+func (b *EventBus) Discard(ctx context.Context, id uuid.UUID) error {
+	if _, err := b.store.Get(ctx, id); err != nil {
+		return errors.Wrap(err)
+	}
+
+	if err := b.store.Delete(ctx, id); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (b *EventBus) withRetries(s subscription) eventbus.EventHandler {
+	return func(ctx context.Context, event domain.Event) error {
+		var (
+			err     error
+			attempt int
+		)
+
+	attempts:
+		for attempt = 1; ; attempt++ {
+			if err = s.fn(ctx, event); err == nil {
+				return nil
+			}
+			if attempt >= s.policy.MaxAttempts {
+				break
+			}
+
+			b.logger.Warning(ctx, "[EventBus] %s failed to handle %s (attempt %d): %v\n", s.name, event.Metadata.Type, attempt, err)
+
+			select {
+			case <-ctx.Done():
+				break attempts
+			case <-time.After(s.policy.Backoff(attempt)):
+			}
+		}
+
+		letter := Letter{
+			ID:        uuid.New(),
+			EventID:   event.ID,
+			EventType: event.Metadata.Type,
+			Topic:     s.topic,
+			Handler:   s.name,
+			Attempts:  attempt,
+			LastError: err.Error(),
+			FailedAt:  time.Now(),
+		}
+
+		// handler context may be already cancelled, dead letter has to be saved regardless
+		if saveErr := b.store.Save(context.Background(), letter); saveErr != nil {
+			b.logger.Critical(ctx, "[EventBus] %s could not save dead letter of %s %s: %v\n", s.name, event.Metadata.Type, event.ID, saveErr)
+		} else {
+			b.logger.Error(ctx, "[EventBus] %s moved %s %s to dead letters as %s: %v\n", s.name, event.Metadata.Type, event.ID, letter.ID, err)
+		}
+
+		return errors.Wrap(err)
+	}
+}
+
+// HandlerName returns name of the function that created handler,
+// e.g. eventhandler.WhenUserWasRegisteredWithEmail for a closure returned by it
+func HandlerName(fn eventbus.EventHandler) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	// strip suffixes of anonymous functions, e.g. .func1 or .func1.2
+	parts := strings.Split(name, ".")
+	for len(parts) > 2 && isAnonymousFuncSuffix(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func isAnonymousFuncSuffix(part string) bool {
+	part = strings.TrimPrefix(part, "func")
+	if part == "" {
+		return false
+	}
+
+	for _, r := range part {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
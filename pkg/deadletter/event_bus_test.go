@@ -0,0 +1,263 @@
+package deadletter_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/deadletter"
+	memorydeadletter "github.com/vardius/go-api-boilerplate/pkg/deadletter/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	memoryeventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+type eventMock struct{}
+
+func (e eventMock) GetType() string {
+	return "test.Mock"
+}
+
+func newFlakyHandler(failures int32) (eventbus.EventHandler, *int32) {
+	var calls int32
+
+	return func(ctx context.Context, event domain.Event) error {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			return errors.New("handler failed")
+		}
+
+		return nil
+	}, &calls
+}
+
+func TestEventBusRetries(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New("development")
+	store := memorydeadletter.New()
+	bus := deadletter.NewEventBus(memoryeventbus.New(1, logger), store, memoryeventstore.New(), deadletter.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}, logger)
+
+	handler, calls := newFlakyHandler(2)
+	if err := bus.Subscribe(ctx, (eventMock{}).GetType(), handler); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := domain.NewEvent(uuid.New(), "test", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.PublishAndAcknowledge(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(calls) != 3 {
+		t.Errorf("expected 3 calls, got %d", atomic.LoadInt32(calls))
+	}
+
+	letters, err := bus.DeadLetters(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 0 {
+		t.Errorf("expected no dead letters, got %v", letters)
+	}
+}
+
+func TestEventBusDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New("development")
+	store := memorydeadletter.New()
+	eventStore := memoryeventstore.New()
+	bus := deadletter.NewEventBus(memoryeventbus.New(1, logger), store, eventStore, deadletter.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}, logger)
+
+	handler, calls := newFlakyHandler(3)
+	if err := bus.SubscribeWithPolicy(ctx, (eventMock{}).GetType(), "flaky", handler, deadletter.RetryPolicy{MaxAttempts: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := domain.NewEvent(uuid.New(), "test", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// dead letters refer to stored event
+	if err := eventStore.Store(ctx, []domain.Event{event}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.PublishAndAcknowledge(ctx, event); err == nil {
+		t.Error("expected handler error once retries were exhausted")
+	}
+	if atomic.LoadInt32(calls) != 2 {
+		t.Errorf("expected 2 calls, got %d", atomic.LoadInt32(calls))
+	}
+
+	letters, err := bus.DeadLetters(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("expected one dead letter, got %v", letters)
+	}
+
+	letter := letters[0]
+	if letter.EventID != event.ID || letter.EventType != event.Metadata.Type || letter.Handler != "flaky" || letter.Attempts != 2 || letter.LastError == "" {
+		t.Errorf("unexpected dead letter %v", letter)
+	}
+
+	// third call still fails, dead letter is kept with updated attempts
+	if err := bus.Retry(ctx, letter.ID); err == nil {
+		t.Error("expected retry to fail")
+	}
+
+	letters, err = bus.DeadLetters(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 || letters[0].Attempts != 3 {
+		t.Fatalf("expected dead letter with 3 attempts, got %v", letters)
+	}
+
+	if err := bus.Retry(ctx, letter.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, letter.ID); !errors.Is(err, deadletter.ErrLetterNotFound) {
+		t.Errorf("expected dead letter to be removed after successful retry, got %v", err)
+	}
+}
+
+func TestEventBusDiscard(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New("development")
+	store := memorydeadletter.New()
+	bus := deadletter.NewEventBus(memoryeventbus.New(1, logger), store, memoryeventstore.New(), deadletter.RetryPolicy{}, logger)
+
+	letter := deadletter.Letter{ID: uuid.New(), Handler: "unknown", FailedAt: time.Now()}
+	if err := store.Save(ctx, letter); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.Retry(ctx, letter.ID); !errors.Is(err, deadletter.ErrHandlerNotFound) {
+		t.Errorf("expected handler not found error, got %v", err)
+	}
+
+	if err := bus.Discard(ctx, letter.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Discard(ctx, letter.ID); !errors.Is(err, deadletter.ErrLetterNotFound) {
+		t.Errorf("expected letter not found error, got %v", err)
+	}
+}
+
+func TestEventBusUnsubscribe(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New("development")
+	bus := deadletter.NewEventBus(memoryeventbus.New(1, logger), memorydeadletter.New(), memoryeventstore.New(), deadletter.RetryPolicy{}, logger)
+
+	handler, calls := newFlakyHandler(0)
+	if err := bus.Subscribe(ctx, (eventMock{}).GetType(), handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Unsubscribe(ctx, (eventMock{}).GetType(), handler); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := domain.NewEvent(uuid.New(), "test", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.PublishAndAcknowledge(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(calls) != 0 {
+		t.Errorf("expected unsubscribed handler not to be called, got %d calls", atomic.LoadInt32(calls))
+	}
+}
+
+func TestHandlerName(t *testing.T) {
+	handler, _ := newFlakyHandler(0)
+
+	if name := deadletter.HandlerName(handler); name != "deadletter_test.newFlakyHandler" {
+		t.Errorf("HandlerName() = %s, want deadletter_test.newFlakyHandler", name)
+	}
+}
+
+func TestEventBusDuplicateHandler(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New("development")
+	bus := deadletter.NewEventBus(memoryeventbus.New(1, logger), memorydeadletter.New(), memoryeventstore.New(), deadletter.RetryPolicy{}, logger)
+
+	first, firstCalls := newFlakyHandler(0)
+	second, secondCalls := newFlakyHandler(0)
+
+	if err := bus.Subscribe(ctx, (eventMock{}).GetType(), first); err != nil {
+		t.Fatal(err)
+	}
+	// same handler can be subscribed again
+	if err := bus.Subscribe(ctx, (eventMock{}).GetType(), first); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Subscribe(ctx, (eventMock{}).GetType(), second); !errors.Is(err, deadletter.ErrDuplicateHandler) {
+		t.Fatalf("expected duplicate handler error, got %v", err)
+	}
+
+	event, err := domain.NewEvent(uuid.New(), "test", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.PublishAndAcknowledge(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(firstCalls) != 1 {
+		t.Errorf("expected first handler to be called once, got %d calls", atomic.LoadInt32(firstCalls))
+	}
+	if atomic.LoadInt32(secondCalls) != 0 {
+		t.Errorf("expected rejected handler not to be called, got %d calls", atomic.LoadInt32(secondCalls))
+	}
+}
+
+func TestEventBusRetryPatternHandler(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New("development")
+	eventStore := memoryeventstore.New()
+	bus := deadletter.NewEventBus(memoryeventbus.New(1, logger), memorydeadletter.New(), eventStore, deadletter.RetryPolicy{MaxAttempts: 1}, logger)
+
+	handler, calls := newFlakyHandler(1)
+	if err := bus.Subscribe(ctx, eventbus.AllEvents, handler); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := domain.NewEvent(uuid.New(), "test", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// dead letters refer to stored event
+	if err := eventStore.Store(ctx, []domain.Event{event}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.PublishAndAcknowledge(ctx, event); err == nil {
+		t.Fatal("expected handler error")
+	}
+
+	letters, err := bus.DeadLetters(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 || letters[0].Topic != eventbus.AllEvents {
+		t.Fatalf("expected dead letter of handler subscribed to all events, got %v", letters)
+	}
+
+	if err := bus.Retry(ctx, letters[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(calls) != 2 {
+		t.Errorf("expected handler to be called again, got %d calls", atomic.LoadInt32(calls))
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Package deadletter provides memory implementation of dead-letter store
+*/
+package deadletter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+
+	basedeadletter "github.com/vardius/go-api-boilerplate/pkg/deadletter"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+type store struct {
+	mtx     sync.RWMutex
+	letters []basedeadletter.Letter
+}
+
+func (s *store) Save(ctx context.Context, letter basedeadletter.Letter) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for i, l := range s.letters {
+		if l.ID == letter.ID {
+			s.letters[i] = letter
+			return nil
+		}
+	}
+
+	s.letters = append(s.letters, letter)
+
+	return nil
+}
+
+func (s *store) Get(ctx context.Context, id uuid.UUID) (basedeadletter.Letter, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	for _, l := range s.letters {
+		if l.ID == id {
+			return l, nil
+		}
+	}
+
+	return basedeadletter.Letter{}, errors.Wrap(fmt.Errorf("%w: %s", basedeadletter.ErrLetterNotFound, id))
+}
+
+func (s *store) List(ctx context.Context, offset, limit int) ([]basedeadletter.Letter, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	letters := make([]basedeadletter.Letter, 0)
+	for i := offset; i < len(s.letters) && len(letters) < limit; i++ {
+		letters = append(letters, s.letters[i])
+	}
+
+	return letters, nil
+}
+
+func (s *store) Delete(ctx context.Context, id uuid.UUID) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for i, l := range s.letters {
+		if l.ID == id {
+			s.letters = append(s.letters[:i], s.letters[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// New creates in memory dead-letter store
+func New() basedeadletter.Store {
+	return &store{}
+}
@@ -0,0 +1,70 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	basedeadletter "github.com/vardius/go-api-boilerplate/pkg/deadletter"
+)
+
+func TestNew(t *testing.T) {
+	s := New()
+
+	if s == nil {
+		t.Fail()
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	first := basedeadletter.Letter{ID: uuid.New(), Handler: "first", Attempts: 1, FailedAt: time.Now()}
+	second := basedeadletter.Letter{ID: uuid.New(), Handler: "second", Attempts: 1, FailedAt: time.Now()}
+
+	if err := s.Save(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+
+	first.Attempts = 2
+	if err := s.Save(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+
+	letters, err := s.List(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 2 || letters[0].ID != first.ID || letters[0].Attempts != 2 {
+		t.Fatalf("expected updated first letter followed by second one, got %v", letters)
+	}
+
+	letters, err = s.List(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 || letters[0].ID != second.ID {
+		t.Errorf("expected second letter only, got %v", letters)
+	}
+
+	if err := s.Delete(ctx, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, first.ID); !errors.Is(err, basedeadletter.ErrLetterNotFound) {
+		t.Errorf("expected letter not found error, got %v", err)
+	}
+
+	letter, err := s.Get(ctx, second.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if letter.Handler != "second" {
+		t.Errorf("Handler = %s, want second", letter.Handler)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Package deadletter provides mysql implementation of dead-letter store
+*/
+package deadletter
+
+import (
+	"context"
+	"database/sql"
+	systemErrors "errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	basedeadletter "github.com/vardius/go-api-boilerplate/pkg/deadletter"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+type store struct {
+	db   *sql.DB
+	name string
+}
+
+func (s *store) Save(ctx context.Context, letter basedeadletter.Letter) error {
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO dead_letters (id, store_name, event_id, event_type, topic, handler, attempts, last_error, failed_at) VALUES (?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE attempts=VALUES(attempts), last_error=VALUES(last_error), failed_at=VALUES(failed_at)`,
+		letter.ID.String(), s.name, letter.EventID.String(), letter.EventType, letter.Topic, letter.Handler, letter.Attempts, letter.LastError, letter.FailedAt.UTC(),
+	); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (s *store) Get(ctx context.Context, id uuid.UUID) (basedeadletter.Letter, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT id, event_id, event_type, topic, handler, attempts, last_error, failed_at FROM dead_letters WHERE store_name=? AND id=?`, s.name, id.String())
+
+	letter, err := scanLetter(row)
+	if err != nil {
+		if systemErrors.Is(err, sql.ErrNoRows) {
+			return basedeadletter.Letter{}, errors.Wrap(fmt.Errorf("%w: %s", basedeadletter.ErrLetterNotFound, id))
+		}
+
+		return basedeadletter.Letter{}, errors.Wrap(err)
+	}
+
+	return letter, nil
+}
+
+func (s *store) List(ctx context.Context, offset, limit int) ([]basedeadletter.Letter, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, event_id, event_type, topic, handler, attempts, last_error, failed_at FROM dead_letters WHERE store_name=? ORDER BY distinct_id ASC LIMIT ? OFFSET ?`, s.name, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defer rows.Close()
+
+	letters := make([]basedeadletter.Letter, 0)
+	for rows.Next() {
+		letter, err := scanLetter(rows)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		letters = append(letters, letter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return letters, nil
+}
+
+func (s *store) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM dead_letters WHERE store_name=? AND id=?`, s.name, id.String()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLetter(row scanner) (basedeadletter.Letter, error) {
+	var (
+		letter  basedeadletter.Letter
+		id      string
+		eventID string
+	)
+	if err := row.Scan(&id, &eventID, &letter.EventType, &letter.Topic, &letter.Handler, &letter.Attempts, &letter.LastError, &letter.FailedAt); err != nil {
+		return letter, err
+	}
+
+	var err error
+	letter.ID, err = uuid.Parse(id)
+	if err != nil {
+		return letter, err
+	}
+
+	letter.EventID, err = uuid.Parse(eventID)
+	if err != nil {
+		return letter, err
+	}
+
+	return letter, nil
+}
+
+// New creates mysql dead-letter store of given name,
+// services sharing database keep their dead letters apart by using different names
+func New(db *sql.DB, name string) basedeadletter.Store {
+	return &store{db: db, name: name}
+}
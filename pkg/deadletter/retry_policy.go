@@ -0,0 +1,37 @@
+package deadletter
+
+import (
+	"time"
+)
+
+// RetryPolicy describes how many times and how often handler is called
+// before event is moved to dead-letter store
+type RetryPolicy struct {
+	// MaxAttempts including the first call, values lower than 2 disable retries
+	MaxAttempts int
+	// InitialBackoff is a delay after the first failed attempt, doubled after each next one
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay, 0 means no cap
+	MaxBackoff time.Duration
+}
+
+// Backoff returns delay after given failed attempt, attempts are counted from 1
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 || p.InitialBackoff <= 0 {
+		return 0
+	}
+
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	return backoff
+}
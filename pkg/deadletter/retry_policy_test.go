@@ -0,0 +1,26 @@
+package deadletter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	for attempt, want := range map[int]time.Duration{
+		0: 0,
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 300 * time.Millisecond,
+		9: 300 * time.Millisecond,
+	} {
+		if got := p.Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+
+	if got := (RetryPolicy{InitialBackoff: time.Second}).Backoff(4); got != 8*time.Second {
+		t.Errorf("uncapped Backoff(4) = %s, want 8s", got)
+	}
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/identity"
+	"github.com/vardius/go-api-boilerplate/pkg/metadata"
+)
+
+// NewEventFromContext creates new event with identity, correlation and causation taken from context,
+// events created outside of a request or an event handler start their own correlation
+func NewEventFromContext(ctx context.Context, streamID uuid.UUID, streamName string, streamVersion int, rawEvent RawEvent) (Event, error) {
+	var i *identity.Identity
+	if ctxIdentity, ok := identity.FromContext(ctx); ok {
+		i = &ctxIdentity
+	}
+
+	event, err := NewEvent(streamID, streamName, streamVersion, rawEvent, i)
+	if err != nil {
+		return NullEvent, err
+	}
+
+	if m, ok := metadata.FromContext(ctx); ok {
+		event.Metadata.CorrelationID = m.TraceID
+		event.Metadata.CausationID = m.CausationID
+		if event.Metadata.CausationID == "" {
+			event.Metadata.CausationID = m.TraceID
+		}
+	}
+	if event.Metadata.CorrelationID == "" {
+		event.Metadata.CorrelationID = event.ID.String()
+	}
+
+	return event, nil
+}
+
+// ContextWithCause returns context for handling given event,
+// events created within it are correlated with the event and caused by it
+func ContextWithCause(ctx context.Context, event Event) context.Context {
+	m := &metadata.Metadata{
+		Now:     time.Now(),
+		TraceID: event.ID.String(),
+	}
+	if parent, ok := metadata.FromContext(ctx); ok {
+		m.TraceID = parent.TraceID
+		m.StatusCode = parent.StatusCode
+	}
+	if event.Metadata.CorrelationID != "" {
+		m.TraceID = event.Metadata.CorrelationID
+	}
+	m.CausationID = event.ID.String()
+
+	return metadata.ContextWithMetadata(ctx, m)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/identity"
+	"github.com/vardius/go-api-boilerplate/pkg/metadata"
+)
+
+func TestNewEventFromContext(t *testing.T) {
+	m := metadata.New()
+	i := identity.New(uuid.New(), uuid.New(), "test@test.com", "secret", "token")
+	ctx := identity.ContextWithIdentity(metadata.ContextWithMetadata(context.Background(), m), i)
+
+	event, err := NewEventFromContext(ctx, uuid.New(), "test", 0, rawEventMock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event.Identity == nil || event.Identity.UserID != i.UserID {
+		t.Errorf("expected identity from context, got %v", event.Identity)
+	}
+	if event.Metadata.CorrelationID != m.TraceID {
+		t.Errorf("CorrelationID = %s, want %s", event.Metadata.CorrelationID, m.TraceID)
+	}
+	if event.Metadata.CausationID != m.TraceID {
+		t.Errorf("event created within request should be caused by it, got %s", event.Metadata.CausationID)
+	}
+
+	caused, err := NewEventFromContext(ContextWithCause(context.Background(), event), uuid.New(), "test", 0, rawEventMock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if caused.Metadata.CorrelationID != m.TraceID {
+		t.Errorf("expected correlation to be carried over, got %s", caused.Metadata.CorrelationID)
+	}
+	if caused.Metadata.CausationID != event.ID.String() {
+		t.Errorf("CausationID = %s, want %s", caused.Metadata.CausationID, event.ID)
+	}
+}
+
+func TestNewEventFromContextWithoutMetadata(t *testing.T) {
+	event, err := NewEventFromContext(context.Background(), uuid.New(), "test", 0, rawEventMock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event.Metadata.CorrelationID != event.ID.String() || event.Metadata.CausationID != "" {
+		t.Errorf("expected event to start its own correlation, got %+v", event.Metadata)
+	}
+}
@@ -3,6 +3,8 @@ package domain
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,17 +15,37 @@ import (
 // NullEvent represents empty event
 var NullEvent Event
 
+// PersonalDataTag is a struct tag marking raw event fields holding personal data, e.g. `json:"email" pii:"true"`
+const PersonalDataTag = "pii"
+
+// InitialSchemaVersion is a schema version of event payloads that were never evolved,
+// events stored before schema versioning was introduced are treated as this version
+const InitialSchemaVersion = 1
+
 // RawEvent represents raw event that it is aware of its type
 type RawEvent interface {
 	GetType() string
 }
 
+// SchemaVersionedEvent represents raw event which payload shape has evolved,
+// events not implementing it are created with InitialSchemaVersion
+type SchemaVersionedEvent interface {
+	RawEvent
+	GetSchemaVersion() int
+}
+
 // Event contains id, payload and metadata
 type Event struct {
 	ID       uuid.UUID          `json:"id"`
 	Metadata EventMetaData      `json:"metadata"`
 	Payload  json.RawMessage    `json:"payload"`
 	Identity *identity.Identity `json:"identity,omitempty"`
+	// Position is a global, monotonic position of an event in the event store log
+	// assigned by the store when event is appended, zero for events not stored yet
+	Position uint64 `json:"position,omitempty"`
+	// PersonalData lists payload fields tagged with PersonalDataTag,
+	// event store may encrypt them with a key of the stream subject
+	PersonalData []string `json:"personal_data,omitempty"`
 }
 
 // EventMetaData for Event
@@ -33,6 +55,11 @@ type EventMetaData struct {
 	StreamName    string    `json:"stream_name"`
 	StreamVersion int       `json:"stream_version"`
 	OccurredAt    time.Time `json:"occurred_at"`
+	SchemaVersion int       `json:"schema_version,omitempty"`
+	// CorrelationID is shared by all events originating from the same request
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// CausationID is an id of event or request that caused this event
+	CausationID string `json:"causation_id,omitempty"`
 }
 
 // NewEvent create new event
@@ -43,6 +70,11 @@ func NewEvent(streamID uuid.UUID, streamName string, streamVersion int, rawEvent
 		StreamName:    streamName,
 		StreamVersion: streamVersion,
 		OccurredAt:    time.Now(),
+		SchemaVersion: InitialSchemaVersion,
+	}
+
+	if versioned, ok := rawEvent.(SchemaVersionedEvent); ok {
+		meta.SchemaVersion = versioned.GetSchemaVersion()
 	}
 
 	payload, err := json.Marshal(rawEvent)
@@ -56,10 +88,11 @@ func NewEvent(streamID uuid.UUID, streamName string, streamVersion int, rawEvent
 	}
 
 	return Event{
-		ID:       id,
-		Metadata: meta,
-		Payload:  payload,
-		Identity: identity,
+		ID:           id,
+		Metadata:     meta,
+		Payload:      payload,
+		Identity:     identity,
+		PersonalData: personalDataFields(rawEvent),
 	}, nil
 }
 
@@ -77,3 +110,34 @@ func MakeEvent(meta EventMetaData, payload json.RawMessage, identity *identity.I
 		Identity: identity,
 	}, nil
 }
+
+// personalDataFields returns json names of top level raw event fields tagged with PersonalDataTag
+func personalDataFields(rawEvent RawEvent) []string {
+	t := reflect.TypeOf(rawEvent)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get(PersonalDataTag) != "true" {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+
+		fields = append(fields, name)
+	}
+
+	return fields
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// ErrUnknownEventType is when event type was not registered
+	ErrUnknownEventType = errors.New("unknown event type")
+	// ErrEventTypeMismatch is when event is decoded into raw event of a different type
+	ErrEventTypeMismatch = errors.New("event type mismatch")
+)
+
+// EventRegistry maps stable event type names to raw event types
+// so stored events can be decoded back into typed raw events
+type EventRegistry struct {
+	mtx   sync.RWMutex
+	types map[string]reflect.Type
+}
+
+// NewEventRegistry creates empty event registry
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// Register adds raw event types under names returned by their GetType method,
+// names are stored with every event so they have to stay the same once events were stored
+func (r *EventRegistry) Register(rawEvents ...RawEvent) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, rawEvent := range rawEvents {
+		name := rawEvent.GetType()
+		if name == "" {
+			return fmt.Errorf("raw event %T has empty type name", rawEvent)
+		}
+
+		t := reflect.TypeOf(rawEvent)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if registered, ok := r.types[name]; ok && registered != t {
+			return fmt.Errorf("event type %s is already registered for %s", name, registered)
+		}
+
+		r.types[name] = t
+	}
+
+	return nil
+}
+
+// MustRegister is like Register but panics if raw events can not be registered,
+// it simplifies registration of package level registries
+func (r *EventRegistry) MustRegister(rawEvents ...RawEvent) {
+	if err := r.Register(rawEvents...); err != nil {
+		panic(err)
+	}
+}
+
+// Decode unmarshals event payload into a new raw event of registered type
+func (r *EventRegistry) Decode(event Event) (RawEvent, error) {
+	r.mtx.RLock()
+	t, ok := r.types[event.Metadata.Type]
+	r.mtx.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, event.Metadata.Type)
+	}
+
+	v := reflect.New(t)
+	if err := json.Unmarshal(event.Payload, v.Interface()); err != nil {
+		return nil, fmt.Errorf("could not decode event %s payload: %w", event.Metadata.Type, err)
+	}
+
+	rawEvent, ok := v.Elem().Interface().(RawEvent)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s does not implement RawEvent", ErrUnknownEventType, t)
+	}
+
+	return rawEvent, nil
+}
+
+// DecodeInto unmarshals event payload into target pointer to a registered raw event,
+// fails if event type differs from the type of target
+func (r *EventRegistry) DecodeInto(event Event, target RawEvent) error {
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return fmt.Errorf("decode target has to be a pointer, got %T", target)
+	}
+
+	r.mtx.RLock()
+	registered, ok := r.types[event.Metadata.Type]
+	r.mtx.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEventType, event.Metadata.Type)
+	}
+	if registered != t.Elem() {
+		return fmt.Errorf("%w: can not decode %s into %s", ErrEventTypeMismatch, event.Metadata.Type, t.Elem())
+	}
+
+	if err := json.Unmarshal(event.Payload, target); err != nil {
+		return fmt.Errorf("could not decode event %s payload: %w", event.Metadata.Type, err)
+	}
+
+	return nil
+}
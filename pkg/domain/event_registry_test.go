@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEventRegistryDecode(t *testing.T) {
+	registry := NewEventRegistry()
+	if err := registry.Register(versionedEventMock{}); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := NewEvent(uuid.New(), "streamName", 0, versionedEventMock{FullName: "John Doe"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rawEvent, err := registry.Decode(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, ok := rawEvent.(versionedEventMock)
+	if !ok {
+		t.Fatalf("expected versionedEventMock, got %T", rawEvent)
+	}
+	if decoded.FullName != "John Doe" {
+		t.Errorf("FullName = %s, want John Doe", decoded.FullName)
+	}
+
+	var target versionedEventMock
+	if err := registry.DecodeInto(event, &target); err != nil {
+		t.Fatal(err)
+	}
+	if target.FullName != "John Doe" {
+		t.Errorf("FullName = %s, want John Doe", target.FullName)
+	}
+}
+
+func TestEventRegistryErrors(t *testing.T) {
+	registry := NewEventRegistry()
+	registry.MustRegister(versionedEventMock{})
+
+	event, err := NewEvent(uuid.New(), "streamName", 0, rawEventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := registry.Decode(event); !errors.Is(err, ErrUnknownEventType) {
+		t.Errorf("expected unknown event type error, got %v", err)
+	}
+
+	event.Metadata.Type = (versionedEventMock{}).GetType()
+	if err := registry.DecodeInto(event, &rawEventMock{}); !errors.Is(err, ErrEventTypeMismatch) {
+		t.Errorf("expected event type mismatch error, got %v", err)
+	}
+
+	event.Payload = []byte("{")
+	if _, err := registry.Decode(event); err == nil {
+		t.Error("expected error for malformed payload")
+	}
+
+	if err := registry.Register(duplicatedEventMock{}); err == nil {
+		t.Error("expected error when type name is already registered for other type")
+	}
+}
+
+type duplicatedEventMock struct{}
+
+func (e duplicatedEventMock) GetType() string {
+	return (versionedEventMock{}).GetType()
+}
@@ -51,3 +51,40 @@ func TestNewEventInvalidValue(t *testing.T) {
 		t.Error("Parsing value to json should fail")
 	}
 }
+
+type personalDataEventMock struct {
+	ID      uuid.UUID `json:"id"`
+	Email   string    `json:"email" pii:"true"`
+	Phone   string    `json:"phone,omitempty" pii:"true"`
+	Name    string    `pii:"true"`
+	Ignored string    `json:"-" pii:"true"`
+}
+
+func (e personalDataEventMock) GetType() string {
+	return "test.PersonalData"
+}
+
+func TestNewEventPersonalData(t *testing.T) {
+	event, err := NewEvent(uuid.New(), "streamName", 0, personalDataEventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"email", "phone", "Name"}
+	if len(event.PersonalData) != len(expected) {
+		t.Fatalf("expected personal data fields %v, got %v", expected, event.PersonalData)
+	}
+	for i, field := range expected {
+		if event.PersonalData[i] != field {
+			t.Errorf("expected personal data fields %v, got %v", expected, event.PersonalData)
+		}
+	}
+
+	event, err = NewEvent(uuid.New(), "streamName", 0, rawEventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(event.PersonalData) != 0 {
+		t.Errorf("expected no personal data fields, got %v", event.PersonalData)
+	}
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Upcaster transforms event payload from one schema version to the next one
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+// UpcasterRegistry holds upcasters of event types by schema version they upgrade from
+type UpcasterRegistry struct {
+	mtx       sync.RWMutex
+	upcasters map[string]map[int]Upcaster
+}
+
+// NewUpcasterRegistry creates empty upcaster registry
+func NewUpcasterRegistry() *UpcasterRegistry {
+	return &UpcasterRegistry{
+		upcasters: make(map[string]map[int]Upcaster),
+	}
+}
+
+// Register adds upcaster transforming payload of given event type from fromVersion to fromVersion+1
+func (r *UpcasterRegistry) Register(eventType string, fromVersion int, upcaster Upcaster) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.upcasters[eventType]; !ok {
+		r.upcasters[eventType] = make(map[int]Upcaster)
+	}
+
+	r.upcasters[eventType][fromVersion] = upcaster
+}
+
+// Upcast applies registered upcasters step by step until event payload
+// reaches the latest schema version known to the registry
+func (r *UpcasterRegistry) Upcast(event Event) (Event, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if event.Metadata.SchemaVersion < InitialSchemaVersion {
+		event.Metadata.SchemaVersion = InitialSchemaVersion
+	}
+
+	upcasters := r.upcasters[event.Metadata.Type]
+	for {
+		upcaster, ok := upcasters[event.Metadata.SchemaVersion]
+		if !ok {
+			return event, nil
+		}
+
+		payload, err := upcaster(event.Payload)
+		if err != nil {
+			return event, fmt.Errorf("could not upcast %s from schema version %d: %w", event.Metadata.Type, event.Metadata.SchemaVersion, err)
+		}
+
+		event.Payload = payload
+		event.Metadata.SchemaVersion++
+	}
+}
+
+// UpcastAll upcasts each of given events in place
+func (r *UpcasterRegistry) UpcastAll(events []Event) ([]Event, error) {
+	for i := range events {
+		e, err := r.Upcast(events[i])
+		if err != nil {
+			return nil, err
+		}
+		events[i] = e
+	}
+
+	return events, nil
+}
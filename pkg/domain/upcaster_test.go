@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type versionedEventMock struct {
+	FullName string `json:"full_name"`
+}
+
+func (e versionedEventMock) GetType() string {
+	return "test.Versioned"
+}
+
+func (e versionedEventMock) GetSchemaVersion() int {
+	return 3
+}
+
+func TestNewEventSchemaVersion(t *testing.T) {
+	event, err := NewEvent(uuid.New(), "streamName", 0, rawEventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Metadata.SchemaVersion != InitialSchemaVersion {
+		t.Errorf("expected initial schema version, got %d", event.Metadata.SchemaVersion)
+	}
+
+	event, err = NewEvent(uuid.New(), "streamName", 0, versionedEventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Metadata.SchemaVersion != 3 {
+		t.Errorf("expected schema version 3, got %d", event.Metadata.SchemaVersion)
+	}
+}
+
+func TestUpcasterRegistry(t *testing.T) {
+	registry := NewUpcasterRegistry()
+	// v1 {"name":"john"} -> v2 {"name":"john","surname":""} -> v3 {"full_name":"john"}
+	registry.Register("test.Versioned", 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		var v1 struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(payload, &v1); err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{"name": v1.Name, "surname": ""})
+	})
+	registry.Register("test.Versioned", 2, func(payload json.RawMessage) (json.RawMessage, error) {
+		var v2 struct {
+			Name    string `json:"name"`
+			Surname string `json:"surname"`
+		}
+		if err := json.Unmarshal(payload, &v2); err != nil {
+			return nil, err
+		}
+		return json.Marshal(versionedEventMock{FullName: v2.Name + v2.Surname})
+	})
+
+	tests := []struct {
+		name          string
+		schemaVersion int
+		payload       string
+	}{
+		{"unversioned legacy event", 0, `{"name":"john"}`},
+		{"v1 event", 1, `{"name":"john"}`},
+		{"v2 event", 2, `{"name":"john","surname":""}`},
+		{"current event", 3, `{"full_name":"john"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := Event{
+				Metadata: EventMetaData{Type: "test.Versioned", SchemaVersion: tt.schemaVersion},
+				Payload:  json.RawMessage(tt.payload),
+			}
+
+			upcasted, err := registry.Upcast(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if upcasted.Metadata.SchemaVersion != 3 {
+				t.Errorf("expected schema version 3, got %d", upcasted.Metadata.SchemaVersion)
+			}
+			if string(upcasted.Payload) != `{"full_name":"john"}` {
+				t.Errorf("unexpected payload %s", upcasted.Payload)
+			}
+		})
+	}
+}
+
+func TestUpcasterRegistryError(t *testing.T) {
+	registry := NewUpcasterRegistry()
+	errUpcast := errors.New("invalid payload")
+	registry.Register("test.Versioned", 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		return nil, errUpcast
+	})
+
+	_, err := registry.Upcast(Event{Metadata: EventMetaData{Type: "test.Versioned", SchemaVersion: 1}})
+	if !errors.Is(err, errUpcast) {
+		t.Errorf("expected upcaster error, got %v", err)
+	}
+
+	other := Event{Metadata: EventMetaData{Type: "test.Other", SchemaVersion: 1}, Payload: json.RawMessage(`{}`)}
+	if _, err := registry.Upcast(other); err != nil {
+		t.Errorf("events without upcasters should pass through, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+// Ack is sent by subscribers of remote event bus to reply topic of event
+// published with PublishAndAcknowledge, every handler sends receipt as soon
+// as it gets the event and another ack once it is handled
+type Ack struct {
+	HandlerID string `json:"handler_id,omitempty"`
+	Handled   bool   `json:"handled,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Probe is sent by publisher to its own reply topic to make sure it is listening before event is published
+	Probe bool `json:"probe,omitempty"`
+}
+
+// NewReplyTopic returns unique topic acknowledgements of given event type are sent to
+func NewReplyTopic(eventType string) string {
+	return fmt.Sprintf("$ack:%s:%s", eventType, uuid.New())
+}
+
+// AwaitProbe sends probes until one of them is received back,
+// remote buses may drop messages published before reply topic subscription is registered
+func AwaitProbe(ctx context.Context, acks <-chan Ack, interval time.Duration, sendProbe func(ctx context.Context) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	if err := sendProbe(ctx); err != nil {
+		return errors.Wrap(err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(fmt.Errorf("%w: reply topic is not listening: %v", application.ErrTimeout, ctx.Err()))
+		case ack, ok := <-acks:
+			if !ok {
+				return errors.New("reply topic stream was closed")
+			}
+			if ack.Probe {
+				return nil
+			}
+		case <-ticker.C:
+			if err := sendProbe(ctx); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+	}
+}
+
+// CollectAcks waits until every handler that received event has handled it
+// and no other handler received it within receiptWindow, returns grouped handler errors
+func CollectAcks(ctx context.Context, acks <-chan Ack, receiptWindow time.Duration) error {
+	pending := make(map[string]struct{})
+	var errs []string
+
+	quiet := time.NewTimer(receiptWindow)
+	defer quiet.Stop()
+	windowPassed := false
+
+	for {
+		if windowPassed && len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(fmt.Errorf("%w: %d handlers did not acknowledge event: %v", application.ErrTimeout, len(pending), ctx.Err()))
+		case <-quiet.C:
+			windowPassed = true
+		case ack, ok := <-acks:
+			if !ok {
+				return errors.New("reply topic stream was closed")
+			}
+			if ack.Probe {
+				continue
+			}
+			if !ack.Handled {
+				pending[ack.HandlerID] = struct{}{}
+				continue
+			}
+
+			delete(pending, ack.HandlerID)
+			if ack.Error != "" {
+				errs = append(errs, ack.Error)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
@@ -37,19 +37,21 @@ func (b *eventBus) Publish(parentCtx context.Context, event domain.Event) error
 	b.mtx.RLock()
 	defer b.mtx.RUnlock()
 
-	handlers, ok := b.handlers[event.Metadata.Type]
-	if !ok {
+	topics, handlers := b.topicsOf(event)
+	if handlers == 0 {
 		return nil
 	}
 
-	out := make(chan error, len(handlers))
+	out := make(chan error, handlers)
 
 	flags := executioncontext.FromContext(parentCtx)
 	ctx := executioncontext.WithFlag(context.Background(), flags)
 
 	go func() {
-		b.logger.Debug(parentCtx, "[EventBus] Publish: %s %+v\n", event.Metadata.Type, event)
-		b.messageBus.Publish(event.Metadata.Type, ctx, event, out)
+		b.logger.Debug(parentCtx, "[EventBus] Publish: %s %s\n", event.Metadata.Type, event.ID)
+		for _, topic := range topics {
+			b.messageBus.Publish(topic, ctx, event, out)
+		}
 	}()
 
 	return nil
@@ -59,22 +61,24 @@ func (b *eventBus) PublishAndAcknowledge(parentCtx context.Context, event domain
 	b.mtx.RLock()
 	defer b.mtx.RUnlock()
 
-	handlers, ok := b.handlers[event.Metadata.Type]
-	if !ok {
+	topics, handlers := b.topicsOf(event)
+	if handlers == 0 {
 		return nil
 	}
 
-	out := make(chan error, len(handlers))
+	out := make(chan error, handlers)
 
 	flags := executioncontext.FromContext(parentCtx)
 	ctx := executioncontext.WithFlag(context.Background(), flags)
 
-	b.logger.Debug(parentCtx, "[EventBus] PublishAndAcknowledge: %s %+v\n", event.Metadata.Type, event)
-	b.messageBus.Publish(event.Metadata.Type, ctx, event, out)
+	b.logger.Debug(parentCtx, "[EventBus] PublishAndAcknowledge: %s %s\n", event.Metadata.Type, event.ID)
+	for _, topic := range topics {
+		b.messageBus.Publish(topic, ctx, event, out)
+	}
 
 	var errs []error
 
-	for j := 1; j <= len(handlers); j++ {
+	for j := 1; j <= handlers; j++ {
 		if err := <-out; err != nil {
 			errs = append(errs, err)
 		}
@@ -93,13 +97,19 @@ func (b *eventBus) PublishAndAcknowledge(parentCtx context.Context, event domain
 	return nil
 }
 
+// Subscribe registers handler of event type or of a pattern, see eventbus.Topics
 func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	if err := eventbus.ValidateTopic(eventType); err != nil {
+		return errors.Wrap(err)
+	}
+
 	b.logger.Info(ctx, "[EventBus] Subscribe: %s\n", eventType)
 
-	handler := func(ctx context.Context, event domain.Event, out chan<- error) {
-		b.logger.Debug(ctx, "[EventHandler] %s: %s\n", eventType, event.Payload)
+	// message bus matches handlers by value and type, the same eventHandler value has to be passed to Unsubscribe
+	var handler eventHandler = func(ctx context.Context, event domain.Event, out chan<- error) {
+		b.logger.Debug(ctx, "[EventHandler] %s: %s\n", eventType, event.ID)
 
-		if err := fn(ctx, event); err != nil {
+		if err := fn(domain.ContextWithCause(ctx, event), event); err != nil {
 			b.logger.Error(ctx, "[EventHandler] %s: %v\n", eventType, err)
 			out <- errors.Wrap(err)
 		} else {
@@ -115,6 +125,9 @@ func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.
 	if _, ok := b.handlers[eventType]; !ok {
 		b.handlers[eventType] = make(map[reflect.Value]eventHandler)
 	}
+	if _, ok := b.handlers[eventType][rv]; ok {
+		return nil
+	}
 
 	b.handlers[eventType][rv] = handler
 
@@ -142,3 +155,17 @@ func (b *eventBus) Unsubscribe(ctx context.Context, eventType string, fn eventbu
 
 	return nil
 }
+
+// topicsOf returns topics event is delivered to that have handlers and total number of their handlers
+func (b *eventBus) topicsOf(event domain.Event) ([]string, int) {
+	var topics []string
+	var handlers int
+	for _, topic := range eventbus.Topics(event) {
+		if topicHandlers, ok := b.handlers[topic]; ok {
+			topics = append(topics, topic)
+			handlers += len(topicHandlers)
+		}
+	}
+
+	return topics, handlers
+}
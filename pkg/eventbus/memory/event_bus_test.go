@@ -3,12 +3,14 @@ package memory
 import (
 	"context"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
 	"github.com/vardius/go-api-boilerplate/pkg/log"
 )
 
@@ -95,3 +97,127 @@ func TestUnsubscribe(t *testing.T) {
 
 	<-ctx.Done()
 }
+
+func TestUnsubscribeOneOfHandlers(t *testing.T) {
+	ctx := context.Background()
+	bus := New(runtime.NumCPU(), log.New("development"))
+
+	var mtx sync.Mutex
+	calls := make(map[string]int)
+	handler := func(name string) eventbus.EventHandler {
+		return func(ctx context.Context, event domain.Event) error {
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			calls[name]++
+
+			return nil
+		}
+	}
+
+	kept := handler("kept")
+	removed := handler("removed")
+
+	if err := bus.Subscribe(ctx, "event", kept); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Subscribe(ctx, "event", removed); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Unsubscribe(ctx, "event", removed); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		e, err := domain.NewEvent(uuid.New(), "event", 0, eventMock{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := bus.PublishAndAcknowledge(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if calls["kept"] != 10 || calls["removed"] != 0 {
+		t.Errorf("expected only subscribed handler to be called, got: %v", calls)
+	}
+}
+
+func TestHandlerContextCausation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	bus := New(runtime.NumCPU(), log.New("development"))
+
+	e, err := domain.NewEvent(uuid.New(), "event", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Metadata.CorrelationID = uuid.New().String()
+
+	if err := bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+		caused, err := domain.NewEventFromContext(ctx, uuid.New(), "event", 0, eventMock{})
+		if err != nil {
+			return err
+		}
+
+		if caused.Metadata.CorrelationID != e.Metadata.CorrelationID {
+			t.Errorf("CorrelationID = %s, want %s", caused.Metadata.CorrelationID, e.Metadata.CorrelationID)
+		}
+		if caused.Metadata.CausationID != e.ID.String() {
+			t.Errorf("CausationID = %s, want %s", caused.Metadata.CausationID, e.ID)
+		}
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.PublishAndAcknowledge(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubscribePattern(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	bus := New(runtime.NumCPU(), log.New("development"))
+
+	e, err := domain.NewEvent(uuid.New(), "stream", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mtx sync.Mutex
+	var handled []string
+	for _, topic := range []string{"event", eventbus.AllEvents, eventbus.StreamTopic("stream"), eventbus.StreamTopic("other")} {
+		topic := topic
+		if err := bus.Subscribe(ctx, topic, func(ctx context.Context, event domain.Event) error {
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			handled = append(handled, topic)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := bus.PublishAndAcknowledge(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(handled) != 3 {
+		t.Errorf("expected event to be handled by type, stream and all events handlers, got: %v", handled)
+	}
+
+	if err := bus.Subscribe(ctx, "even*", func(ctx context.Context, event domain.Event) error {
+		return nil
+	}); err == nil {
+		t.Error("expected invalid topic error")
+	}
+}
@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"context"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// NewOrdered creates memory event bus delivering events of the same stream in order they were published,
+// events are partitioned by stream id, each partition handles one event at a time
+// so different streams are still handled in parallel by up to partitions events at once
+func NewOrdered(partitions int, log *log.Logger) eventbus.EventBus {
+	if partitions < 1 {
+		partitions = 1
+	}
+
+	b := &orderedEventBus{
+		logger:     log,
+		handlers:   make(map[string]map[reflect.Value]eventbus.EventHandler),
+		partitions: make([]*partition, partitions),
+	}
+
+	for i := range b.partitions {
+		p := &partition{
+			bus:    b,
+			signal: make(chan struct{}, 1),
+		}
+		b.partitions[i] = p
+
+		go p.run()
+	}
+
+	return b
+}
+
+type delivery struct {
+	ctx      context.Context
+	event    domain.Event
+	handlers []eventbus.EventHandler
+	// out receives handler errors, nil when published without acknowledgement
+	out chan<- error
+}
+
+type partitionKey struct{}
+
+// partition handles its deliveries one by one, queue is not bounded
+// so handlers publishing new events never block on their own partition
+type partition struct {
+	bus    *orderedEventBus
+	mtx    sync.Mutex
+	queue  []delivery
+	signal chan struct{}
+}
+
+func (p *partition) push(d delivery) {
+	p.mtx.Lock()
+	p.queue = append(p.queue, d)
+	p.mtx.Unlock()
+
+	select {
+	case p.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (p *partition) run() {
+	for range p.signal {
+		for {
+			p.mtx.Lock()
+			if len(p.queue) == 0 {
+				p.mtx.Unlock()
+				break
+			}
+			d := p.queue[0]
+			p.queue[0] = delivery{}
+			p.queue = p.queue[1:]
+			p.mtx.Unlock()
+
+			p.deliver(d)
+		}
+	}
+}
+
+func (p *partition) deliver(d delivery) {
+	ctx := context.WithValue(d.ctx, partitionKey{}, p)
+
+	var wg sync.WaitGroup
+	for _, fn := range d.handlers {
+		wg.Add(1)
+		go func(fn eventbus.EventHandler) {
+			defer wg.Done()
+
+			p.bus.logger.Debug(ctx, "[EventHandler] %s: %s\n", d.event.Metadata.Type, d.event.ID)
+
+			err := fn(domain.ContextWithCause(ctx, d.event), d.event)
+			if err != nil {
+				p.bus.logger.Error(ctx, "[EventHandler] %s: %v\n", d.event.Metadata.Type, err)
+				err = errors.Wrap(err)
+			}
+			if d.out != nil {
+				d.out <- err
+			}
+		}(fn)
+	}
+
+	wg.Wait()
+}
+
+type orderedEventBus struct {
+	logger     *log.Logger
+	mtx        sync.RWMutex
+	handlers   map[string]map[reflect.Value]eventbus.EventHandler
+	partitions []*partition
+}
+
+func (b *orderedEventBus) Publish(parentCtx context.Context, event domain.Event) error {
+	handlers := b.handlersOf(event)
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	b.logger.Debug(parentCtx, "[EventBus] Publish: %s %s\n", event.Metadata.Type, event.ID)
+
+	b.partitionOf(event).push(delivery{
+		ctx:      b.deliveryContext(parentCtx),
+		event:    event,
+		handlers: handlers,
+	})
+
+	return nil
+}
+
+// PublishAndAcknowledge waits until event is handled by all handlers,
+// events published before it to the same stream are handled first
+func (b *orderedEventBus) PublishAndAcknowledge(parentCtx context.Context, event domain.Event) error {
+	handlers := b.handlersOf(event)
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	b.logger.Debug(parentCtx, "[EventBus] PublishAndAcknowledge: %s %s\n", event.Metadata.Type, event.ID)
+
+	out := make(chan error, len(handlers))
+	d := delivery{
+		ctx:      b.deliveryContext(parentCtx),
+		event:    event,
+		handlers: handlers,
+		out:      out,
+	}
+
+	p := b.partitionOf(event)
+	if current, ok := parentCtx.Value(partitionKey{}).(*partition); ok && current == p {
+		// called by a handler of the same partition, waiting for the queue would never end
+		p.deliver(d)
+	} else {
+		p.push(d)
+	}
+
+	var errs []string
+	for range handlers {
+		if err := <-out; err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// Subscribe registers handler of event type or of a pattern, see eventbus.Topics
+func (b *orderedEventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	if err := eventbus.ValidateTopic(eventType); err != nil {
+		return errors.Wrap(err)
+	}
+
+	b.logger.Info(ctx, "[EventBus] Subscribe: %s\n", eventType)
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if _, ok := b.handlers[eventType]; !ok {
+		b.handlers[eventType] = make(map[reflect.Value]eventbus.EventHandler)
+	}
+
+	b.handlers[eventType][reflect.ValueOf(fn)] = fn
+
+	return nil
+}
+
+func (b *orderedEventBus) Unsubscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	b.logger.Info(ctx, "[EventBus] Unsubscribe: %s\n", eventType)
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if topicHandlers, ok := b.handlers[eventType]; ok {
+		delete(topicHandlers, reflect.ValueOf(fn))
+		if len(topicHandlers) == 0 {
+			delete(b.handlers, eventType)
+		}
+	}
+
+	return nil
+}
+
+// handlersOf returns handlers of every topic event is delivered to
+func (b *orderedEventBus) handlersOf(event domain.Event) []eventbus.EventHandler {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	var handlers []eventbus.EventHandler
+	for _, topic := range eventbus.Topics(event) {
+		for _, fn := range b.handlers[topic] {
+			handlers = append(handlers, fn)
+		}
+	}
+
+	return handlers
+}
+
+func (b *orderedEventBus) partitionOf(event domain.Event) *partition {
+	h := fnv.New32a()
+	_, _ = h.Write(event.Metadata.StreamID[:])
+
+	return b.partitions[h.Sum32()%uint32(len(b.partitions))]
+}
+
+// deliveryContext keeps only execution flags of publisher context, handlers outlive the request
+func (b *orderedEventBus) deliveryContext(parentCtx context.Context) context.Context {
+	flags := executioncontext.FromContext(parentCtx)
+
+	return executioncontext.WithFlag(context.Background(), flags)
+}
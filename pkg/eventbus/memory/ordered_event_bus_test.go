@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+type otherEventMock struct{}
+
+func (e otherEventMock) GetType() string {
+	return "other"
+}
+
+func TestOrderedDeliveryWithinStream(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bus := NewOrdered(4, log.New("development"))
+
+	var (
+		mtx      sync.Mutex
+		versions []int
+		wg       sync.WaitGroup
+	)
+	handler := func(ctx context.Context, event domain.Event) error {
+		defer wg.Done()
+
+		// later events are handled faster, unordered delivery would reorder them
+		time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+
+		mtx.Lock()
+		versions = append(versions, event.Metadata.StreamVersion)
+		mtx.Unlock()
+
+		return nil
+	}
+
+	if err := bus.Subscribe(ctx, "event", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Subscribe(ctx, "other", handler); err != nil {
+		t.Fatal(err)
+	}
+
+	streamID := uuid.New()
+	for version := 0; version < 50; version++ {
+		var rawEvent domain.RawEvent = eventMock{}
+		if version%2 == 1 {
+			rawEvent = otherEventMock{}
+		}
+
+		e, err := domain.NewEvent(streamID, "stream", version, rawEvent, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		if err := bus.Publish(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wg.Wait()
+
+	for i, version := range versions {
+		if version != i {
+			t.Fatalf("events of the stream were handled out of order: %v", versions)
+		}
+	}
+}
+
+func TestOrderedDeliveryAcrossStreamsInParallel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bus := NewOrdered(2, log.New("development")).(*orderedEventBus)
+
+	first, err := domain.NewEvent(uuid.New(), "stream", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// find event of a stream handled by the other partition
+	var second domain.Event
+	for {
+		second, err = domain.NewEvent(uuid.New(), "stream", 0, eventMock{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bus.partitionOf(second) != bus.partitionOf(first) {
+			break
+		}
+	}
+
+	secondHandled := make(chan struct{})
+	if err := bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+		if event.ID == first.ID {
+			select {
+			case <-secondHandled:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("stream was blocked by another one")
+			}
+		}
+
+		close(secondHandled)
+
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.Publish(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.PublishAndAcknowledge(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOrderedPublishAndAcknowledge(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bus := NewOrdered(4, log.New("development"))
+
+	streamID := uuid.New()
+	nested, err := domain.NewEvent(streamID, "stream", 1, otherEventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+		// handler of the same stream waiting for another event must not block its partition
+		return bus.PublishAndAcknowledge(ctx, nested)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Subscribe(ctx, "other", func(ctx context.Context, event domain.Event) error {
+		return fmt.Errorf("other handler failed")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := domain.NewEvent(streamID, "stream", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = bus.PublishAndAcknowledge(ctx, e)
+	if err == nil || !strings.Contains(err.Error(), "other handler failed") {
+		t.Errorf("expected handler error, got: %v", err)
+	}
+}
@@ -0,0 +1,4 @@
+/*
+Package middleware provides event handler middleware
+*/
+package middleware
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// EventBus wraps event bus applying middleware to every handler subscribed
+type EventBus struct {
+	eventbus.EventBus
+	middleware Middleware
+
+	mtx sync.Mutex
+	// wrapped handlers by event type and original handler
+	handlers map[string]map[reflect.Value]eventbus.EventHandler
+}
+
+// NewEventBus wraps event bus, middleware is applied in the given order, the first one is the outermost
+func NewEventBus(bus eventbus.EventBus, middlewares ...Middleware) *EventBus {
+	return &EventBus{
+		EventBus:   bus,
+		middleware: Chain(middlewares...),
+		handlers:   make(map[string]map[reflect.Value]eventbus.EventHandler),
+	}
+}
+
+// Subscribe handler wrapped with middleware
+func (b *EventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	wrapped := b.middleware(fn)
+
+	b.mtx.Lock()
+	if _, ok := b.handlers[eventType]; !ok {
+		b.handlers[eventType] = make(map[reflect.Value]eventbus.EventHandler)
+	}
+	b.handlers[eventType][reflect.ValueOf(fn)] = wrapped
+	b.mtx.Unlock()
+
+	if err := b.EventBus.Subscribe(ctx, eventType, wrapped); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// Unsubscribe handler
+func (b *EventBus) Unsubscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	rv := reflect.ValueOf(fn)
+
+	b.mtx.Lock()
+	wrapped, ok := b.handlers[eventType][rv]
+	delete(b.handlers[eventType], rv)
+	b.mtx.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	if err := b.EventBus.Unsubscribe(ctx, eventType, wrapped); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
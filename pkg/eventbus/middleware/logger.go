@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// Logger logs start, end and error of every handled event
+func Logger(logger *log.Logger) Middleware {
+	m := func(next eventbus.EventHandler) eventbus.EventHandler {
+		fn := func(ctx context.Context, event domain.Event) error {
+			now := time.Now()
+
+			logger.Info(ctx, "[EventHandler] Start: %s %s -> %s\n",
+				event.Metadata.Type, event.ID,
+				event.Metadata.StreamID,
+			)
+
+			if err := next(ctx, event); err != nil {
+				logger.Error(ctx, "[EventHandler] Failed: %s %s -> %s (%s): %v\n",
+					event.Metadata.Type, event.ID,
+					event.Metadata.StreamID,
+					time.Since(now),
+					err,
+				)
+
+				return err
+			}
+
+			logger.Info(ctx, "[EventHandler] End: %s %s -> %s (%s)\n",
+				event.Metadata.Type, event.ID,
+				event.Metadata.StreamID,
+				time.Since(now),
+			)
+
+			return nil
+		}
+
+		return fn
+	}
+
+	return m
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// m contains the global program counters for event handlers, keyed by event type.
+var m = struct {
+	handled  *expvar.Map
+	failed   *expvar.Map
+	duration *expvar.Map
+}{
+	handled:  expvar.NewMap("eventsHandled"),
+	failed:   expvar.NewMap("eventsFailed"),
+	duration: expvar.NewMap("eventsHandlingSeconds"),
+}
+
+// Metrics updates program counters of handled and failed events and total time spent handling them
+func Metrics() Middleware {
+	mw := func(next eventbus.EventHandler) eventbus.EventHandler {
+		fn := func(ctx context.Context, event domain.Event) error {
+			now := time.Now()
+
+			err := next(ctx, event)
+
+			m.duration.AddFloat(event.Metadata.Type, time.Since(now).Seconds())
+			if err != nil {
+				m.failed.Add(event.Metadata.Type, 1)
+			} else {
+				m.handled.Add(event.Metadata.Type, 1)
+			}
+
+			return err
+		}
+
+		return fn
+	}
+
+	return mw
+}
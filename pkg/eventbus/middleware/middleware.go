@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// Middleware wraps event handler
+type Middleware func(next eventbus.EventHandler) eventbus.EventHandler
+
+// Chain composes middleware into one, the first middleware is the outermost
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next eventbus.EventHandler) eventbus.EventHandler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+
+		return next
+	}
+}
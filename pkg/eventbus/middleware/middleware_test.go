@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"context"
+	systemErrors "errors"
+	"expvar"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/metadata"
+)
+
+type eventMock struct{}
+
+func (e eventMock) GetType() string {
+	return "event"
+}
+
+func newEvent(t *testing.T) domain.Event {
+	t.Helper()
+
+	e, err := domain.NewEvent(uuid.New(), "event", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next eventbus.EventHandler) eventbus.EventHandler {
+			return func(ctx context.Context, event domain.Event) error {
+				calls = append(calls, name)
+				return next(ctx, event)
+			}
+		}
+	}
+
+	h := Chain(record("first"), record("second"))(func(ctx context.Context, event domain.Event) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	if err := h(context.Background(), newEvent(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(calls, ","); got != "first,second,handler" {
+		t.Errorf("unexpected call order: %s", got)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	h := Timeout(10 * time.Millisecond)(func(ctx context.Context, event domain.Event) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if err := h(context.Background(), newEvent(t)); !systemErrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got: %v", err)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	h := Recover(log.New("development"))(func(ctx context.Context, event domain.Event) error {
+		panic("boom")
+	})
+
+	err := h(context.Background(), newEvent(t))
+	if !systemErrors.Is(err, application.ErrInternal) {
+		t.Errorf("expected internal error, got: %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	attempts := 0
+	h := Retry(3, func(attempt int) time.Duration { return time.Millisecond }, log.New("development"))(func(ctx context.Context, event domain.Event) error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("attempt %d failed", attempts)
+		}
+		return nil
+	})
+
+	if err := h(context.Background(), newEvent(t)); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got: %d", attempts)
+	}
+}
+
+func TestRetryReturnsLastError(t *testing.T) {
+	attempts := 0
+	h := Retry(2, func(attempt int) time.Duration { return 0 }, log.New("development"))(func(ctx context.Context, event domain.Event) error {
+		attempts++
+		return fmt.Errorf("attempt %d failed", attempts)
+	})
+
+	err := h(context.Background(), newEvent(t))
+	if err == nil || err.Error() != "attempt 2 failed" {
+		t.Errorf("expected last error, got: %v", err)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	h := Metrics()(func(ctx context.Context, event domain.Event) error {
+		return nil
+	})
+
+	event := newEvent(t)
+	event.Metadata.Type = "metrics.event"
+
+	var before int64
+	if v, ok := m.handled.Get("metrics.event").(*expvar.Int); ok {
+		before = v.Value()
+	}
+
+	if err := h(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := m.handled.Get("metrics.event").(*expvar.Int); !ok || v.Value() != before+1 {
+		t.Errorf("expected handled counter to be incremented, got: %v", m.handled.Get("metrics.event"))
+	}
+}
+
+func TestTrace(t *testing.T) {
+	event := newEvent(t)
+	event.Metadata.CorrelationID = "correlation"
+
+	h := Trace()(func(ctx context.Context, event domain.Event) error {
+		m, ok := metadata.FromContext(ctx)
+		if !ok {
+			return fmt.Errorf("metadata missing")
+		}
+		if m.TraceID != "correlation" || m.CausationID != event.ID.String() {
+			return fmt.Errorf("unexpected metadata: %+v", m)
+		}
+		return nil
+	})
+
+	if err := h(context.Background(), event); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEventBus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	c := make(chan error, 1)
+	bus := NewEventBus(memory.New(runtime.NumCPU(), log.New("development")), Recover(log.New("development")))
+
+	handler := func(ctx context.Context, event domain.Event) error {
+		defer func() { c <- nil }()
+		panic("boom")
+	}
+
+	if err := bus.Subscribe(ctx, "event", handler); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.PublishAndAcknowledge(ctx, newEvent(t)); err == nil || !strings.Contains(err.Error(), "recovered from panic") {
+		t.Errorf("expected recovered panic error, got: %v", err)
+	}
+
+	if err := bus.Unsubscribe(ctx, "event", handler); err != nil {
+		t.Fatal(err)
+	}
+	<-c
+
+	if err := bus.PublishAndAcknowledge(ctx, newEvent(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-c:
+		t.Error("handler was called after unsubscribe")
+	default:
+	}
+}
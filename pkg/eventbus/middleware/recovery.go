@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// Recover recovers from handler panic returning it as an internal error
+func Recover(logger *log.Logger) Middleware {
+	m := func(next eventbus.EventHandler) eventbus.EventHandler {
+		fn := func(ctx context.Context, event domain.Event) (err error) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					logger.Critical(ctx, "[EventHandler] Recovered in %v\n%s\n", rec, debug.Stack())
+
+					err = errors.Wrap(fmt.Errorf("%w: recovered from panic while handling %s: %v", application.ErrInternal, event.Metadata.Type, rec))
+				}
+			}()
+
+			return next(ctx, event)
+		}
+
+		return fn
+	}
+
+	return m
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// Retry calls handler up to maxAttempts times waiting backoff(attempt) after each failed attempt,
+// e.g. Retry(policy.MaxAttempts, policy.Backoff, logger) for deadletter.RetryPolicy,
+// returns the last error if all attempts failed or context is done
+func Retry(maxAttempts int, backoff func(attempt int) time.Duration, logger *log.Logger) Middleware {
+	m := func(next eventbus.EventHandler) eventbus.EventHandler {
+		fn := func(ctx context.Context, event domain.Event) error {
+			for attempt := 1; ; attempt++ {
+				err := next(ctx, event)
+				if err == nil || attempt >= maxAttempts {
+					return err
+				}
+
+				logger.Warning(ctx, "[EventHandler] Failed to handle %s (attempt %d): %v\n", event.Metadata.Type, attempt, err)
+
+				select {
+				case <-ctx.Done():
+					return err
+				case <-time.After(backoff(attempt)):
+				}
+			}
+		}
+
+		return fn
+	}
+
+	return m
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// Timeout cancels handler context after given duration
+func Timeout(timeout time.Duration) Middleware {
+	m := func(next eventbus.EventHandler) eventbus.EventHandler {
+		fn := func(parentCtx context.Context, event domain.Event) error {
+			ctx, cancel := context.WithTimeout(parentCtx, timeout)
+			defer cancel()
+
+			return next(ctx, event)
+		}
+
+		return fn
+	}
+
+	return m
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// Trace makes sure handler context carries request metadata with trace id of the event,
+// buses set it when dispatching but handlers called directly (e.g. when replaying events) would log without it
+func Trace() Middleware {
+	m := func(next eventbus.EventHandler) eventbus.EventHandler {
+		fn := func(ctx context.Context, event domain.Event) error {
+			return next(domain.ContextWithCause(ctx, event), event)
+		}
+
+		return fn
+	}
+
+	return m
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	pubsubproto "github.com/vardius/pubsub/v2/proto"
 
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
@@ -20,15 +21,30 @@ import (
 func New(handlerTimeout time.Duration, pubsub pubsubproto.PubSubClient, log *log.Logger) eventbus.EventBus {
 	return &eventBus{
 		handlerTimeout:      handlerTimeout,
+		ackTimeout:          handlerTimeout + ackGracePeriod,
+		receiptWindow:       defaultReceiptWindow,
+		probeInterval:       defaultProbeInterval,
 		pubsub:              pubsub,
 		logger:              log,
 		unsubscribeChannels: make(map[reflect.Value]chan struct{}),
 	}
 }
 
+const (
+	// ackGracePeriod is added to handler timeout when waiting for acknowledgements
+	ackGracePeriod = time.Second
+	// defaultReceiptWindow is how long publisher waits for handlers to confirm they received event,
+	// handlers that receive event later are not waited for
+	defaultReceiptWindow = 100 * time.Millisecond
+	// defaultProbeInterval is how often publisher probes its reply topic until subscription is registered
+	defaultProbeInterval = 10 * time.Millisecond
+)
+
 type dto struct {
 	Event           domain.Event       `json:"event"`
 	RequestMetadata *metadata.Metadata `json:"request_metadata,omitempty"`
+	// ReplyTopic is set by PublishAndAcknowledge, handlers send acknowledgements to it
+	ReplyTopic string `json:"reply_topic,omitempty"`
 }
 
 // EventBus allow to publish/subscribe to events, allow to push/pull events
@@ -36,6 +52,9 @@ type dto struct {
 // use Publish/Subscribe if you want every handler to be notified of the event
 type eventBus struct {
 	handlerTimeout time.Duration
+	ackTimeout     time.Duration
+	receiptWindow  time.Duration
+	probeInterval  time.Duration
 	pubsub         pubsubproto.PubSubClient
 	logger         *log.Logger
 
@@ -45,6 +64,10 @@ type eventBus struct {
 
 // Subscribe registers handler to be notified of every event published
 func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	if err := eventbus.ValidateTopic(eventType); err != nil {
+		return errors.Wrap(err)
+	}
+
 	stream, err := b.pubsub.Subscribe(ctx, &pubsubproto.SubscribeRequest{
 		Topic: eventType,
 	})
@@ -54,6 +77,7 @@ func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.
 
 	b.logger.Info(stream.Context(), "[EventBus] Subscribe: %s\n", eventType)
 
+	handlerID := uuid.New().String()
 	rv := reflect.ValueOf(fn)
 	unsubscribeCh := make(chan struct{}, 1)
 
@@ -74,7 +98,7 @@ func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.
 				return errors.Wrap(err)
 			}
 
-			if err := b.dispatchEvent(resp.GetPayload(), fn); err != nil {
+			if err := b.dispatchEvent(resp.GetPayload(), handlerID, fn); err != nil {
 				return errors.Wrap(err)
 			}
 		}
@@ -83,33 +107,65 @@ func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.
 
 // Publish sends event to every client subscribed
 func (b *eventBus) Publish(ctx context.Context, event domain.Event) error {
-	o := dto{
-		Event: event,
-	}
+	return b.publish(ctx, event, "")
+}
 
-	if m, ok := metadata.FromContext(ctx); ok {
-		o.RequestMetadata = m
-	}
+// PublishAndAcknowledge sends event to every client subscribed and waits until it is handled,
+// every handler that received event within receipt window is waited for, returns grouped handler errors
+func (b *eventBus) PublishAndAcknowledge(parentCtx context.Context, event domain.Event) error {
+	ctx, cancel := context.WithTimeout(parentCtx, b.ackTimeout)
+	defer cancel()
 
-	payload, err := json.Marshal(o)
+	replyTopic := eventbus.NewReplyTopic(event.Metadata.Type)
+
+	stream, err := b.pubsub.Subscribe(ctx, &pubsubproto.SubscribeRequest{
+		Topic: replyTopic,
+	})
 	if err != nil {
 		return errors.Wrap(err)
 	}
 
-	b.logger.Debug(ctx, "[EventBus] Publish: %s %s\n", event.Metadata.Type, payload)
+	acks := make(chan eventbus.Ack)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				// stream is closed when context is done, collector reports timeout in that case
+				if ctx.Err() == nil {
+					close(acks)
+				}
+				return
+			}
+
+			var ack eventbus.Ack
+			if err := json.Unmarshal(resp.GetPayload(), &ack); err != nil {
+				b.logger.Warning(ctx, "[EventBus] Invalid acknowledgement: %s %v\n", replyTopic, err)
+				continue
+			}
 
-	if _, err := b.pubsub.Publish(ctx, &pubsubproto.PublishRequest{
-		Topic:   event.Metadata.Type,
-		Payload: payload,
+			select {
+			case acks <- ack:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := eventbus.AwaitProbe(ctx, acks, b.probeInterval, func(ctx context.Context) error {
+		return b.sendAck(ctx, replyTopic, eventbus.Ack{Probe: true})
 	}); err != nil {
 		return errors.Wrap(err)
 	}
 
-	return nil
-}
+	if err := b.publish(ctx, event, replyTopic); err != nil {
+		return errors.Wrap(err)
+	}
 
-func (b *eventBus) PublishAndAcknowledge(ctx context.Context, event domain.Event) error {
-	panic("not implemented")
+	if err := eventbus.CollectAcks(ctx, acks, b.receiptWindow); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
 }
 
 // Unsubscribe will unsubscribe after next event handler because stream.Recv() is blocking
@@ -125,7 +181,53 @@ func (b *eventBus) Unsubscribe(ctx context.Context, eventType string, fn eventbu
 	return nil
 }
 
-func (b *eventBus) dispatchEvent(payload []byte, fn eventbus.EventHandler) error {
+func (b *eventBus) publish(ctx context.Context, event domain.Event, replyTopic string) error {
+	o := dto{
+		Event:      event,
+		ReplyTopic: replyTopic,
+	}
+
+	if m, ok := metadata.FromContext(ctx); ok {
+		o.RequestMetadata = m
+	}
+
+	payload, err := json.Marshal(o)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	b.logger.Debug(ctx, "[EventBus] Publish: %s %s\n", event.Metadata.Type, event.ID)
+
+	// event is sent to topics of every pattern matching it, see eventbus.Topics
+	for _, topic := range eventbus.Topics(event) {
+		if _, err := b.pubsub.Publish(ctx, &pubsubproto.PublishRequest{
+			Topic:   topic,
+			Payload: payload,
+		}); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+func (b *eventBus) sendAck(ctx context.Context, replyTopic string, ack eventbus.Ack) error {
+	payload, err := json.Marshal(ack)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if _, err := b.pubsub.Publish(ctx, &pubsubproto.PublishRequest{
+		Topic:   replyTopic,
+		Payload: payload,
+	}); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (b *eventBus) dispatchEvent(payload []byte, handlerID string, fn eventbus.EventHandler) error {
 	ctx, cancel := context.WithTimeout(context.Background(), b.handlerTimeout)
 	defer cancel()
 
@@ -138,7 +240,30 @@ func (b *eventBus) dispatchEvent(payload []byte, fn eventbus.EventHandler) error
 		ctx = metadata.ContextWithMetadata(ctx, o.RequestMetadata)
 	}
 
-	b.logger.Debug(ctx, "[EventBus] Dispatch Event: %s %s\n", o.Event.Metadata.Type, o.Event.Payload)
+	b.logger.Debug(ctx, "[EventBus] Dispatch Event: %s %s\n", o.Event.Metadata.Type, o.Event.ID)
+
+	if o.ReplyTopic == "" {
+		return fn(domain.ContextWithCause(ctx, o.Event), o.Event)
+	}
+
+	if err := b.sendAck(ctx, o.ReplyTopic, eventbus.Ack{HandlerID: handlerID}); err != nil {
+		b.logger.Warning(ctx, "[EventBus] Could not acknowledge receipt: %s %v\n", o.Event.Metadata.Type, err)
+	}
+
+	handlerErr := fn(domain.ContextWithCause(ctx, o.Event), o.Event)
+
+	ack := eventbus.Ack{HandlerID: handlerID, Handled: true}
+	if handlerErr != nil {
+		ack.Error = handlerErr.Error()
+	}
+
+	// handler may have used up its context, acknowledgement is sent with a fresh one
+	ackCtx, ackCancel := context.WithTimeout(context.Background(), b.handlerTimeout)
+	defer ackCancel()
+
+	if err := b.sendAck(ackCtx, o.ReplyTopic, ack); err != nil {
+		b.logger.Warning(ctx, "[EventBus] Could not acknowledge event: %s %v\n", o.Event.Metadata.Type, err)
+	}
 
-	return fn(ctx, o.Event)
+	return handlerErr
 }
@@ -0,0 +1,245 @@
+package pubsub
+
+import (
+	"context"
+	systemErrors "errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/google/uuid"
+	pubsubproto "github.com/vardius/pubsub/v2/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+type eventMock struct{}
+
+func (e eventMock) GetType() string {
+	return "event"
+}
+
+// server is an in-process pubsub server delivering every message to every subscriber of a topic
+type server struct {
+	mtx         sync.RWMutex
+	subscribers map[string]map[chan []byte]struct{}
+}
+
+func (s *server) Publish(ctx context.Context, r *pubsubproto.PublishRequest) (*empty.Empty, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	for ch := range s.subscribers[r.GetTopic()] {
+		ch <- r.GetPayload()
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s *server) Subscribe(r *pubsubproto.SubscribeRequest, stream pubsubproto.PubSub_SubscribeServer) error {
+	ch := make(chan []byte, 100)
+
+	s.mtx.Lock()
+	if _, ok := s.subscribers[r.GetTopic()]; !ok {
+		s.subscribers[r.GetTopic()] = make(map[chan []byte]struct{})
+	}
+	s.subscribers[r.GetTopic()][ch] = struct{}{}
+	s.mtx.Unlock()
+
+	defer func() {
+		s.mtx.Lock()
+		delete(s.subscribers[r.GetTopic()], ch)
+		s.mtx.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case payload := <-ch:
+			if err := stream.Send(&pubsubproto.SubscribeResponse{Payload: payload}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *server) waitForSubscribers(t *testing.T, topic string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mtx.RLock()
+		count := len(s.subscribers[topic])
+		s.mtx.RUnlock()
+
+		if count >= n {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("expected %d subscribers of %s", n, topic)
+}
+
+func newTestBus(t *testing.T) (*eventBus, *server) {
+	t.Helper()
+
+	srv := &server{subscribers: make(map[string]map[chan []byte]struct{})}
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	pubsubproto.RegisterPubSubServer(grpcServer, srv)
+
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+	})
+
+	bus := New(time.Second, pubsubproto.NewPubSubClient(conn), log.New("development"))
+
+	return bus.(*eventBus), srv
+}
+
+func newEvent(t *testing.T) domain.Event {
+	t.Helper()
+
+	e, err := domain.NewEvent(uuid.New(), "event", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestPublishAndAcknowledge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus, srv := newTestBus(t)
+
+	var handled int32
+	for i := 0; i < 3; i++ {
+		go bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&handled, 1)
+			return nil
+		})
+	}
+	srv.waitForSubscribers(t, "event", 3)
+
+	if err := bus.PublishAndAcknowledge(ctx, newEvent(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt32(&handled); n != 3 {
+		t.Errorf("expected every handler to handle event before acknowledgement, handled: %d", n)
+	}
+}
+
+func TestPublishAndAcknowledgeHandlerErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus, srv := newTestBus(t)
+
+	for i := 0; i < 3; i++ {
+		i := i
+		go bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+			if i == 0 {
+				return nil
+			}
+			return fmt.Errorf("handler %d failed", i)
+		})
+	}
+	srv.waitForSubscribers(t, "event", 3)
+
+	err := bus.PublishAndAcknowledge(ctx, newEvent(t))
+	if err == nil {
+		t.Fatal("expected handler errors")
+	}
+
+	for _, expected := range []string{"handler 1 failed", "handler 2 failed"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to contain %q, got: %v", expected, err)
+		}
+	}
+}
+
+func TestPublishAndAcknowledgeWithoutSubscribers(t *testing.T) {
+	bus, _ := newTestBus(t)
+
+	if err := bus.PublishAndAcknowledge(context.Background(), newEvent(t)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishAndAcknowledgeTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus, srv := newTestBus(t)
+	bus.ackTimeout = 200 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+
+	go bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+		<-release
+		return nil
+	})
+	srv.waitForSubscribers(t, "event", 1)
+
+	err := bus.PublishAndAcknowledge(ctx, newEvent(t))
+	if !systemErrors.Is(err, application.ErrTimeout) {
+		t.Errorf("expected timeout error, got: %v", err)
+	}
+}
+
+func TestPublishDoesNotRequestAcknowledgement(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus, srv := newTestBus(t)
+
+	c := make(chan error, 1)
+	go bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+		c <- nil
+		return nil
+	})
+	srv.waitForSubscribers(t, "event", 1)
+
+	if err := bus.Publish(ctx, newEvent(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-c:
+	case <-time.After(time.Second):
+		t.Fatal("event was not handled")
+	}
+
+	srv.mtx.RLock()
+	defer srv.mtx.RUnlock()
+	if len(srv.subscribers) != 1 {
+		t.Errorf("expected no reply topics, got: %d topics", len(srv.subscribers))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	pushpullproto "github.com/vardius/pushpull/proto"
 
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
@@ -20,15 +21,30 @@ import (
 func New(handlerTimeout time.Duration, client pushpullproto.PushPullClient, log *log.Logger) eventbus.EventBus {
 	return &eventBus{
 		handlerTimeout:      handlerTimeout,
+		ackTimeout:          handlerTimeout + ackGracePeriod,
+		receiptWindow:       defaultReceiptWindow,
+		probeInterval:       defaultProbeInterval,
 		client:              client,
 		logger:              log,
 		unsubscribeChannels: make(map[reflect.Value]chan struct{}),
 	}
 }
 
+const (
+	// ackGracePeriod is added to handler timeout when waiting for acknowledgement
+	ackGracePeriod = time.Second
+	// defaultReceiptWindow is how long publisher waits for worker to confirm it pulled event,
+	// if none does event is considered to have no workers
+	defaultReceiptWindow = 100 * time.Millisecond
+	// defaultProbeInterval is how often publisher probes its reply topic until worker pulling it is registered
+	defaultProbeInterval = 10 * time.Millisecond
+)
+
 type dto struct {
 	Event           domain.Event       `json:"event"`
 	RequestMetadata *metadata.Metadata `json:"request_metadata,omitempty"`
+	// ReplyTopic is set by PublishAndAcknowledge, worker sends acknowledgements to it
+	ReplyTopic string `json:"reply_topic,omitempty"`
 }
 
 // EventBus allow to publish/subscribe to events, allow to push/pull events
@@ -36,6 +52,9 @@ type dto struct {
 // use Push/Pull if you want only one handler to pull event from queue
 type eventBus struct {
 	handlerTimeout time.Duration
+	ackTimeout     time.Duration
+	receiptWindow  time.Duration
+	probeInterval  time.Duration
 	client         pushpullproto.PushPullClient
 	logger         *log.Logger
 
@@ -46,6 +65,10 @@ type eventBus struct {
 // Subscribe adds worker to pull events from queue,
 // pulled even will not be handled by other handlers
 func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.EventHandler) error {
+	if err := eventbus.ValidateTopic(eventType); err != nil {
+		return errors.Wrap(err)
+	}
+
 	stream, err := b.client.Pull(ctx, &pushpullproto.PullRequest{
 		Topic: eventType,
 	})
@@ -55,6 +78,7 @@ func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.
 
 	b.logger.Info(stream.Context(), "[EventBus] Pull: %s\n", eventType)
 
+	handlerID := uuid.New().String()
 	rv := reflect.ValueOf(fn)
 	unsubscribeCh := make(chan struct{}, 1)
 
@@ -75,7 +99,7 @@ func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.
 				return errors.Wrap(err)
 			}
 
-			if err := b.dispatchEvent(resp.GetPayload(), fn); err != nil {
+			if err := b.dispatchEvent(resp.GetPayload(), handlerID, fn); err != nil {
 				return errors.Wrap(err)
 			}
 		}
@@ -85,33 +109,65 @@ func (b *eventBus) Subscribe(ctx context.Context, eventType string, fn eventbus.
 // Publish pushes event to the queue,
 // will be handled by first handler to Pull it from that queue
 func (b *eventBus) Publish(ctx context.Context, event domain.Event) error {
-	o := dto{
-		Event: event,
-	}
+	return b.push(ctx, event, "")
+}
 
-	if m, ok := metadata.FromContext(ctx); ok {
-		o.RequestMetadata = m
-	}
+// PublishAndAcknowledge pushes event to the queue and waits until worker that pulled it handles it,
+// returns handler error, if no worker pulls event within receipt window it is not waited for
+func (b *eventBus) PublishAndAcknowledge(parentCtx context.Context, event domain.Event) error {
+	ctx, cancel := context.WithTimeout(parentCtx, b.ackTimeout)
+	defer cancel()
 
-	payload, err := json.Marshal(o)
+	replyTopic := eventbus.NewReplyTopic(event.Metadata.Type)
+
+	stream, err := b.client.Pull(ctx, &pushpullproto.PullRequest{
+		Topic: replyTopic,
+	})
 	if err != nil {
 		return errors.Wrap(err)
 	}
 
-	b.logger.Debug(ctx, "[EventBus] Push: %s %s\n", event.Metadata.Type, payload)
+	acks := make(chan eventbus.Ack)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				// stream is closed when context is done, collector reports timeout in that case
+				if ctx.Err() == nil {
+					close(acks)
+				}
+				return
+			}
 
-	if _, err := b.client.Push(ctx, &pushpullproto.PushRequest{
-		Topic:   event.Metadata.Type,
-		Payload: payload,
+			var ack eventbus.Ack
+			if err := json.Unmarshal(resp.GetPayload(), &ack); err != nil {
+				b.logger.Warning(ctx, "[EventBus] Invalid acknowledgement: %s %v\n", replyTopic, err)
+				continue
+			}
+
+			select {
+			case acks <- ack:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := eventbus.AwaitProbe(ctx, acks, b.probeInterval, func(ctx context.Context) error {
+		return b.sendAck(ctx, replyTopic, eventbus.Ack{Probe: true})
 	}); err != nil {
 		return errors.Wrap(err)
 	}
 
-	return nil
-}
+	if err := b.push(ctx, event, replyTopic); err != nil {
+		return errors.Wrap(err)
+	}
 
-func (b *eventBus) PublishAndAcknowledge(parentCtx context.Context, event domain.Event) error {
-	panic("not implemented")
+	if err := eventbus.CollectAcks(ctx, acks, b.receiptWindow); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
 }
 
 // Unsubscribe will unsubscribe after next event handler because stream.Recv() is blocking
@@ -127,7 +183,53 @@ func (b *eventBus) Unsubscribe(ctx context.Context, eventType string, fn eventbu
 	return nil
 }
 
-func (b *eventBus) dispatchEvent(payload []byte, fn eventbus.EventHandler) error {
+func (b *eventBus) push(ctx context.Context, event domain.Event, replyTopic string) error {
+	o := dto{
+		Event:      event,
+		ReplyTopic: replyTopic,
+	}
+
+	if m, ok := metadata.FromContext(ctx); ok {
+		o.RequestMetadata = m
+	}
+
+	payload, err := json.Marshal(o)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	b.logger.Debug(ctx, "[EventBus] Push: %s %s\n", event.Metadata.Type, event.ID)
+
+	// event is sent to topics of every pattern matching it, see eventbus.Topics
+	for _, topic := range eventbus.Topics(event) {
+		if _, err := b.client.Push(ctx, &pushpullproto.PushRequest{
+			Topic:   topic,
+			Payload: payload,
+		}); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+func (b *eventBus) sendAck(ctx context.Context, replyTopic string, ack eventbus.Ack) error {
+	payload, err := json.Marshal(ack)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if _, err := b.client.Push(ctx, &pushpullproto.PushRequest{
+		Topic:   replyTopic,
+		Payload: payload,
+	}); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (b *eventBus) dispatchEvent(payload []byte, handlerID string, fn eventbus.EventHandler) error {
 	ctx, cancel := context.WithTimeout(context.Background(), b.handlerTimeout)
 	defer cancel()
 
@@ -140,7 +242,30 @@ func (b *eventBus) dispatchEvent(payload []byte, fn eventbus.EventHandler) error
 		ctx = metadata.ContextWithMetadata(ctx, o.RequestMetadata)
 	}
 
-	b.logger.Debug(ctx, "[EventBus] Dispatch Event: %s %s\n", o.Event.Metadata.Type, o.Event.Payload)
+	b.logger.Debug(ctx, "[EventBus] Dispatch Event: %s %s\n", o.Event.Metadata.Type, o.Event.ID)
+
+	if o.ReplyTopic == "" {
+		return fn(domain.ContextWithCause(ctx, o.Event), o.Event)
+	}
+
+	if err := b.sendAck(ctx, o.ReplyTopic, eventbus.Ack{HandlerID: handlerID}); err != nil {
+		b.logger.Warning(ctx, "[EventBus] Could not acknowledge receipt: %s %v\n", o.Event.Metadata.Type, err)
+	}
+
+	handlerErr := fn(domain.ContextWithCause(ctx, o.Event), o.Event)
+
+	ack := eventbus.Ack{HandlerID: handlerID, Handled: true}
+	if handlerErr != nil {
+		ack.Error = handlerErr.Error()
+	}
+
+	// handler may have used up its context, acknowledgement is sent with a fresh one
+	ackCtx, ackCancel := context.WithTimeout(context.Background(), b.handlerTimeout)
+	defer ackCancel()
+
+	if err := b.sendAck(ackCtx, o.ReplyTopic, ack); err != nil {
+		b.logger.Warning(ctx, "[EventBus] Could not acknowledge event: %s %v\n", o.Event.Metadata.Type, err)
+	}
 
-	return fn(ctx, o.Event)
+	return handlerErr
 }
@@ -0,0 +1,205 @@
+package pushpull
+
+import (
+	"context"
+	systemErrors "errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/google/uuid"
+	pushpullproto "github.com/vardius/pushpull/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+type eventMock struct{}
+
+func (e eventMock) GetType() string {
+	return "event"
+}
+
+// server is an in-process pushpull server delivering every message to one of workers pulling a topic,
+// messages pushed to a topic without workers are dropped
+type server struct {
+	mtx     sync.RWMutex
+	workers map[string]map[chan []byte]struct{}
+}
+
+func (s *server) Push(ctx context.Context, r *pushpullproto.PushRequest) (*empty.Empty, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	for ch := range s.workers[r.GetTopic()] {
+		ch <- r.GetPayload()
+		break
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s *server) Pull(r *pushpullproto.PullRequest, stream pushpullproto.PushPull_PullServer) error {
+	ch := make(chan []byte, 100)
+
+	s.mtx.Lock()
+	if _, ok := s.workers[r.GetTopic()]; !ok {
+		s.workers[r.GetTopic()] = make(map[chan []byte]struct{})
+	}
+	s.workers[r.GetTopic()][ch] = struct{}{}
+	s.mtx.Unlock()
+
+	defer func() {
+		s.mtx.Lock()
+		delete(s.workers[r.GetTopic()], ch)
+		s.mtx.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case payload := <-ch:
+			if err := stream.Send(&pushpullproto.PullResponse{Payload: payload}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *server) waitForWorkers(t *testing.T, topic string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mtx.RLock()
+		count := len(s.workers[topic])
+		s.mtx.RUnlock()
+
+		if count >= n {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("expected %d workers of %s", n, topic)
+}
+
+func newTestBus(t *testing.T) (*eventBus, *server) {
+	t.Helper()
+
+	srv := &server{workers: make(map[string]map[chan []byte]struct{})}
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	pushpullproto.RegisterPushPullServer(grpcServer, srv)
+
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+	})
+
+	bus := New(time.Second, pushpullproto.NewPushPullClient(conn), log.New("development"))
+
+	return bus.(*eventBus), srv
+}
+
+func newEvent(t *testing.T) domain.Event {
+	t.Helper()
+
+	e, err := domain.NewEvent(uuid.New(), "event", 0, eventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestPublishAndAcknowledge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus, srv := newTestBus(t)
+
+	var handled int32
+	for i := 0; i < 3; i++ {
+		go bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+			time.Sleep(50 * time.Millisecond)
+			atomic.AddInt32(&handled, 1)
+			return nil
+		})
+	}
+	srv.waitForWorkers(t, "event", 3)
+
+	if err := bus.PublishAndAcknowledge(ctx, newEvent(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt32(&handled); n != 1 {
+		t.Errorf("expected event to be handled by one worker before acknowledgement, handled: %d", n)
+	}
+}
+
+func TestPublishAndAcknowledgeHandlerError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus, srv := newTestBus(t)
+
+	go bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+		return fmt.Errorf("handler failed")
+	})
+	srv.waitForWorkers(t, "event", 1)
+
+	err := bus.PublishAndAcknowledge(ctx, newEvent(t))
+	if err == nil || !strings.Contains(err.Error(), "handler failed") {
+		t.Errorf("expected handler error, got: %v", err)
+	}
+}
+
+func TestPublishAndAcknowledgeWithoutWorkers(t *testing.T) {
+	bus, _ := newTestBus(t)
+
+	if err := bus.PublishAndAcknowledge(context.Background(), newEvent(t)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishAndAcknowledgeTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus, srv := newTestBus(t)
+	bus.ackTimeout = 200 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+
+	go bus.Subscribe(ctx, "event", func(ctx context.Context, event domain.Event) error {
+		<-release
+		return nil
+	})
+	srv.waitForWorkers(t, "event", 1)
+
+	err := bus.PublishAndAcknowledge(ctx, newEvent(t))
+	if !systemErrors.Is(err, application.ErrTimeout) {
+		t.Errorf("expected timeout error, got: %v", err)
+	}
+}
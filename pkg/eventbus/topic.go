@@ -0,0 +1,74 @@
+package eventbus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+const (
+	// AllEvents topic subscribes handler to every event
+	AllEvents = "*"
+	// streamTopicPrefix prefixes topics of all events of a stream name
+	streamTopicPrefix = "stream:"
+	// wildcardSuffix ends topics matching event types by prefix, e.g. user.*
+	wildcardSuffix = ".*"
+)
+
+// StreamTopic returns topic subscribing handler to every event of given stream name, e.g. user.User
+func StreamTopic(streamName string) string {
+	return streamTopicPrefix + streamName
+}
+
+// TypePrefixTopic returns topic subscribing handler to every event which type starts with given segments,
+// e.g. TypePrefixTopic("user") matches user.WasRegisteredWithEmail
+func TypePrefixTopic(prefix string) string {
+	return prefix + wildcardSuffix
+}
+
+// ValidateTopic returns error if topic is not an event type, AllEvents, stream topic or type prefix topic,
+// wildcard is allowed only as a whole last segment of event type
+func ValidateTopic(topic string) error {
+	if topic == "" {
+		return errors.Wrap(fmt.Errorf("%w: topic can not be empty", application.ErrInvalid))
+	}
+	if topic == AllEvents || strings.HasPrefix(topic, streamTopicPrefix) {
+		return nil
+	}
+
+	i := strings.Index(topic, "*")
+	if i < 0 {
+		return nil
+	}
+	if i != len(topic)-1 || !strings.HasSuffix(topic, wildcardSuffix) || len(topic) == len(wildcardSuffix) {
+		return errors.Wrap(fmt.Errorf("%w: invalid wildcard topic %s", application.ErrInvalid, topic))
+	}
+
+	return nil
+}
+
+// Topics returns every topic event is delivered to, starting with its type:
+// type prefix topics from the longest one, stream topic and AllEvents.
+// Remote buses publish event to each of them so subscribers of patterns get it as well
+func Topics(event domain.Event) []string {
+	topics := []string{event.Metadata.Type}
+
+	prefix := event.Metadata.Type
+	for {
+		i := strings.LastIndex(prefix, ".")
+		if i <= 0 {
+			break
+		}
+		prefix = prefix[:i]
+		topics = append(topics, TypePrefixTopic(prefix))
+	}
+
+	if event.Metadata.StreamName != "" {
+		topics = append(topics, StreamTopic(event.Metadata.StreamName))
+	}
+
+	return append(topics, AllEvents)
+}
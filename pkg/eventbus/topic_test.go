@@ -0,0 +1,38 @@
+package eventbus
+
+import (
+	systemErrors "errors"
+	"reflect"
+	"testing"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+)
+
+func TestTopics(t *testing.T) {
+	event := domain.Event{
+		Metadata: domain.EventMetaData{
+			Type:       "user.email.WasChanged",
+			StreamName: "user.User",
+		},
+	}
+
+	want := []string{"user.email.WasChanged", "user.email.*", "user.*", "stream:user.User", AllEvents}
+	if got := Topics(event); !reflect.DeepEqual(got, want) {
+		t.Errorf("Topics() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateTopic(t *testing.T) {
+	for _, topic := range []string{"user.WasRegisteredWithEmail", AllEvents, TypePrefixTopic("user"), StreamTopic("user.User")} {
+		if err := ValidateTopic(topic); err != nil {
+			t.Errorf("ValidateTopic(%q) unexpected error: %v", topic, err)
+		}
+	}
+
+	for _, topic := range []string{"", "user*", "user.Was*", "*.WasCreated", ".*"} {
+		if err := ValidateTopic(topic); !systemErrors.Is(err, application.ErrInvalid) {
+			t.Errorf("ValidateTopic(%q) expected invalid topic error, got: %v", topic, err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	systemErrors "errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/keystore"
+)
+
+// encryptedFieldKey is a key of json object replacing encrypted payload field value
+const encryptedFieldKey = "$pii"
+
+type encryptedField struct {
+	Ciphertext []byte `json:"$pii"`
+}
+
+type cryptoShreddingEventStore struct {
+	EventStore
+	keyStore keystore.KeyStore
+}
+
+// WithCryptoShredding wraps event store so personal data fields of event payloads
+// are encrypted with a key of the event stream before they are stored
+// and transparently decrypted on read.
+// Once stream subject is forgotten its personal data fields are dropped from payloads
+// while the rest of the stream stays readable.
+// Only stored events are encrypted, events published to event bus carry decrypted personal data
+// so anything kept outside of event store should refer to events by id and read them again, as dead letters do.
+func WithCryptoShredding(store EventStore, keyStore keystore.KeyStore) EventStore {
+	return &cryptoShreddingEventStore{
+		EventStore: store,
+		keyStore:   keyStore,
+	}
+}
+
+func (s *cryptoShreddingEventStore) Store(ctx context.Context, events []domain.Event) error {
+	encrypted := make([]domain.Event, len(events))
+	for i, e := range events {
+		if len(e.PersonalData) > 0 {
+			payload, err := s.encrypt(ctx, e)
+			if err != nil {
+				return errors.Wrap(err)
+			}
+			e.Payload = payload
+		}
+		encrypted[i] = e
+	}
+
+	if err := s.EventStore.Store(ctx, encrypted); err != nil {
+		return err
+	}
+
+	for i := range events {
+		events[i].Position = encrypted[i].Position
+	}
+
+	return nil
+}
+
+func (s *cryptoShreddingEventStore) Get(ctx context.Context, id uuid.UUID) (domain.Event, error) {
+	e, err := s.EventStore.Get(ctx, id)
+	if err != nil {
+		return e, err
+	}
+
+	e.Payload, err = s.decrypt(ctx, e)
+	if err != nil {
+		return domain.NullEvent, errors.Wrap(err)
+	}
+
+	return e, nil
+}
+
+func (s *cryptoShreddingEventStore) FindAll(ctx context.Context) ([]domain.Event, error) {
+	return s.decryptAll(ctx)(s.EventStore.FindAll(ctx))
+}
+
+func (s *cryptoShreddingEventStore) GetStream(ctx context.Context, streamID uuid.UUID, streamName string) ([]domain.Event, error) {
+	return s.decryptAll(ctx)(s.EventStore.GetStream(ctx, streamID, streamName))
+}
+
+func (s *cryptoShreddingEventStore) GetStreamFromVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	return s.decryptAll(ctx)(s.EventStore.GetStreamFromVersion(ctx, streamID, streamName, version))
+}
+
+func (s *cryptoShreddingEventStore) GetStreamToVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	return s.decryptAll(ctx)(s.EventStore.GetStreamToVersion(ctx, streamID, streamName, version))
+}
+
+func (s *cryptoShreddingEventStore) GetStreamAsOf(ctx context.Context, streamID uuid.UUID, streamName string, at time.Time) ([]domain.Event, error) {
+	return s.decryptAll(ctx)(s.EventStore.GetStreamAsOf(ctx, streamID, streamName, at))
+}
+
+func (s *cryptoShreddingEventStore) ReadAll(ctx context.Context, fromPosition uint64, limit int) ([]domain.Event, error) {
+	return s.decryptAll(ctx)(s.EventStore.ReadAll(ctx, fromPosition, limit))
+}
+
+func (s *cryptoShreddingEventStore) GetByCorrelationID(ctx context.Context, correlationID string) ([]domain.Event, error) {
+	return s.decryptAll(ctx)(s.EventStore.GetByCorrelationID(ctx, correlationID))
+}
+
+func (s *cryptoShreddingEventStore) decryptAll(ctx context.Context) func(events []domain.Event, err error) ([]domain.Event, error) {
+	return func(events []domain.Event, err error) ([]domain.Event, error) {
+		if err != nil {
+			return events, err
+		}
+
+		for i := range events {
+			events[i].Payload, err = s.decrypt(ctx, events[i])
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+		}
+
+		return events, nil
+	}
+}
+
+// encrypt replaces values of personal data fields with encrypted field objects
+func (s *cryptoShreddingEventStore) encrypt(ctx context.Context, e domain.Event) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(e.Payload, &fields); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	key, err := s.keyStore.GetOrCreate(ctx, e.Metadata.StreamID)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	for _, name := range e.PersonalData {
+		value, ok := fields[name]
+		if !ok {
+			continue
+		}
+
+		ciphertext, err := keystore.Encrypt(key, value)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		encrypted, err := json.Marshal(encryptedField{Ciphertext: ciphertext})
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		fields[name] = encrypted
+	}
+
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return payload, nil
+}
+
+// decrypt restores values of encrypted fields,
+// fields of forgotten subjects are removed from the payload
+func (s *cryptoShreddingEventStore) decrypt(ctx context.Context, e domain.Event) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(e.Payload, &fields); err != nil {
+		// payload is not an object so it has no fields to decrypt
+		return e.Payload, nil
+	}
+
+	var (
+		key       []byte
+		forgotten bool
+		changed   bool
+	)
+	for name, value := range fields {
+		encrypted, ok := asEncryptedField(value)
+		if !ok {
+			continue
+		}
+		changed = true
+
+		if key == nil && !forgotten {
+			k, err := s.keyStore.Get(ctx, e.Metadata.StreamID)
+			switch {
+			case systemErrors.Is(err, keystore.ErrSubjectForgotten), systemErrors.Is(err, keystore.ErrKeyNotFound):
+				forgotten = true
+			case err != nil:
+				return nil, errors.Wrap(err)
+			default:
+				key = k
+			}
+		}
+
+		if forgotten {
+			delete(fields, name)
+			continue
+		}
+
+		plaintext, err := keystore.Decrypt(key, encrypted.Ciphertext)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		fields[name] = plaintext
+	}
+
+	if !changed {
+		return e.Payload, nil
+	}
+
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return payload, nil
+}
+
+func asEncryptedField(value json.RawMessage) (encryptedField, bool) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(value, &object); err != nil || len(object) != 1 {
+		return encryptedField{}, false
+	}
+	if _, ok := object[encryptedFieldKey]; !ok {
+		return encryptedField{}, false
+	}
+
+	var field encryptedField
+	if err := json.Unmarshal(value, &field); err != nil {
+		return encryptedField{}, false
+	}
+
+	return field, true
+}
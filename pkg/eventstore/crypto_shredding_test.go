@@ -0,0 +1,147 @@
+package eventstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	memorykeystore "github.com/vardius/go-api-boilerplate/pkg/keystore/memory"
+)
+
+type emailWasChanged struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email" pii:"true"`
+}
+
+func (e emailWasChanged) GetType() string {
+	return "test.EmailWasChanged"
+}
+
+func TestWithCryptoShredding(t *testing.T) {
+	ctx := context.Background()
+	streamID := uuid.New()
+
+	inner := memoryeventstore.New()
+	keyStore := memorykeystore.New()
+	store := eventstore.WithCryptoShredding(inner, keyStore)
+
+	e, err := domain.NewEvent(streamID, "test", 0, emailWasChanged{ID: streamID, Email: "test@test.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []domain.Event{e}
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+	if events[0].Position != 1 {
+		t.Errorf("expected position to be assigned, got %d", events[0].Position)
+	}
+	if !strings.Contains(string(events[0].Payload), "test@test.com") {
+		t.Error("stored events payload should stay readable for the caller")
+	}
+
+	raw, err := inner.Get(ctx, e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw.Payload), "test@test.com") {
+		t.Errorf("personal data should be encrypted at rest, got %s", raw.Payload)
+	}
+
+	stream, err := store.GetStream(ctx, streamID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload emailWasChanged
+	if err := json.Unmarshal(stream[0].Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Email != "test@test.com" || payload.ID != streamID {
+		t.Errorf("unexpected decrypted payload %s", stream[0].Payload)
+	}
+
+	if err := keyStore.Forget(ctx, streamID); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err = store.GetStream(ctx, streamID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stream) != 1 {
+		t.Fatalf("stream should stay intact after subject is forgotten, got %d events", len(stream))
+	}
+
+	payload = emailWasChanged{}
+	if err := json.Unmarshal(stream[0].Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Email != "" || payload.ID != streamID {
+		t.Errorf("expected personal data to be shredded, got %s", stream[0].Payload)
+	}
+}
+
+// emailWasChangedV2 renamed personal data field of emailWasChanged
+type emailWasChangedV2 struct {
+	ID           uuid.UUID `json:"id"`
+	EmailAddress string    `json:"email_address" pii:"true"`
+}
+
+func TestWithCryptoShreddingUpcasting(t *testing.T) {
+	ctx := context.Background()
+	streamID := uuid.New()
+
+	upcasters := domain.NewUpcasterRegistry()
+	upcasters.Register((emailWasChanged{}).GetType(), 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		var v1 emailWasChanged
+		if err := json.Unmarshal(payload, &v1); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(emailWasChangedV2{ID: v1.ID, EmailAddress: v1.Email})
+	})
+
+	inner := memoryeventstore.New()
+	// upcasters wrap decrypting store so they get personal data fields as plaintext
+	store := eventstore.WithUpcasting(eventstore.WithCryptoShredding(inner, memorykeystore.New()), upcasters)
+
+	e, err := domain.NewEvent(streamID, "test", 0, emailWasChanged{ID: streamID, Email: "test@test.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Store(ctx, []domain.Event{e}); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := inner.Get(ctx, e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw.Payload), "test@test.com") {
+		t.Fatalf("personal data should be encrypted at rest, got %s", raw.Payload)
+	}
+
+	stream, err := store.GetStream(ctx, streamID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stream) != 1 || stream[0].Metadata.SchemaVersion != 2 {
+		t.Fatalf("expected upcasted event, got %v", stream)
+	}
+
+	var payload emailWasChangedV2
+	if err := json.Unmarshal(stream[0].Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.EmailAddress != "test@test.com" || payload.ID != streamID {
+		t.Errorf("expected renamed personal data field to hold plaintext, got %s", stream[0].Payload)
+	}
+}
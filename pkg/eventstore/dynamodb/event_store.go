@@ -6,19 +6,48 @@ package eventstore
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/google/uuid"
 
+	"github.com/vardius/go-api-boilerplate/pkg/application"
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
 	"github.com/vardius/go-api-boilerplate/pkg/errors"
 	baseeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore"
 )
 
+const (
+	// positionCounterKey is an id of an item holding last reserved global position
+	positionCounterKey = "$position"
+	// globalLogIndex is a global secondary index with globalLogKey hash key and position range key
+	// all events share the same globalLogKey value so the index keeps them ordered by position
+	globalLogIndex = "global_log-position-index"
+	globalLogKey   = "global_log"
+	globalLog      = "events"
+	// storedAtKey is an attribute holding time event was written at in unix nanoseconds
+	storedAtKey = "stored_at"
+	// occurredAtKey is an attribute holding time event occurred at in unix nanoseconds,
+	// metadata.occurred_at is an RFC3339 string with varying precision and zone so it does not compare in time order
+	occurredAtKey = "occurred_at_nanos"
+	// gapSettleTime is how long events after a missing position are withheld from ReadAll,
+	// positions are reserved before the write so a gap is either an append still in flight or one that failed
+	gapSettleTime = 10 * time.Second
+	// maxTransactItems is the most items single TransactWriteItems call accepts
+	maxTransactItems = 100
+	// MaxEvents is the most events Store appends at once, each event is written together with its stream version item
+	MaxEvents = maxTransactItems / 2
+)
+
+// ErrTooManyEvents is returned when more than MaxEvents are stored at once,
+// events are appended in a single transaction so they can not be split without losing atomicity
+var ErrTooManyEvents = fmt.Errorf("%w: dynamodb event store appends at most %d events at once", application.ErrInvalid, MaxEvents)
+
 type eventStore struct {
 	service   *dynamodb.DynamoDB
 	tableName string
@@ -28,24 +57,37 @@ func (s *eventStore) Store(ctx context.Context, events []domain.Event) error {
 	if len(events) == 0 {
 		return nil
 	}
+	// checked before positions are reserved, reserved positions that are never written leave gaps readers wait for
+	if len(events) > MaxEvents {
+		return errors.Wrap(fmt.Errorf("%w, got %d", ErrTooManyEvents, len(events)))
+	}
 
-	// @TODO: check event version
-	for _, e := range events {
-		item, err := dynamodbattribute.MarshalMap(e)
-		if err != nil {
-			return errors.Wrap(err)
-		}
-		putParams := &dynamodb.PutItemInput{
-			TableName:           aws.String(s.tableName),
-			ConditionExpression: aws.String("attribute_not_exists(id) AND attribute_not_exists(metadata) AND attribute_not_exists(payload)"),
-			Item:                item,
-		}
-		if _, err = s.service.PutItem(putParams); err != nil {
-			if err, ok := err.(awserr.RequestFailure); ok && err.Code() == "ConditionalCheckFailedException" {
-				return errors.Wrap(fmt.Errorf("PutItem request failureerror: %w", err))
+	lastPosition, err := s.reservePositions(ctx, len(events))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	firstPosition := lastPosition - uint64(len(events)) + 1
+	items, err := s.transactItems(events, firstPosition, time.Now())
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if _, err := s.service.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	}); err != nil {
+		if err, ok := err.(*dynamodb.TransactionCanceledException); ok {
+			for _, reason := range err.CancellationReasons {
+				if aws.StringValue(reason.Code) == "ConditionalCheckFailed" {
+					return errors.Wrap(fmt.Errorf("%w: %s", baseeventstore.ErrConcurrencyConflict, err))
+				}
 			}
-			return errors.Wrap(fmt.Errorf("PutItem error: %w", err))
 		}
+		return errors.Wrap(fmt.Errorf("TransactWriteItems error: %w", err))
+	}
+
+	for i := range events {
+		events[i].Position = firstPosition + uint64(i)
 	}
 
 	return nil
@@ -88,11 +130,17 @@ func (s *eventStore) FindAll(ctx context.Context) ([]domain.Event, error) {
 }
 
 func (s *eventStore) GetStream(ctx context.Context, streamID uuid.UUID, streamName string) ([]domain.Event, error) {
+	return s.GetStreamFromVersion(ctx, streamID, streamName, 0)
+}
+
+func (s *eventStore) GetStreamFromVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
 	params := &dynamodb.QueryInput{
 		TableName:              aws.String(s.tableName),
 		KeyConditionExpression: aws.String("metadata.streamID = :streamID"),
+		FilterExpression:       aws.String("metadata.stream_version >= :streamVersion"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":streamID": {S: aws.String(streamID.String())},
+			":streamID":      {S: aws.String(streamID.String())},
+			":streamVersion": {N: aws.String(strconv.Itoa(version))},
 		},
 		ConsistentRead: aws.Bool(true),
 	}
@@ -105,6 +153,197 @@ func (s *eventStore) GetStream(ctx context.Context, streamID uuid.UUID, streamNa
 	return es, nil
 }
 
+func (s *eventStore) GetStreamToVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	params := &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		KeyConditionExpression: aws.String("metadata.streamID = :streamID"),
+		FilterExpression:       aws.String("metadata.stream_version < :streamVersion"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":streamID":      {S: aws.String(streamID.String())},
+			":streamVersion": {N: aws.String(strconv.Itoa(version))},
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	es, err := s.query(params)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return es, nil
+}
+
+// GetStreamAsOf filters events by occurredAtKey, events written before it was recorded are filtered after they are read
+func (s *eventStore) GetStreamAsOf(ctx context.Context, streamID uuid.UUID, streamName string, at time.Time) ([]domain.Event, error) {
+	params := &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		KeyConditionExpression: aws.String("metadata.streamID = :streamID"),
+		FilterExpression:       aws.String("#occurredAt <= :occurredAt OR attribute_not_exists(#occurredAt)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#occurredAt": aws.String(occurredAtKey),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":streamID":   {S: aws.String(streamID.String())},
+			":occurredAt": {N: aws.String(strconv.FormatInt(at.UnixNano(), 10))},
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	es, err := s.query(params)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return occurredAsOf(es, at), nil
+}
+
+// ReadAll requires globalLogIndex on the table, reads from global secondary index are eventually consistent,
+// events after a missing position are withheld until the gap settles, see gapSettleTime
+func (s *eventStore) ReadAll(ctx context.Context, fromPosition uint64, limit int) ([]domain.Event, error) {
+	resp, err := s.service.QueryWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		IndexName:              aws.String(globalLogIndex),
+		KeyConditionExpression: aws.String("#log = :log AND #position > :position"),
+		ExpressionAttributeNames: map[string]*string{
+			"#log":      aws.String(globalLogKey),
+			"#position": aws.String("position"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":log":      {S: aws.String(globalLog)},
+			":position": {N: aws.String(strconv.FormatUint(fromPosition, 10))},
+		},
+		ScanIndexForward: aws.Bool(true),
+		Limit:            aws.Int64(int64(limit)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf("query failed: %w", err))
+	}
+
+	stored := make([]storedEvent, len(resp.Items))
+	for i, item := range resp.Items {
+		if err := dynamodbattribute.UnmarshalMap(item, &stored[i].event); err != nil {
+			return nil, errors.Wrap(fmt.Errorf("unmarshal events failed: %w", err))
+		}
+		if attr, ok := item[storedAtKey]; ok {
+			nanos, err := strconv.ParseInt(aws.StringValue(attr.N), 10, 64)
+			if err != nil {
+				return nil, errors.Wrap(fmt.Errorf("invalid %s: %w", storedAtKey, err))
+			}
+			stored[i].storedAt = time.Unix(0, nanos)
+		}
+	}
+
+	return settled(stored, fromPosition, time.Now()), nil
+}
+
+// GetByCorrelationID scans the whole table, correlation id is a nested attribute and can not be indexed
+func (s *eventStore) GetByCorrelationID(ctx context.Context, correlationID string) ([]domain.Event, error) {
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String(s.tableName),
+		FilterExpression: aws.String("metadata.correlation_id = :correlationID"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":correlationID": {S: aws.String(correlationID)},
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	es := make([]domain.Event, 0)
+	var unmarshalErr error
+	if err := s.service.ScanPagesWithContext(ctx, params, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			e := domain.Event{}
+			if err := dynamodbattribute.UnmarshalMap(item, &e); err != nil {
+				unmarshalErr = fmt.Errorf("unmarshal events failed: %w", err)
+				return false
+			}
+			es = append(es, e)
+		}
+
+		return true
+	}); err != nil {
+		return nil, errors.Wrap(fmt.Errorf("scan failed: %w", err))
+	}
+	if unmarshalErr != nil {
+		return nil, errors.Wrap(unmarshalErr)
+	}
+
+	sort.Slice(es, func(i, j int) bool {
+		return es[i].Position < es[j].Position
+	})
+
+	return es, nil
+}
+
+// transactItems returns event items with their stream version items,
+// stream version items have neither globalLogKey nor position so globalLogIndex being sparse leaves them out of ReadAll
+func (s *eventStore) transactItems(events []domain.Event, firstPosition uint64, storedAt time.Time) ([]*dynamodb.TransactWriteItem, error) {
+	items := make([]*dynamodb.TransactWriteItem, 0, 2*len(events))
+	for i, e := range events {
+		e.Position = firstPosition + uint64(i)
+		item, err := dynamodbattribute.MarshalMap(e)
+		if err != nil {
+			return nil, err
+		}
+		item[globalLogKey] = &dynamodb.AttributeValue{S: aws.String(globalLog)}
+		item[storedAtKey] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(storedAt.UnixNano(), 10))}
+		item[occurredAtKey] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(e.Metadata.OccurredAt.UnixNano(), 10))}
+		items = append(items,
+			&dynamodb.TransactWriteItem{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(s.tableName),
+					ConditionExpression: aws.String("attribute_not_exists(id) AND attribute_not_exists(metadata) AND attribute_not_exists(payload)"),
+					Item:                item,
+				},
+			},
+			// stream version item guards that only one event is appended to the stream with given version
+			&dynamodb.TransactWriteItem{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(s.tableName),
+					ConditionExpression: aws.String("attribute_not_exists(id)"),
+					Item: map[string]*dynamodb.AttributeValue{
+						"id": {S: aws.String(streamVersionKey(e.Metadata))},
+					},
+				},
+			},
+		)
+	}
+
+	return items, nil
+}
+
+// reservePositions atomically increments position counter by n and returns the last reserved position
+func (s *eventStore) reservePositions(ctx context.Context, n int) (uint64, error) {
+	resp, err := s.service.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(positionCounterKey)},
+		},
+		UpdateExpression: aws.String("ADD #position :n"),
+		ExpressionAttributeNames: map[string]*string{
+			"#position": aws.String("position"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":n": {N: aws.String(strconv.Itoa(n))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("UpdateItem error: %w", err)
+	}
+
+	attr, ok := resp.Attributes["position"]
+	if !ok {
+		return 0, fmt.Errorf("position counter was not returned")
+	}
+
+	position, err := strconv.ParseUint(aws.StringValue(attr.N), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid position counter: %w", err)
+	}
+
+	return position, nil
+}
+
 func (s *eventStore) query(params *dynamodb.QueryInput) ([]domain.Event, error) {
 	resp, err := s.service.Query(params)
 	if err != nil {
@@ -127,6 +366,47 @@ func (s *eventStore) query(params *dynamodb.QueryInput) ([]domain.Event, error)
 	return es, nil
 }
 
+type storedEvent struct {
+	event domain.Event
+	// storedAt is zero for events written before it was recorded
+	storedAt time.Time
+}
+
+// settled returns events read in position order up to the first gap younger than gapSettleTime,
+// event after the gap reserved its position later so the missing one has been written at least since it was stored,
+// reader that moved past a gap never reads it again so it waits until the write commits or is given up
+func settled(stored []storedEvent, fromPosition uint64, now time.Time) []domain.Event {
+	events := make([]domain.Event, 0, len(stored))
+	expected := fromPosition + 1
+	for _, s := range stored {
+		if s.event.Position != expected && now.Sub(s.storedAt) < gapSettleTime {
+			break
+		}
+
+		events = append(events, s.event)
+		expected = s.event.Position + 1
+	}
+
+	return events
+}
+
+// occurredAsOf returns events that occurred at or before given time, comparing parsed times instead of their text
+func occurredAsOf(events []domain.Event, at time.Time) []domain.Event {
+	filtered := events[:0]
+	for _, e := range events {
+		if !e.Metadata.OccurredAt.After(at) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+// streamVersionKey returns id of an item reserving stream version
+func streamVersionKey(meta domain.EventMetaData) string {
+	return fmt.Sprintf("%s:%s:%d", meta.StreamName, meta.StreamID, meta.StreamVersion)
+}
+
 // New creates new dynamodb event store
 func New(tableName string, config *aws.Config) baseeventstore.EventStore {
 	if tableName == "" {
@@ -1,11 +1,23 @@
 package eventstore
 
 import (
+	"context"
+	systemErrors "errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
 )
 
+type eventMock struct{}
+
+func (e eventMock) GetType() string {
+	return "test.Mock"
+}
+
 func TestNew(t *testing.T) {
 	store := New("test", &aws.Config{Region: aws.String("us-west-2")})
 
@@ -13,3 +25,96 @@ func TestNew(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSettled(t *testing.T) {
+	now := time.Now()
+	stored := func(position uint64, storedAt time.Time) storedEvent {
+		return storedEvent{event: domain.Event{ID: uuid.New(), Position: position}, storedAt: storedAt}
+	}
+
+	// append holding position 2 overlaps with the one at position 3 and is not visible yet
+	overlapping := []storedEvent{stored(1, now), stored(3, now)}
+	if events := settled(overlapping, 0, now); len(events) != 1 || events[0].Position != 1 {
+		t.Errorf("expected events after in flight position to be withheld, got %v", events)
+	}
+	if events := settled(overlapping[1:], 1, now); len(events) != 0 {
+		t.Errorf("expected reader at position 1 to wait for position 2, got %v", events)
+	}
+
+	committed := []storedEvent{stored(2, now), stored(3, now)}
+	if events := settled(committed, 1, now); len(events) != 2 || events[0].Position != 2 || events[1].Position != 3 {
+		t.Errorf("expected both appends once position 2 is visible, got %v", events)
+	}
+
+	// append failed after reserving position 2
+	if events := settled(overlapping[1:], 1, now.Add(gapSettleTime)); len(events) != 1 || events[0].Position != 3 {
+		t.Errorf("expected settled gap to be skipped, got %v", events)
+	}
+
+	legacy := []storedEvent{stored(5, time.Time{})}
+	if events := settled(legacy, 1, now); len(events) != 1 {
+		t.Errorf("expected events without stored time to be treated as settled, got %v", events)
+	}
+}
+
+func TestOccurredAsOf(t *testing.T) {
+	at := time.Date(2020, 10, 14, 12, 0, 0, 0, time.UTC)
+	occurred := func(t time.Time) domain.Event {
+		return domain.Event{ID: uuid.New(), Metadata: domain.EventMetaData{OccurredAt: t}}
+	}
+
+	// as RFC3339Nano text "2020-10-14T12:00:00Z" sorts after "2020-10-14T12:00:00.5Z"
+	// and "2020-10-14T13:30:00+02:00" after "2020-10-14T12:00:00Z"
+	events := []domain.Event{
+		occurred(at.Add(-time.Hour)),
+		occurred(at),
+		occurred(at.Add(500 * time.Millisecond)),
+		occurred(at.Add(-30 * time.Minute).In(time.FixedZone("CEST", 2*60*60))),
+		occurred(at.Add(time.Nanosecond)),
+	}
+
+	got := occurredAsOf(events, at)
+	if len(got) != 3 || got[0].ID != events[0].ID || got[1].ID != events[1].ID || got[2].ID != events[3].ID {
+		t.Errorf("expected events occurred at or before %s, got %v", at, got)
+	}
+}
+
+func TestStoreRejectsTooManyEvents(t *testing.T) {
+	store := New("test", &aws.Config{Region: aws.String("us-west-2")})
+
+	// rejected before any request is sent
+	if err := store.Store(context.Background(), make([]domain.Event, MaxEvents+1)); !systemErrors.Is(err, ErrTooManyEvents) {
+		t.Errorf("expected too many events error, got %v", err)
+	}
+}
+
+func TestTransactItems(t *testing.T) {
+	s := &eventStore{tableName: "test"}
+
+	events := make([]domain.Event, MaxEvents)
+	for i := range events {
+		e, err := domain.NewEvent(uuid.New(), "test", i, eventMock{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events[i] = e
+	}
+
+	items, err := s.transactItems(events, 1, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) > maxTransactItems {
+		t.Fatalf("expected at most %d items, got %d", maxTransactItems, len(items))
+	}
+
+	for i := 0; i < len(items); i += 2 {
+		event, guard := items[i].Put.Item, items[i+1].Put.Item
+		if event[globalLogKey] == nil || event["position"] == nil {
+			t.Errorf("expected event item to be in global log index, got %v", event)
+		}
+		if guard[globalLogKey] != nil || guard["position"] != nil {
+			t.Errorf("expected stream version item to be left out of global log index, got %v", guard)
+		}
+	}
+}
@@ -2,7 +2,13 @@ package eventstore
 
 import (
 	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
 )
 
 // ErrEventNotFound is thrown when an event is not found in the store.
 var ErrEventNotFound = fmt.Errorf("event not found")
+
+// ErrConcurrencyConflict is thrown when stream has been modified since aggregate root was loaded,
+// event with the same stream version already exists in the store.
+var ErrConcurrencyConflict = fmt.Errorf("%w: stream version already exists", application.ErrConflict)
@@ -2,6 +2,7 @@ package eventstore
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -10,8 +11,26 @@ import (
 
 // EventStore methods allow to save, load events and event streams
 type EventStore interface {
+	// Store appends events to their streams, event's StreamVersion is the expected version of the stream
+	// if event with the same version was already appended to the stream returns ErrConcurrencyConflict
+	// and none of the given events are stored, on success events get their global Position assigned
 	Store(ctx context.Context, events []domain.Event) error
 	Get(ctx context.Context, id uuid.UUID) (domain.Event, error)
 	FindAll(ctx context.Context) ([]domain.Event, error)
 	GetStream(ctx context.Context, streamID uuid.UUID, streamName string) ([]domain.Event, error)
+	// GetStreamFromVersion returns stream events with version greater or equal to given one
+	GetStreamFromVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error)
+	// GetStreamToVersion returns stream events with version lower than given one,
+	// aggregate root loaded from them is at given version
+	GetStreamToVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error)
+	// GetStreamAsOf returns stream events that occurred at or before given time
+	GetStreamAsOf(ctx context.Context, streamID uuid.UUID, streamName string, at time.Time) ([]domain.Event, error)
+	// ReadAll returns up to limit events with position greater than fromPosition ordered by position,
+	// position of the last returned event should be used as fromPosition of the next read,
+	// event is not returned before every event with lower position that will ever be stored is visible
+	// so fewer events than limit may be returned while appends are in flight
+	ReadAll(ctx context.Context, fromPosition uint64, limit int) ([]domain.Event, error)
+	// GetByCorrelationID returns all events sharing given correlation id ordered by position,
+	// their causation ids link each event to the one that caused it
+	GetByCorrelationID(ctx context.Context, correlationID string) ([]domain.Event, error)
 }
@@ -0,0 +1,622 @@
+/*
+Package eventstore provides append-only segment file implementation of domain event store
+*/
+package eventstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	baseeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore"
+)
+
+// SyncMode defines when appended records are flushed to stable storage
+type SyncMode string
+
+const (
+	// SyncAlways fsyncs segment after every Store call
+	SyncAlways SyncMode = "always"
+	// SyncInterval fsyncs segment periodically, events stored since last sync may be lost on power failure
+	SyncInterval SyncMode = "interval"
+	// SyncNever leaves flushing to the operating system
+	SyncNever SyncMode = "never"
+)
+
+const (
+	segmentExt = ".log"
+	// headerSize is a size of record header: uint32 payload length followed by uint32 crc32 checksum of payload
+	headerSize = 8
+
+	defaultSegmentSize  = 64 << 20
+	defaultSyncInterval = time.Second
+)
+
+// Config provides values for file event store configuration
+type Config struct {
+	// SegmentSize is a size in bytes after which new segment file is started
+	SegmentSize  int64
+	Sync         SyncMode
+	SyncInterval time.Duration
+}
+
+// location points to an event stored in a segment file,
+// each Store call is written as a single record so that batch is appended atomically
+type location struct {
+	segment       int
+	offset        int64
+	size          uint32
+	index         int
+	streamVersion int
+}
+
+type segment struct {
+	file          *os.File
+	firstPosition uint64
+	size          int64
+}
+
+// EventStore is an append-only event store keeping events in segment files,
+// it keeps an in memory index of event positions, ids and streams rebuilt on start
+type EventStore struct {
+	sync.RWMutex
+	dir      string
+	cfg      Config
+	segments []*segment
+	log      []location
+	ids      map[uuid.UUID]int
+	streams  map[string][]int
+	versions map[string]map[int]struct{}
+	// correlations holds log indexes of events by correlation id
+	correlations map[string][]int
+	done         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// New opens file event store in given directory recovering torn tail record left by a crash,
+// corrupted record followed by valid ones fails opening instead of dropping committed events
+func New(dir string, cfg Config) (*EventStore, error) {
+	if cfg.SegmentSize <= 0 {
+		cfg.SegmentSize = defaultSegmentSize
+	}
+	if cfg.Sync == "" {
+		cfg.Sync = SyncAlways
+	}
+	if cfg.SyncInterval <= 0 {
+		cfg.SyncInterval = defaultSyncInterval
+	}
+
+	switch cfg.Sync {
+	case SyncAlways, SyncInterval, SyncNever:
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported sync mode: %s", cfg.Sync))
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	s := &EventStore{
+		dir:          dir,
+		cfg:          cfg,
+		ids:          make(map[uuid.UUID]int),
+		streams:      make(map[string][]int),
+		versions:     make(map[string]map[int]struct{}),
+		correlations: make(map[string][]int),
+		done:         make(chan struct{}),
+	}
+
+	if err := s.open(); err != nil {
+		s.closeSegments()
+		return nil, errors.Wrap(err)
+	}
+
+	if cfg.Sync == SyncInterval {
+		s.wg.Add(1)
+		go s.syncPeriodically()
+	}
+
+	return s, nil
+}
+
+// Store appends events to the active segment as a single record
+func (s *EventStore) Store(ctx context.Context, events []domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	appended := make(map[string]map[int]struct{})
+	for _, e := range events {
+		key := streamKey(e.Metadata.StreamID, e.Metadata.StreamName)
+		if _, ok := s.versions[key][e.Metadata.StreamVersion]; ok {
+			return fmt.Errorf("%w: %s@%d", baseeventstore.ErrConcurrencyConflict, key, e.Metadata.StreamVersion)
+		}
+		if _, ok := appended[key][e.Metadata.StreamVersion]; ok {
+			return fmt.Errorf("%w: %s@%d", baseeventstore.ErrConcurrencyConflict, key, e.Metadata.StreamVersion)
+		}
+		if _, ok := appended[key]; !ok {
+			appended[key] = make(map[int]struct{})
+		}
+		appended[key][e.Metadata.StreamVersion] = struct{}{}
+	}
+
+	batch := make([]domain.Event, len(events))
+	copy(batch, events)
+	for i := range batch {
+		batch[i].Position = uint64(len(s.log) + i + 1)
+	}
+
+	record, err := encodeRecord(batch)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	active := s.segments[len(s.segments)-1]
+	if active.size > 0 && active.size+int64(len(record)) > s.cfg.SegmentSize {
+		if err := s.roll(batch[0].Position); err != nil {
+			return errors.Wrap(err)
+		}
+		active = s.segments[len(s.segments)-1]
+	}
+
+	offset := active.size
+	if _, err := active.file.WriteAt(record, offset); err != nil {
+		// drop partially written record so it is not mistaken for a torn tail of a later write
+		_ = active.file.Truncate(offset)
+		return errors.Wrap(err)
+	}
+	if s.cfg.Sync == SyncAlways {
+		if err := active.file.Sync(); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	active.size += int64(len(record))
+
+	s.index(len(s.segments)-1, offset, uint32(len(record)), batch)
+
+	for i := range events {
+		events[i].Position = batch[i].Position
+	}
+
+	return nil
+}
+
+// Get returns event by id
+func (s *EventStore) Get(ctx context.Context, id uuid.UUID) (domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	i, ok := s.ids[id]
+	if !ok {
+		return domain.NullEvent, baseeventstore.ErrEventNotFound
+	}
+
+	e, err := s.read(s.log[i])
+	if err != nil {
+		return domain.NullEvent, errors.Wrap(err)
+	}
+
+	return e, nil
+}
+
+// FindAll returns all stored events in position order
+func (s *EventStore) FindAll(ctx context.Context) ([]domain.Event, error) {
+	s.RLock()
+	n := len(s.log)
+	s.RUnlock()
+
+	return s.ReadAll(ctx, 0, n)
+}
+
+// GetStream returns stream events in version order
+func (s *EventStore) GetStream(ctx context.Context, streamID uuid.UUID, streamName string) ([]domain.Event, error) {
+	return s.GetStreamFromVersion(ctx, streamID, streamName, 0)
+}
+
+// GetStreamFromVersion returns stream events with version greater or equal to given one
+func (s *EventStore) GetStreamFromVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	stream := s.streams[streamKey(streamID, streamName)]
+	from := sort.Search(len(stream), func(i int) bool {
+		return s.log[stream[i]].streamVersion >= version
+	})
+
+	es := make([]domain.Event, 0, len(stream)-from)
+	for _, i := range stream[from:] {
+		e, err := s.read(s.log[i])
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		es = append(es, e)
+	}
+
+	return es, nil
+}
+
+// GetStreamToVersion returns stream events with version lower than given one
+func (s *EventStore) GetStreamToVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	stream := s.streams[streamKey(streamID, streamName)]
+	to := sort.Search(len(stream), func(i int) bool {
+		return s.log[stream[i]].streamVersion >= version
+	})
+
+	es := make([]domain.Event, 0, to)
+	for _, i := range stream[:to] {
+		e, err := s.read(s.log[i])
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		es = append(es, e)
+	}
+
+	return es, nil
+}
+
+// GetStreamAsOf returns stream events that occurred at or before given time
+func (s *EventStore) GetStreamAsOf(ctx context.Context, streamID uuid.UUID, streamName string, at time.Time) ([]domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	stream := s.streams[streamKey(streamID, streamName)]
+	es := make([]domain.Event, 0, len(stream))
+	for _, i := range stream {
+		e, err := s.read(s.log[i])
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if e.Metadata.OccurredAt.After(at) {
+			break
+		}
+		es = append(es, e)
+	}
+
+	return es, nil
+}
+
+// ReadAll returns up to limit events with position greater than fromPosition
+func (s *EventStore) ReadAll(ctx context.Context, fromPosition uint64, limit int) ([]domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	if fromPosition >= uint64(len(s.log)) || limit <= 0 {
+		return []domain.Event{}, nil
+	}
+	end := fromPosition + uint64(limit)
+	if end > uint64(len(s.log)) {
+		end = uint64(len(s.log))
+	}
+
+	es := make([]domain.Event, 0, end-fromPosition)
+	for _, loc := range s.log[fromPosition:end] {
+		e, err := s.read(loc)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		es = append(es, e)
+	}
+
+	return es, nil
+}
+
+// GetByCorrelationID returns events sharing given correlation id in position order
+func (s *EventStore) GetByCorrelationID(ctx context.Context, correlationID string) ([]domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	correlated := s.correlations[correlationID]
+	es := make([]domain.Event, 0, len(correlated))
+	for _, i := range correlated {
+		e, err := s.read(s.log[i])
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		es = append(es, e)
+	}
+
+	return es, nil
+}
+
+// Close syncs and closes segment files
+func (s *EventStore) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	s.Lock()
+	defer s.Unlock()
+
+	if len(s.segments) > 0 && s.cfg.Sync != SyncNever {
+		if err := s.segments[len(s.segments)-1].file.Sync(); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	if err := s.closeSegments(); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// open loads existing segments in order rebuilding the index,
+// torn record at the end of the last segment is truncated
+func (s *EventStore) open() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+segmentExt))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	for i, path := range paths {
+		f, err := os.OpenFile(path, os.O_RDWR, 0644)
+		if err != nil {
+			return err
+		}
+
+		seg := &segment{file: f, firstPosition: uint64(len(s.log) + 1)}
+		s.segments = append(s.segments, seg)
+
+		isLast := i == len(paths)-1
+		if err := s.recover(len(s.segments)-1, isLast); err != nil {
+			return fmt.Errorf("segment %s: %w", path, err)
+		}
+	}
+
+	if len(s.segments) == 0 {
+		return s.roll(1)
+	}
+
+	return nil
+}
+
+// recover reads all records of a segment, only the last segment is allowed to end with a torn record,
+// bad record followed by a valid one is a corruption of committed events and fails the recovery
+func (s *EventStore) recover(segmentIndex int, isLast bool) error {
+	seg := s.segments[segmentIndex]
+
+	info, err := seg.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+	for offset < info.Size() {
+		size, batch, err := readRecord(seg.file, offset, info.Size())
+		if err != nil {
+			if !isLast {
+				return fmt.Errorf("corrupted record at offset %d: %w", offset, err)
+			}
+
+			followed, scanErr := hasRecordAfter(seg.file, offset, info.Size())
+			if scanErr != nil {
+				return scanErr
+			}
+			if followed {
+				return fmt.Errorf("corrupted record at offset %d is followed by valid records: %w", offset, err)
+			}
+
+			if err := seg.file.Truncate(offset); err != nil {
+				return err
+			}
+			if err := seg.file.Sync(); err != nil {
+				return err
+			}
+			break
+		}
+
+		for i := range batch {
+			batch[i].Position = uint64(len(s.log) + i + 1)
+		}
+		s.index(segmentIndex, offset, size, batch)
+
+		offset += int64(size)
+	}
+
+	seg.size = offset
+
+	return nil
+}
+
+// roll starts new segment file, events are appended to the last segment only
+func (s *EventStore) roll(firstPosition uint64) error {
+	if len(s.segments) > 0 && s.cfg.Sync != SyncNever {
+		if err := s.segments[len(s.segments)-1].file.Sync(); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(s.dir, fmt.Sprintf("%020d%s", firstPosition, segmentExt))
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	if s.cfg.Sync != SyncNever {
+		if err := syncDir(s.dir); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	s.segments = append(s.segments, &segment{file: f, firstPosition: firstPosition})
+
+	return nil
+}
+
+func (s *EventStore) index(segmentIndex int, offset int64, size uint32, batch []domain.Event) {
+	for i, e := range batch {
+		key := streamKey(e.Metadata.StreamID, e.Metadata.StreamName)
+		if _, ok := s.versions[key]; !ok {
+			s.versions[key] = make(map[int]struct{})
+		}
+		s.versions[key][e.Metadata.StreamVersion] = struct{}{}
+
+		s.log = append(s.log, location{
+			segment:       segmentIndex,
+			offset:        offset,
+			size:          size,
+			index:         i,
+			streamVersion: e.Metadata.StreamVersion,
+		})
+		s.ids[e.ID] = len(s.log) - 1
+		s.streams[key] = insertByVersion(s.streams[key], len(s.log)-1, s.log)
+		if e.Metadata.CorrelationID != "" {
+			s.correlations[e.Metadata.CorrelationID] = append(s.correlations[e.Metadata.CorrelationID], len(s.log)-1)
+		}
+	}
+}
+
+func (s *EventStore) read(loc location) (domain.Event, error) {
+	_, batch, err := readRecord(s.segments[loc.segment].file, loc.offset, loc.offset+int64(loc.size))
+	if err != nil {
+		return domain.NullEvent, err
+	}
+	if loc.index >= len(batch) {
+		return domain.NullEvent, fmt.Errorf("record at offset %d has no event %d", loc.offset, loc.index)
+	}
+
+	return batch[loc.index], nil
+}
+
+func (s *EventStore) syncPeriodically() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.RLock()
+			_ = s.segments[len(s.segments)-1].file.Sync()
+			s.RUnlock()
+		}
+	}
+}
+
+func (s *EventStore) closeSegments() error {
+	var firstErr error
+	for _, seg := range s.segments {
+		if err := seg.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	s.segments = nil
+
+	return firstErr
+}
+
+func encodeRecord(batch []domain.Event) ([]byte, error) {
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	record := make([]byte, headerSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[headerSize:], payload)
+
+	return record, nil
+}
+
+// readRecord reads record at given offset returning its total size, end is an offset record must not exceed,
+// short read or checksum mismatch means record is torn or corrupted
+func readRecord(r io.ReaderAt, offset, end int64) (uint32, []domain.Event, error) {
+	if offset+headerSize > end {
+		return 0, nil, fmt.Errorf("could not read record header: %w", io.ErrUnexpectedEOF)
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, offset); err != nil {
+		return 0, nil, fmt.Errorf("could not read record header: %w", err)
+	}
+
+	length := binary.LittleEndian.Uint32(header[0:4])
+	checksum := binary.LittleEndian.Uint32(header[4:8])
+
+	if offset+headerSize+int64(length) > end {
+		return 0, nil, fmt.Errorf("could not read record payload: %w", io.ErrUnexpectedEOF)
+	}
+
+	payload := make([]byte, length)
+	if _, err := r.ReadAt(payload, offset+headerSize); err != nil {
+		return 0, nil, fmt.Errorf("could not read record payload: %w", err)
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return 0, nil, fmt.Errorf("record checksum mismatch")
+	}
+
+	var batch []domain.Event
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return 0, nil, fmt.Errorf("could not decode record: %w", err)
+	}
+
+	return headerSize + length, batch, nil
+}
+
+// hasRecordAfter looks for a valid record starting anywhere after given offset,
+// torn write leaves only a prefix of the last record so nothing valid can follow it
+func hasRecordAfter(r io.ReaderAt, offset, end int64) (bool, error) {
+	tail := make([]byte, end-offset)
+	if _, err := r.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return false, err
+	}
+
+	tr := bytes.NewReader(tail)
+	for o := int64(1); o+headerSize <= int64(len(tail)); o++ {
+		if _, _, err := readRecord(tr, o, int64(len(tail))); err == nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// insertByVersion keeps stream index ordered by stream version
+func insertByVersion(stream []int, i int, log []location) []int {
+	at := sort.Search(len(stream), func(j int) bool {
+		return log[stream[j]].streamVersion > log[i].streamVersion
+	})
+	stream = append(stream, 0)
+	copy(stream[at+1:], stream[at:])
+	stream[at] = i
+
+	return stream
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+func streamKey(streamID uuid.UUID, streamName string) string {
+	return streamName + ":" + streamID.String()
+}
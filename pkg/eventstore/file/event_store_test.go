@@ -0,0 +1,418 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	baseeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore"
+)
+
+type rawEventMock struct {
+	Page   int      `json:"page"`
+	Fruits []string `json:"fruits"`
+}
+
+func (e rawEventMock) GetType() string {
+	return "test.Mock"
+}
+
+func newTestStore(t *testing.T, dir string, cfg Config) *EventStore {
+	store, err := New(dir, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func newTestEvents(t *testing.T, streamID uuid.UUID, fromVersion, count int) []domain.Event {
+	events := make([]domain.Event, 0, count)
+	for v := fromVersion; v < fromVersion+count; v++ {
+		e, err := domain.NewEvent(streamID, "test", v, rawEventMock{Page: v}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+
+	return events
+}
+
+func TestNew(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := newTestStore(t, dir, Config{})
+	defer store.Close()
+
+	if _, err := New(dir, Config{Sync: "sometimes"}); err == nil {
+		t.Error("expected error for unsupported sync mode")
+	}
+}
+
+func TestEventStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	store := newTestStore(t, dir, Config{Sync: SyncNever})
+	defer store.Close()
+
+	streamID := uuid.New()
+	events := newTestEvents(t, streamID, 0, 3)
+
+	if err := store.Store(ctx, events[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Store(ctx, events[2:]); err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range events {
+		if e.Position != uint64(i+1) {
+			t.Errorf("expected position %d, got %d", i+1, e.Position)
+		}
+	}
+
+	se, err := store.Get(ctx, events[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if se.ID != events[1].ID || string(se.Payload) != string(events[1].Payload) {
+		t.Errorf("unexpected event %v", se)
+	}
+
+	if _, err := store.Get(ctx, uuid.New()); !errors.Is(err, baseeventstore.ErrEventNotFound) {
+		t.Errorf("expected ErrEventNotFound, got %v", err)
+	}
+
+	s, err := store.GetStreamFromVersion(ctx, streamID, "test", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 || s[0].Metadata.StreamVersion != 1 || s[1].Metadata.StreamVersion != 2 {
+		t.Errorf("unexpected stream %v", s)
+	}
+
+	page, err := store.ReadAll(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].ID != events[1].ID {
+		t.Errorf("unexpected page %v", page)
+	}
+
+	all, err := store.FindAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 events, got %d", len(all))
+	}
+
+	concurrent := newTestEvents(t, streamID, 2, 1)
+	if err := store.Store(ctx, concurrent); !errors.Is(err, baseeventstore.ErrConcurrencyConflict) {
+		t.Errorf("expected concurrency conflict, got %v", err)
+	}
+}
+
+func TestEventStoreGetByCorrelationID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	store := newTestStore(t, dir, Config{Sync: SyncNever})
+
+	root, err := domain.NewEventFromContext(ctx, uuid.New(), "test", 0, rawEventMock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caused, err := domain.NewEventFromContext(domain.ContextWithCause(ctx, root), uuid.New(), "test", 0, rawEventMock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Store(ctx, append(newTestEvents(t, uuid.New(), 0, 1), root, caused)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// index of correlations is rebuilt when store is opened
+	store = newTestStore(t, dir, Config{Sync: SyncNever})
+	defer store.Close()
+
+	chain, err := store.GetByCorrelationID(ctx, root.Metadata.CorrelationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 2 || chain[0].ID != root.ID || chain[1].Metadata.CausationID != root.ID.String() {
+		t.Errorf("unexpected causal chain %v", chain)
+	}
+}
+
+func TestEventStoreTemporalReads(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	store := newTestStore(t, dir, Config{Sync: SyncNever})
+	defer store.Close()
+
+	streamID := uuid.New()
+	start := time.Now()
+	events := newTestEvents(t, streamID, 0, 3)
+	for i := range events {
+		events[i].Metadata.OccurredAt = start.Add(time.Duration(i) * time.Hour)
+	}
+
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+
+	toVersion, err := store.GetStreamToVersion(ctx, streamID, "test", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toVersion) != 2 || toVersion[1].ID != events[1].ID {
+		t.Errorf("expected first two events, got %v", toVersion)
+	}
+
+	asOf, err := store.GetStreamAsOf(ctx, streamID, "test", start.Add(90*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asOf) != 2 || asOf[1].ID != events[1].ID {
+		t.Errorf("expected first two events, got %v", asOf)
+	}
+}
+
+func TestEventStoreReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	streamID := uuid.New()
+	// small segments so every batch starts a new segment file
+	cfg := Config{SegmentSize: 1, Sync: SyncAlways}
+
+	store := newTestStore(t, dir, cfg)
+	for v := 0; v < 3; v++ {
+		if err := store.Store(ctx, newTestEvents(t, streamID, v, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 3 {
+		t.Errorf("expected 3 segments, got %d", len(segments))
+	}
+
+	store = newTestStore(t, dir, cfg)
+	defer store.Close()
+
+	s, err := store.GetStream(ctx, streamID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 3 || s[2].Position != 3 {
+		t.Fatalf("expected 3 events after reopen, got %v", s)
+	}
+
+	next := newTestEvents(t, streamID, 3, 1)
+	if err := store.Store(ctx, next); err != nil {
+		t.Fatal(err)
+	}
+	if next[0].Position != 4 {
+		t.Errorf("expected position 4, got %d", next[0].Position)
+	}
+}
+
+func TestEventStoreRecoversTornTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	streamID := uuid.New()
+
+	store := newTestStore(t, dir, Config{})
+	if err := store.Store(ctx, newTestEvents(t, streamID, 0, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Store(ctx, newTestEvents(t, streamID, 2, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(segments[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// simulate crash in the middle of writing the second batch
+	if err := os.Truncate(segments[0], info.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+
+	store = newTestStore(t, dir, Config{})
+	defer store.Close()
+
+	s, err := store.GetStream(ctx, streamID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Fatalf("expected torn batch to be dropped as a whole, got %d events", len(s))
+	}
+
+	retry := newTestEvents(t, streamID, 2, 2)
+	if err := store.Store(ctx, retry); err != nil {
+		t.Fatalf("expected versions of torn batch to be available again, got %v", err)
+	}
+	if retry[0].Position != 3 {
+		t.Errorf("expected position 3, got %d", retry[0].Position)
+	}
+
+	all, err := store.FindAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Errorf("expected 4 events, got %d", len(all))
+	}
+}
+
+func TestEventStoreRejectsCorruptedSegment(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	cfg := Config{SegmentSize: 1}
+
+	store := newTestStore(t, dir, cfg)
+	for v := 0; v < 2; v++ {
+		if err := store.Store(ctx, newTestEvents(t, uuid.New(), v, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the tail of the last segment may be torn, damage in older segments must not be silently dropped
+	if err := os.Truncate(segments[0], 4); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(dir, cfg); err == nil {
+		t.Error("expected error for corrupted segment")
+	}
+}
+
+func TestEventStoreRejectsCorruptedMiddleRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	streamID := uuid.New()
+
+	store := newTestStore(t, dir, Config{})
+	first := newTestEvents(t, streamID, 0, 1)
+	if err := store.Store(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	for v := 1; v < 3; v++ {
+		if err := store.Store(ctx, newTestEvents(t, streamID, v, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(segments[0], os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstSize := int64(store.log[0].size)
+	// flip a payload byte of the second of three records
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, firstSize+headerSize+1); err != nil {
+		t.Fatal(err)
+	}
+	b[0] ^= 0xff
+	if _, err := f.WriteAt(b, firstSize+headerSize+1); err != nil {
+		t.Fatal(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(dir, Config{}); err == nil {
+		t.Fatal("expected error for corrupted record followed by valid ones")
+	}
+
+	after, err := os.Stat(segments[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Size() != info.Size() {
+		t.Errorf("segment with committed events after corruption should not be truncated, size %d, want %d", after.Size(), info.Size())
+	}
+}
@@ -0,0 +1,74 @@
+package eventstore
+
+import (
+	"context"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+// Iterator pages through global event log starting after given position
+type Iterator struct {
+	store     EventStore
+	position  uint64
+	batchSize int
+	batch     []domain.Event
+	current   domain.Event
+	err       error
+}
+
+// NewIterator creates iterator reading events with position greater than fromPosition
+// in batches of given size
+func NewIterator(store EventStore, fromPosition uint64, batchSize int) *Iterator {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	return &Iterator{
+		store:     store,
+		position:  fromPosition,
+		batchSize: batchSize,
+	}
+}
+
+// Next advances iterator to the next event, returns false when there are no more events
+// or an error occurred. Once caught up Next can be called again to poll for new events
+func (i *Iterator) Next(ctx context.Context) bool {
+	if i.err != nil {
+		return false
+	}
+
+	if len(i.batch) == 0 {
+		batch, err := i.store.ReadAll(ctx, i.position, i.batchSize)
+		if err != nil {
+			i.err = errors.Wrap(err)
+			return false
+		}
+
+		i.batch = batch
+	}
+
+	if len(i.batch) == 0 {
+		return false
+	}
+
+	i.current, i.batch = i.batch[0], i.batch[1:]
+	i.position = i.current.Position
+
+	return true
+}
+
+// Event returns current event
+func (i *Iterator) Event() domain.Event {
+	return i.current
+}
+
+// Position returns position of the current event, can be stored as a checkpoint
+func (i *Iterator) Position() uint64 {
+	return i.position
+}
+
+// Err returns error that stopped iteration
+func (i *Iterator) Err() error {
+	return i.err
+}
@@ -0,0 +1,75 @@
+package eventstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+)
+
+type eventMock struct{}
+
+func (e eventMock) GetType() string {
+	return "test.Mock"
+}
+
+func TestIterator(t *testing.T) {
+	ctx := context.Background()
+	store := memoryeventstore.New()
+	streamID := uuid.New()
+
+	appendEvents := func(fromVersion, count int) {
+		events := make([]domain.Event, 0, count)
+		for v := fromVersion; v < fromVersion+count; v++ {
+			e, err := domain.NewEvent(streamID, "test", v, eventMock{}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, e)
+		}
+		if err := store.Store(ctx, events); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	appendEvents(0, 5)
+
+	it := eventstore.NewIterator(store, 0, 2)
+
+	var positions []uint64
+	for it.Next(ctx) {
+		positions = append(positions, it.Event().Position)
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if len(positions) != 5 {
+		t.Fatalf("expected 5 events, got %d", len(positions))
+	}
+	for i, p := range positions {
+		if p != uint64(i+1) {
+			t.Errorf("expected position %d, got %d", i+1, p)
+		}
+	}
+
+	appendEvents(5, 2)
+
+	if !it.Next(ctx) {
+		t.Fatal("expected iterator to pick up new events once caught up")
+	}
+	if it.Position() != 6 {
+		t.Errorf("expected position 6, got %d", it.Position())
+	}
+
+	fromCheckpoint := eventstore.NewIterator(store, 6, 10)
+	if !fromCheckpoint.Next(ctx) || fromCheckpoint.Position() != 7 {
+		t.Errorf("expected to resume after checkpoint at position 7, got %d", fromCheckpoint.Position())
+	}
+	if fromCheckpoint.Next(ctx) {
+		t.Error("expected no more events")
+	}
+}
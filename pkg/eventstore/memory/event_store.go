@@ -5,7 +5,9 @@ package eventstore
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -15,7 +17,9 @@ import (
 
 type eventStore struct {
 	sync.RWMutex
-	events map[string]domain.Event
+	log      []domain.Event
+	index    map[string]int
+	versions map[string]map[int]struct{}
 }
 
 func (s *eventStore) Store(ctx context.Context, events []domain.Event) error {
@@ -26,9 +30,31 @@ func (s *eventStore) Store(ctx context.Context, events []domain.Event) error {
 	s.Lock()
 	defer s.Unlock()
 
-	// @TODO: check event version
+	appended := make(map[string]map[int]struct{})
 	for _, e := range events {
-		s.events[e.ID.String()] = e
+		key := streamKey(e.Metadata.StreamID, e.Metadata.StreamName)
+		if _, ok := s.versions[key][e.Metadata.StreamVersion]; ok {
+			return fmt.Errorf("%w: %s@%d", baseeventstore.ErrConcurrencyConflict, key, e.Metadata.StreamVersion)
+		}
+		if _, ok := appended[key][e.Metadata.StreamVersion]; ok {
+			return fmt.Errorf("%w: %s@%d", baseeventstore.ErrConcurrencyConflict, key, e.Metadata.StreamVersion)
+		}
+		if _, ok := appended[key]; !ok {
+			appended[key] = make(map[int]struct{})
+		}
+		appended[key][e.Metadata.StreamVersion] = struct{}{}
+	}
+
+	for i := range events {
+		key := streamKey(events[i].Metadata.StreamID, events[i].Metadata.StreamName)
+		if _, ok := s.versions[key]; !ok {
+			s.versions[key] = make(map[int]struct{})
+		}
+		s.versions[key][events[i].Metadata.StreamVersion] = struct{}{}
+
+		events[i].Position = uint64(len(s.log) + 1)
+		s.index[events[i].ID.String()] = len(s.log)
+		s.log = append(s.log, events[i])
 	}
 
 	return nil
@@ -37,8 +63,8 @@ func (s *eventStore) Store(ctx context.Context, events []domain.Event) error {
 func (s *eventStore) Get(ctx context.Context, id uuid.UUID) (domain.Event, error) {
 	s.RLock()
 	defer s.RUnlock()
-	if val, ok := s.events[id.String()]; ok {
-		return val, nil
+	if i, ok := s.index[id.String()]; ok {
+		return s.log[i], nil
 	}
 
 	return domain.NullEvent, baseeventstore.ErrEventNotFound
@@ -47,28 +73,86 @@ func (s *eventStore) Get(ctx context.Context, id uuid.UUID) (domain.Event, error
 func (s *eventStore) FindAll(ctx context.Context) ([]domain.Event, error) {
 	s.RLock()
 	defer s.RUnlock()
-	es := make([]domain.Event, 0, len(s.events))
-	for _, val := range s.events {
-		es = append(es, val)
-	}
+	es := make([]domain.Event, len(s.log))
+	copy(es, s.log)
 	return es, nil
 }
 
 func (s *eventStore) GetStream(ctx context.Context, streamID uuid.UUID, streamName string) ([]domain.Event, error) {
+	return s.GetStreamFromVersion(ctx, streamID, streamName, 0)
+}
+
+func (s *eventStore) GetStreamFromVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
 	s.RLock()
 	defer s.RUnlock()
 	e := make([]domain.Event, 0, 0)
-	for _, val := range s.events {
-		if val.Metadata.StreamName == streamName && val.Metadata.StreamID == streamID {
+	for _, val := range s.log {
+		if val.Metadata.StreamName == streamName && val.Metadata.StreamID == streamID && val.Metadata.StreamVersion >= version {
+			e = append(e, val)
+		}
+	}
+	return e, nil
+}
+
+func (s *eventStore) GetStreamToVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+	e := make([]domain.Event, 0)
+	for _, val := range s.log {
+		if val.Metadata.StreamName == streamName && val.Metadata.StreamID == streamID && val.Metadata.StreamVersion < version {
+			e = append(e, val)
+		}
+	}
+	return e, nil
+}
+
+func (s *eventStore) GetStreamAsOf(ctx context.Context, streamID uuid.UUID, streamName string, at time.Time) ([]domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+	e := make([]domain.Event, 0)
+	for _, val := range s.log {
+		if val.Metadata.StreamName == streamName && val.Metadata.StreamID == streamID && !val.Metadata.OccurredAt.After(at) {
 			e = append(e, val)
 		}
 	}
 	return e, nil
 }
 
+func (s *eventStore) ReadAll(ctx context.Context, fromPosition uint64, limit int) ([]domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if fromPosition >= uint64(len(s.log)) || limit <= 0 {
+		return []domain.Event{}, nil
+	}
+	end := fromPosition + uint64(limit)
+	if end > uint64(len(s.log)) {
+		end = uint64(len(s.log))
+	}
+	es := make([]domain.Event, end-fromPosition)
+	copy(es, s.log[fromPosition:end])
+	return es, nil
+}
+
+func (s *eventStore) GetByCorrelationID(ctx context.Context, correlationID string) ([]domain.Event, error) {
+	s.RLock()
+	defer s.RUnlock()
+	es := make([]domain.Event, 0)
+	for _, val := range s.log {
+		if val.Metadata.CorrelationID == correlationID {
+			es = append(es, val)
+		}
+	}
+	return es, nil
+}
+
 // New creates in memory event store
 func New() baseeventstore.EventStore {
 	return &eventStore{
-		events: make(map[string]domain.Event),
+		index:    make(map[string]int),
+		versions: make(map[string]map[int]struct{}),
 	}
 }
+
+func streamKey(streamID uuid.UUID, streamName string) string {
+	return streamName + ":" + streamID.String()
+}
@@ -2,11 +2,14 @@ package eventstore
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	baseeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore"
 )
 
 type rawEventMock struct {
@@ -72,3 +75,174 @@ func TestEventStore(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestEventStoreConcurrencyConflict(t *testing.T) {
+	streamID := uuid.New()
+	streamName := "test"
+
+	e1, err := domain.NewEvent(streamID, streamName, 0, rawEventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e2, err := domain.NewEvent(streamID, streamName, 1, rawEventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	concurrent, err := domain.NewEvent(streamID, streamName, 1, rawEventMock{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	store := New()
+
+	if err := store.Store(ctx, []domain.Event{e1, e2}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Store(ctx, []domain.Event{concurrent}); !errors.Is(err, baseeventstore.ErrConcurrencyConflict) {
+		t.Errorf("expected concurrency conflict, got: %v", err)
+	}
+
+	if _, err := store.Get(ctx, concurrent.ID); !errors.Is(err, baseeventstore.ErrEventNotFound) {
+		t.Errorf("conflicting event should not be stored, got: %v", err)
+	}
+
+	s, err := store.GetStream(ctx, streamID, streamName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 {
+		t.Errorf("expected 2 events in stream, got: %d", len(s))
+	}
+}
+
+func TestEventStoreReadAll(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	events := make([]domain.Event, 0, 3)
+	for v := 0; v < 3; v++ {
+		e, err := domain.NewEvent(uuid.New(), "test", v, rawEventMock{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, e := range events {
+		if e.Position != uint64(i+1) {
+			t.Errorf("expected stored event to have position %d, got %d", i+1, e.Position)
+		}
+	}
+
+	page, err := store.ReadAll(ctx, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].ID != events[0].ID || page[1].ID != events[1].ID {
+		t.Fatalf("unexpected first page: %v", page)
+	}
+
+	page, err = store.ReadAll(ctx, page[1].Position, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].ID != events[2].ID {
+		t.Fatalf("unexpected second page: %v", page)
+	}
+
+	page, err = store.ReadAll(ctx, page[0].Position, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 0 {
+		t.Errorf("expected empty page once caught up, got %d events", len(page))
+	}
+}
+
+func TestEventStoreGetByCorrelationID(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	root, err := domain.NewEventFromContext(ctx, uuid.New(), "test", 0, rawEventMock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caused, err := domain.NewEventFromContext(domain.ContextWithCause(ctx, root), uuid.New(), "test", 0, rawEventMock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unrelated, err := domain.NewEventFromContext(ctx, uuid.New(), "test", 0, rawEventMock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Store(ctx, []domain.Event{root, unrelated, caused}); err != nil {
+		t.Fatal(err)
+	}
+
+	chain, err := store.GetByCorrelationID(ctx, root.Metadata.CorrelationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 2 || chain[0].ID != root.ID || chain[1].ID != caused.ID {
+		t.Fatalf("expected causal chain of root and caused events, got %v", chain)
+	}
+	if chain[1].Metadata.CausationID != root.ID.String() {
+		t.Errorf("CausationID = %s, want %s", chain[1].Metadata.CausationID, root.ID)
+	}
+}
+
+func TestEventStoreTemporalReads(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	streamID := uuid.New()
+	start := time.Now()
+
+	events := make([]domain.Event, 0, 3)
+	for v := 0; v < 3; v++ {
+		e, err := domain.NewEvent(streamID, "test", v, rawEventMock{Page: v}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Metadata.OccurredAt = start.Add(time.Duration(v) * time.Hour)
+		events = append(events, e)
+	}
+
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+
+	toVersion, err := store.GetStreamToVersion(ctx, streamID, "test", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toVersion) != 2 || toVersion[1].ID != events[1].ID {
+		t.Errorf("expected first two events, got %v", toVersion)
+	}
+
+	asOf, err := store.GetStreamAsOf(ctx, streamID, "test", start.Add(30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asOf) != 1 || asOf[0].ID != events[0].ID {
+		t.Errorf("expected first event, got %v", asOf)
+	}
+
+	asOf, err = store.GetStreamAsOf(ctx, streamID, "test", start.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asOf) != 0 {
+		t.Errorf("expected no events before stream began, got %d", len(asOf))
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"database/sql"
 	systemErrors "errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 
 	"github.com/vardius/go-api-boilerplate/pkg/domain"
@@ -16,8 +19,13 @@ import (
 	baseeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore"
 )
 
+// mysqlErrDuplicateEntry is returned when unique key (stream_id, stream_name, stream_version) is violated
+const mysqlErrDuplicateEntry = 1062
+
 type eventStore struct {
 	db *sql.DB
+	// outboxName when set, events are added to the outbox within the same transaction
+	outboxName string
 }
 
 func (s *eventStore) Store(ctx context.Context, events []domain.Event) error {
@@ -26,18 +34,21 @@ func (s *eventStore) Store(ctx context.Context, events []domain.Event) error {
 		return nil
 	}
 
-	query := "INSERT INTO events (event_id, event_type, stream_id, stream_name, stream_version, occurred_at, payload) VALUES "
-	values := make([]interface{}, 0, lenEvents*7)
+	query := "INSERT INTO events (event_id, event_type, stream_id, stream_name, stream_version, schema_version, correlation_id, causation_id, occurred_at, payload) VALUES "
+	values := make([]interface{}, 0, lenEvents*10)
 
 	if lenEvents > 1 {
 		for i := 0; i < lenEvents-1; i++ {
-			query += "(?, ?, ?, ?, ?, ?, ?),"
+			query += "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?),"
 			values = append(values,
 				events[i].ID.String(),
 				events[i].Metadata.Type,
 				events[i].Metadata.StreamID.String(),
 				events[i].Metadata.StreamName,
 				events[i].Metadata.StreamVersion,
+				schemaVersion(events[i].Metadata),
+				nullString(events[i].Metadata.CorrelationID),
+				nullString(events[i].Metadata.CausationID),
 				events[i].Metadata.OccurredAt.UTC(),
 				events[i].Payload,
 			)
@@ -45,108 +56,273 @@ func (s *eventStore) Store(ctx context.Context, events []domain.Event) error {
 	}
 
 	i := lenEvents - 1
-	query += "(?, ?, ?, ?, ?, ?, ?)"
+	query += "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
 	values = append(values,
 		events[i].ID.String(),
 		events[i].Metadata.Type,
 		events[i].Metadata.StreamID.String(),
 		events[i].Metadata.StreamName,
 		events[i].Metadata.StreamVersion,
+		schemaVersion(events[i].Metadata),
+		nullString(events[i].Metadata.CorrelationID),
+		nullString(events[i].Metadata.CausationID),
 		events[i].Metadata.OccurredAt.UTC(),
 		events[i].Payload,
 	)
 
-	stmt, err := s.db.PrepareContext(ctx, query)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return errors.Wrap(err)
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
+
+	if err := lockAppends(ctx, tx); err != nil {
+		return errors.Wrap(err)
+	}
 
-	if _, err := stmt.ExecContext(ctx, values...); err != nil {
+	if _, err := tx.ExecContext(ctx, query, values...); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if systemErrors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return errors.Wrap(fmt.Errorf("%w: %s", baseeventstore.ErrConcurrencyConflict, err))
+		}
+		return errors.Wrap(err)
+	}
+
+	if s.outboxName != "" {
+		if err := s.addToOutbox(ctx, tx, events); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	if err := assignPositions(ctx, tx, events); err != nil {
+		return errors.Wrap(err)
+	}
+
+	if err := tx.Commit(); err != nil {
 		return errors.Wrap(err)
 	}
 
 	return nil
 }
 
-func (s *eventStore) Get(ctx context.Context, id uuid.UUID) (domain.Event, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT event_id, event_type, stream_id, stream_name, stream_version, occurred_at, payload FROM events WHERE event_id=? LIMIT 1`, id.String())
+// addToOutbox adds references of stored events to the outbox so relay can publish them
+func (s *eventStore) addToOutbox(ctx context.Context, tx *sql.Tx, events []domain.Event) error {
+	query := "INSERT INTO outbox (outbox_name, event_id, created_at) VALUES (?, ?, UTC_TIMESTAMP())" + strings.Repeat(", (?, ?, UTC_TIMESTAMP())", len(events)-1)
+	args := make([]interface{}, 0, len(events)*2)
+	for _, e := range events {
+		args = append(args, s.outboxName, e.ID.String())
+	}
 
-	event := domain.Event{}
+	_, err := tx.ExecContext(ctx, query, args...)
 
-	var (
-		eventId  string
-		streamID string
-	)
-	err := row.Scan(
-		&eventId,
-		&event.Metadata.Type,
-		&streamID,
-		&event.Metadata.StreamName,
-		&event.Metadata.StreamVersion,
-		&event.Metadata.OccurredAt,
-		&event.Payload,
-	)
+	return err
+}
+
+func (s *eventStore) Get(ctx context.Context, id uuid.UUID) (domain.Event, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+eventColumns+` FROM events WHERE event_id=? LIMIT 1`, id.String())
+
+	event, err := scanEvent(row)
 
 	switch {
 	case systemErrors.Is(err, sql.ErrNoRows):
 		return event, errors.Wrap(fmt.Errorf("%w: %s", baseeventstore.ErrEventNotFound, err))
 	case err != nil:
-		return event, errors.Wrap(fmt.Errorf(`%w: SELECT event_id, event_type, stream_id, stream_name, stream_version, occurred_at, payload FROM events WHERE id=%s LIMIT 1`, err, id.String()))
+		return event, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE event_id=%s LIMIT 1`, err, id.String()))
 	}
 
-	event.ID = uuid.MustParse(eventId)
-	event.Metadata.StreamID = uuid.MustParse(streamID)
-
 	return event, nil
 }
 
 func (s *eventStore) FindAll(ctx context.Context) ([]domain.Event, error) {
-	return nil, errors.Wrap(fmt.Errorf("should never load all events from mysql"))
+	return nil, errors.Wrap(fmt.Errorf("should never load all events from mysql, use ReadAll to page through events"))
 }
 
 func (s *eventStore) GetStream(ctx context.Context, streamID uuid.UUID, streamName string) ([]domain.Event, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT event_id, event_type, stream_id, stream_name, stream_version, occurred_at, payload FROM events WHERE stream_id=? AND stream_name=? ORDER BY distinct_id ASC`, streamID.String(), streamName)
+	return s.GetStreamFromVersion(ctx, streamID, streamName, 0)
+}
+
+func (s *eventStore) GetStreamFromVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE stream_id=? AND stream_name=? AND stream_version>=? ORDER BY distinct_id ASC`, streamID.String(), streamName, version)
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE stream_id=%s AND stream_name=%s AND stream_version>=%d ORDER BY distinct_id ASC`, err, streamID.String(), streamName, version))
+	}
+
+	return events, nil
+}
+
+func (s *eventStore) GetStreamToVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE stream_id=? AND stream_name=? AND stream_version<? ORDER BY distinct_id ASC`, streamID.String(), streamName, version)
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE stream_id=%s AND stream_name=%s AND stream_version<%d ORDER BY distinct_id ASC`, err, streamID.String(), streamName, version))
+	}
+
+	return events, nil
+}
+
+func (s *eventStore) GetStreamAsOf(ctx context.Context, streamID uuid.UUID, streamName string, at time.Time) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE stream_id=? AND stream_name=? AND occurred_at<=? ORDER BY distinct_id ASC`, streamID.String(), streamName, at.UTC())
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE stream_id=%s AND stream_name=%s AND occurred_at<=%s ORDER BY distinct_id ASC`, err, streamID.String(), streamName, at.UTC()))
+	}
+
+	return events, nil
+}
+
+// ReadAll uses auto increment distinct_id as a global position,
+// appends are serialized by lockAppends so events become visible in position order
+func (s *eventStore) ReadAll(ctx context.Context, fromPosition uint64, limit int) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE distinct_id>? ORDER BY distinct_id ASC LIMIT ?`, fromPosition, limit)
 	if err != nil {
-		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT id, type, stream_id, stream_name, stream_version, occurred_at, payload FROM events WHERE stream_id=%s AND stream_name=%s ORDER BY distinct_id DESC`, err, streamID.String(), streamName))
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE distinct_id>%d ORDER BY distinct_id ASC LIMIT %d`, err, fromPosition, limit))
+	}
+
+	return events, nil
+}
+
+func (s *eventStore) GetByCorrelationID(ctx context.Context, correlationID string) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE correlation_id=? ORDER BY distinct_id ASC`, correlationID)
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE correlation_id=%s ORDER BY distinct_id ASC`, err, correlationID))
+	}
+
+	return events, nil
+}
+
+func (s *eventStore) query(ctx context.Context, query string, args ...interface{}) ([]domain.Event, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
 	defer rows.Close()
 
-	var events []domain.Event
+	events := make([]domain.Event, 0)
 
 	for rows.Next() {
-		event := domain.Event{}
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// lockAppends locks the events_append_lock row until transaction ends,
+// auto increment values are taken after the lock so concurrent appends commit in order of their positions
+// and readers that moved past a position never miss an event committed later with a lower one
+func lockAppends(ctx context.Context, tx *sql.Tx) error {
+	var id int
+	if err := tx.QueryRowContext(ctx, "SELECT id FROM events_append_lock WHERE id=1 FOR UPDATE").Scan(&id); err != nil {
+		return fmt.Errorf("could not lock events append: %w", err)
+	}
+
+	return nil
+}
 
+// assignPositions sets positions of just inserted events,
+// auto increment values of multi row insert are not guaranteed to be consecutive
+func assignPositions(ctx context.Context, tx *sql.Tx, events []domain.Event) error {
+	query := "SELECT event_id, distinct_id FROM events WHERE event_id IN (?" + strings.Repeat(",?", len(events)-1) + ")"
+	args := make([]interface{}, 0, len(events))
+	for _, e := range events {
+		args = append(args, e.ID.String())
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	positions := make(map[string]uint64, len(events))
+	for rows.Next() {
 		var (
 			id       string
-			streamID string
+			position uint64
 		)
-		if err := rows.Scan(
-			&id,
-			&event.Metadata.Type,
-			&streamID,
-			&event.Metadata.StreamName,
-			&event.Metadata.StreamVersion,
-			&event.Metadata.OccurredAt,
-			&event.Payload,
-		); err != nil {
-			return nil, errors.Wrap(err)
+		if err := rows.Scan(&id, &position); err != nil {
+			return err
 		}
+		positions[id] = position
+	}
 
-		event.ID = uuid.MustParse(id)
-		event.Metadata.StreamID = uuid.MustParse(streamID)
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-		events = append(events, event)
+	for i := range events {
+		events[i].Position = positions[events[i].ID.String()]
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, errors.Wrap(err)
+	return nil
+}
+
+const eventColumns = "distinct_id, event_id, event_type, stream_id, stream_name, stream_version, schema_version, correlation_id, causation_id, occurred_at, payload"
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEvent(row scanner) (domain.Event, error) {
+	event := domain.Event{}
+
+	var (
+		id            string
+		streamID      string
+		correlationID sql.NullString
+		causationID   sql.NullString
+	)
+	if err := row.Scan(
+		&event.Position,
+		&id,
+		&event.Metadata.Type,
+		&streamID,
+		&event.Metadata.StreamName,
+		&event.Metadata.StreamVersion,
+		&event.Metadata.SchemaVersion,
+		&correlationID,
+		&causationID,
+		&event.Metadata.OccurredAt,
+		&event.Payload,
+	); err != nil {
+		return event, err
 	}
 
-	return events, nil
+	event.ID = uuid.MustParse(id)
+	event.Metadata.StreamID = uuid.MustParse(streamID)
+	event.Metadata.CorrelationID = correlationID.String
+	event.Metadata.CausationID = causationID.String
+
+	return event, nil
+}
+
+// schemaVersion returns payload schema version to store, events made without one have initial version
+func schemaVersion(meta domain.EventMetaData) int {
+	if meta.SchemaVersion < domain.InitialSchemaVersion {
+		return domain.InitialSchemaVersion
+	}
+
+	return meta.SchemaVersion
+}
+
+// nullString stores empty metadata values as NULL
+func nullString(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
 }
 
 // New creates in mysql event store
 func New(db *sql.DB) baseeventstore.EventStore {
-	return &eventStore{db}
+	return &eventStore{db: db}
+}
+
+// NewWithOutbox creates mysql event store adding stored events to the outbox of given name
+// within the same transaction, see outbox/mysql package
+func NewWithOutbox(db *sql.DB, outboxName string) baseeventstore.EventStore {
+	return &eventStore{db: db, outboxName: outboxName}
 }
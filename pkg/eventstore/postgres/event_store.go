@@ -0,0 +1,316 @@
+/*
+Package eventstore provides postgres implementation of domain event store
+*/
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	systemErrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	baseeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore"
+)
+
+// postgresErrUniqueViolation is returned when unique constraint (stream_id, stream_name, stream_version) is violated
+const postgresErrUniqueViolation = "23505"
+
+// appendLockKey is a key of transaction level advisory lock serializing appends
+const appendLockKey = 1602662402
+
+const eventColumns = "distinct_id, event_id, event_type, stream_id, stream_name, stream_version, schema_version, correlation_id, causation_id, occurred_at, payload"
+
+type eventStore struct {
+	db *sql.DB
+	// outboxName when set, events are added to the outbox within the same transaction
+	outboxName string
+}
+
+func (s *eventStore) Store(ctx context.Context, events []domain.Event) error {
+	lenEvents := len(events)
+	if lenEvents == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, lenEvents)
+	values := make([]interface{}, 0, lenEvents*10)
+
+	for i, e := range events {
+		n := i * 10
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10))
+		values = append(values,
+			e.ID.String(),
+			e.Metadata.Type,
+			e.Metadata.StreamID.String(),
+			e.Metadata.StreamName,
+			e.Metadata.StreamVersion,
+			schemaVersion(e.Metadata),
+			nullString(e.Metadata.CorrelationID),
+			nullString(e.Metadata.CausationID),
+			e.Metadata.OccurredAt.UTC(),
+			[]byte(e.Payload),
+		)
+	}
+
+	query := "INSERT INTO events (event_id, event_type, stream_id, stream_name, stream_version, schema_version, correlation_id, causation_id, occurred_at, payload) VALUES " +
+		strings.Join(placeholders, ", ") +
+		" RETURNING event_id, distinct_id"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer tx.Rollback()
+
+	if err := lockAppends(ctx, tx); err != nil {
+		return errors.Wrap(err)
+	}
+
+	positions, err := insertEvents(ctx, tx, query, values, lenEvents)
+	if err != nil {
+		return errors.Wrap(wrapStoreError(err))
+	}
+
+	if s.outboxName != "" {
+		if err := s.addToOutbox(ctx, tx, events); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(wrapStoreError(err))
+	}
+
+	for i := range events {
+		events[i].Position = positions[events[i].ID.String()]
+	}
+
+	return nil
+}
+
+// lockAppends holds advisory lock until transaction ends, sequence values are taken after the lock
+// so concurrent appends commit in order of their positions
+// and readers that moved past a position never miss an event committed later with a lower one
+func lockAppends(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", appendLockKey); err != nil {
+		return fmt.Errorf("could not lock events append: %w", err)
+	}
+
+	return nil
+}
+
+// insertEvents returns positions of inserted events by event id
+func insertEvents(ctx context.Context, tx *sql.Tx, query string, values []interface{}, lenEvents int) (map[string]uint64, error) {
+	rows, err := tx.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := make(map[string]uint64, lenEvents)
+	for rows.Next() {
+		var (
+			id       string
+			position uint64
+		)
+		if err := rows.Scan(&id, &position); err != nil {
+			return nil, err
+		}
+		positions[id] = position
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return positions, nil
+}
+
+// addToOutbox adds references of stored events to the outbox so relay can publish them
+func (s *eventStore) addToOutbox(ctx context.Context, tx *sql.Tx, events []domain.Event) error {
+	placeholders := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*2)
+	for i, e := range events {
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, NOW())", i*2+1, i*2+2))
+		args = append(args, s.outboxName, e.ID.String())
+	}
+
+	_, err := tx.ExecContext(ctx, "INSERT INTO outbox (outbox_name, event_id, created_at) VALUES "+strings.Join(placeholders, ", "), args...)
+
+	return err
+}
+
+func (s *eventStore) Get(ctx context.Context, id uuid.UUID) (domain.Event, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+eventColumns+` FROM events WHERE event_id=$1 LIMIT 1`, id.String())
+
+	event, err := scanEvent(row)
+
+	switch {
+	case systemErrors.Is(err, sql.ErrNoRows):
+		return event, errors.Wrap(fmt.Errorf("%w: %s", baseeventstore.ErrEventNotFound, err))
+	case err != nil:
+		return event, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE event_id=%s LIMIT 1`, err, id.String()))
+	}
+
+	return event, nil
+}
+
+func (s *eventStore) FindAll(ctx context.Context) ([]domain.Event, error) {
+	return nil, errors.Wrap(fmt.Errorf("should never load all events from postgres, use ReadAll to page through events"))
+}
+
+func (s *eventStore) GetStream(ctx context.Context, streamID uuid.UUID, streamName string) ([]domain.Event, error) {
+	return s.GetStreamFromVersion(ctx, streamID, streamName, 0)
+}
+
+func (s *eventStore) GetStreamFromVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE stream_id=$1 AND stream_name=$2 AND stream_version>=$3 ORDER BY stream_version ASC`, streamID.String(), streamName, version)
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE stream_id=%s AND stream_name=%s AND stream_version>=%d ORDER BY stream_version ASC`, err, streamID.String(), streamName, version))
+	}
+
+	return events, nil
+}
+
+func (s *eventStore) GetStreamToVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE stream_id=$1 AND stream_name=$2 AND stream_version<$3 ORDER BY stream_version ASC`, streamID.String(), streamName, version)
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE stream_id=%s AND stream_name=%s AND stream_version<%d ORDER BY stream_version ASC`, err, streamID.String(), streamName, version))
+	}
+
+	return events, nil
+}
+
+func (s *eventStore) GetStreamAsOf(ctx context.Context, streamID uuid.UUID, streamName string, at time.Time) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE stream_id=$1 AND stream_name=$2 AND occurred_at<=$3 ORDER BY stream_version ASC`, streamID.String(), streamName, at.UTC())
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE stream_id=%s AND stream_name=%s AND occurred_at<=%s ORDER BY stream_version ASC`, err, streamID.String(), streamName, at.UTC()))
+	}
+
+	return events, nil
+}
+
+// ReadAll uses distinct_id sequence as a global position,
+// appends are serialized by lockAppends so events become visible in position order
+func (s *eventStore) ReadAll(ctx context.Context, fromPosition uint64, limit int) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE distinct_id>$1 ORDER BY distinct_id ASC LIMIT $2`, fromPosition, limit)
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE distinct_id>%d ORDER BY distinct_id ASC LIMIT %d`, err, fromPosition, limit))
+	}
+
+	return events, nil
+}
+
+func (s *eventStore) GetByCorrelationID(ctx context.Context, correlationID string) ([]domain.Event, error) {
+	events, err := s.query(ctx, `SELECT `+eventColumns+` FROM events WHERE correlation_id=$1 ORDER BY distinct_id ASC`, correlationID)
+	if err != nil {
+		return nil, errors.Wrap(fmt.Errorf(`%w: SELECT `+eventColumns+` FROM events WHERE correlation_id=%s ORDER BY distinct_id ASC`, err, correlationID))
+	}
+
+	return events, nil
+}
+
+func (s *eventStore) query(ctx context.Context, query string, args ...interface{}) ([]domain.Event, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]domain.Event, 0)
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEvent(row scanner) (domain.Event, error) {
+	event := domain.Event{}
+
+	var (
+		id            string
+		streamID      string
+		correlationID sql.NullString
+		causationID   sql.NullString
+		payload       []byte
+	)
+	if err := row.Scan(
+		&event.Position,
+		&id,
+		&event.Metadata.Type,
+		&streamID,
+		&event.Metadata.StreamName,
+		&event.Metadata.StreamVersion,
+		&event.Metadata.SchemaVersion,
+		&correlationID,
+		&causationID,
+		&event.Metadata.OccurredAt,
+		&payload,
+	); err != nil {
+		return event, err
+	}
+
+	event.ID = uuid.MustParse(id)
+	event.Metadata.StreamID = uuid.MustParse(streamID)
+	event.Metadata.CorrelationID = correlationID.String
+	event.Metadata.CausationID = causationID.String
+	event.Payload = payload
+
+	return event, nil
+}
+
+func wrapStoreError(err error) error {
+	var pqErr *pq.Error
+	if systemErrors.As(err, &pqErr) && pqErr.Code == postgresErrUniqueViolation {
+		return fmt.Errorf("%w: %s", baseeventstore.ErrConcurrencyConflict, err)
+	}
+
+	return err
+}
+
+// schemaVersion returns payload schema version to store, events made without one have initial version
+func schemaVersion(meta domain.EventMetaData) int {
+	if meta.SchemaVersion < domain.InitialSchemaVersion {
+		return domain.InitialSchemaVersion
+	}
+
+	return meta.SchemaVersion
+}
+
+// nullString stores empty metadata values as NULL
+func nullString(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+// New creates postgres event store
+func New(db *sql.DB) baseeventstore.EventStore {
+	return &eventStore{db: db}
+}
+
+// NewWithOutbox creates postgres event store adding stored events to the outbox of given name
+// within the same transaction, see outbox/postgres package
+func NewWithOutbox(db *sql.DB, outboxName string) baseeventstore.EventStore {
+	return &eventStore{db: db, outboxName: outboxName}
+}
@@ -0,0 +1,17 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS "events"
+(
+    "distinct_id"    BIGSERIAL    NOT NULL,
+    "event_id"       UUID         NOT NULL,
+    "event_type"     VARCHAR(255) NOT NULL,
+    "stream_id"      UUID         NOT NULL,
+    "stream_name"    VARCHAR(255) NOT NULL,
+    "stream_version" INT          NOT NULL,
+    "occurred_at"    TIMESTAMPTZ DEFAULT NULL,
+    "payload"        JSON        DEFAULT NULL,
+    PRIMARY KEY ("distinct_id"),
+    CONSTRAINT "u_event_id" UNIQUE ("event_id"),
+    CONSTRAINT "u_stream_id_stream_name_stream_version" UNIQUE ("stream_id", "stream_name", "stream_version")
+);
+CREATE INDEX IF NOT EXISTS "i_stream_id_stream_name_event_type" ON "events" ("stream_id", "stream_name", "event_type");
+COMMIT;
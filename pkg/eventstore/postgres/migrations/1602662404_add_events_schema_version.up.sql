@@ -0,0 +1,4 @@
+START TRANSACTION;
+ALTER TABLE "events"
+    ADD COLUMN IF NOT EXISTS "schema_version" INT NOT NULL DEFAULT 1;
+COMMIT;
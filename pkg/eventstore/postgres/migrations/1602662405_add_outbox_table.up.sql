@@ -0,0 +1,15 @@
+START TRANSACTION;
+CREATE TABLE IF NOT EXISTS "outbox"
+(
+    "distinct_id"   BIGSERIAL    NOT NULL,
+    "outbox_name"   VARCHAR(255) NOT NULL,
+    "event_id"      UUID         NOT NULL,
+    "attempts"      INT          NOT NULL DEFAULT 0,
+    "last_error"    TEXT        DEFAULT NULL,
+    "created_at"    TIMESTAMPTZ  NOT NULL,
+    "dispatched_at" TIMESTAMPTZ DEFAULT NULL,
+    PRIMARY KEY ("distinct_id"),
+    CONSTRAINT "u_outbox_name_event_id" UNIQUE ("outbox_name", "event_id")
+);
+CREATE INDEX IF NOT EXISTS "i_outbox_name_dispatched_at" ON "outbox" ("outbox_name", "dispatched_at");
+COMMIT;
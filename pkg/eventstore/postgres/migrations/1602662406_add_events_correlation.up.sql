@@ -0,0 +1,6 @@
+START TRANSACTION;
+ALTER TABLE "events"
+    ADD COLUMN IF NOT EXISTS "correlation_id" VARCHAR(255) DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS "causation_id"   VARCHAR(255) DEFAULT NULL;
+CREATE INDEX IF NOT EXISTS "i_correlation_id" ON "events" ("correlation_id");
+COMMIT;
@@ -0,0 +1,4 @@
+START TRANSACTION;
+ALTER TABLE "outbox"
+    ADD COLUMN IF NOT EXISTS "claimed_until" TIMESTAMPTZ DEFAULT NULL;
+COMMIT;
@@ -0,0 +1,81 @@
+package eventstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+type upcastingEventStore struct {
+	EventStore
+	registry *domain.UpcasterRegistry
+}
+
+// WithUpcasting wraps event store so events read back from it
+// are upcasted to the latest payload schema version known to the registry,
+// store decrypting personal data has to be wrapped by it so upcasters get plaintext fields
+func WithUpcasting(store EventStore, registry *domain.UpcasterRegistry) EventStore {
+	return &upcastingEventStore{
+		EventStore: store,
+		registry:   registry,
+	}
+}
+
+func (s *upcastingEventStore) Get(ctx context.Context, id uuid.UUID) (domain.Event, error) {
+	e, err := s.EventStore.Get(ctx, id)
+	if err != nil {
+		return e, err
+	}
+
+	e, err = s.registry.Upcast(e)
+	if err != nil {
+		return domain.NullEvent, errors.Wrap(err)
+	}
+
+	return e, nil
+}
+
+func (s *upcastingEventStore) FindAll(ctx context.Context) ([]domain.Event, error) {
+	return s.upcast(s.EventStore.FindAll(ctx))
+}
+
+func (s *upcastingEventStore) GetStream(ctx context.Context, streamID uuid.UUID, streamName string) ([]domain.Event, error) {
+	return s.upcast(s.EventStore.GetStream(ctx, streamID, streamName))
+}
+
+func (s *upcastingEventStore) GetStreamFromVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	return s.upcast(s.EventStore.GetStreamFromVersion(ctx, streamID, streamName, version))
+}
+
+func (s *upcastingEventStore) GetStreamToVersion(ctx context.Context, streamID uuid.UUID, streamName string, version int) ([]domain.Event, error) {
+	return s.upcast(s.EventStore.GetStreamToVersion(ctx, streamID, streamName, version))
+}
+
+func (s *upcastingEventStore) GetStreamAsOf(ctx context.Context, streamID uuid.UUID, streamName string, at time.Time) ([]domain.Event, error) {
+	return s.upcast(s.EventStore.GetStreamAsOf(ctx, streamID, streamName, at))
+}
+
+func (s *upcastingEventStore) ReadAll(ctx context.Context, fromPosition uint64, limit int) ([]domain.Event, error) {
+	return s.upcast(s.EventStore.ReadAll(ctx, fromPosition, limit))
+}
+
+func (s *upcastingEventStore) GetByCorrelationID(ctx context.Context, correlationID string) ([]domain.Event, error) {
+	return s.upcast(s.EventStore.GetByCorrelationID(ctx, correlationID))
+}
+
+func (s *upcastingEventStore) upcast(events []domain.Event, err error) ([]domain.Event, error) {
+	if err != nil {
+		return events, err
+	}
+
+	events, err = s.registry.UpcastAll(events)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return events, nil
+}
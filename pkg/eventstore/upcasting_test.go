@@ -0,0 +1,127 @@
+package eventstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+)
+
+// wasRegisteredV1 is a shape of the event as it was stored before it has evolved
+type wasRegisteredV1 struct {
+	Name string `json:"name"`
+}
+
+func (e wasRegisteredV1) GetType() string {
+	return "test.WasRegistered"
+}
+
+// wasRegistered is the current, second version of the event
+type wasRegistered struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func (e wasRegistered) GetType() string {
+	return "test.WasRegistered"
+}
+
+func (e wasRegistered) GetSchemaVersion() int {
+	return 2
+}
+
+// profile is an aggregate root that only understands the current version of the event
+type profile struct {
+	firstName string
+	lastName  string
+}
+
+func profileFromHistory(t *testing.T, events []domain.Event) profile {
+	var p profile
+	for _, e := range events {
+		if e.Metadata.SchemaVersion != 2 {
+			t.Fatalf("aggregate received event with schema version %d", e.Metadata.SchemaVersion)
+		}
+
+		var payload wasRegistered
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		p.firstName = payload.FirstName
+		p.lastName = payload.LastName
+	}
+
+	return p
+}
+
+func TestWithUpcasting(t *testing.T) {
+	ctx := context.Background()
+	streamID := uuid.New()
+
+	registry := domain.NewUpcasterRegistry()
+	registry.Register((wasRegistered{}).GetType(), 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		var v1 wasRegisteredV1
+		if err := json.Unmarshal(payload, &v1); err != nil {
+			return nil, err
+		}
+
+		var v2 wasRegistered
+		if n, err := fmt.Sscan(v1.Name, &v2.FirstName, &v2.LastName); err != nil && n == 0 {
+			return nil, err
+		}
+
+		return json.Marshal(v2)
+	})
+
+	store := memoryeventstore.New()
+
+	v1, err := domain.NewEvent(streamID, "profile", 0, wasRegisteredV1{Name: "John Doe"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Store(ctx, []domain.Event{v1}); err != nil {
+		t.Fatal(err)
+	}
+
+	upcastingStore := eventstore.WithUpcasting(store, registry)
+
+	events, err := upcastingStore.GetStream(ctx, streamID, "profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := profileFromHistory(t, events)
+	if p.firstName != "John" || p.lastName != "Doe" {
+		t.Errorf("unexpected profile state %+v", p)
+	}
+
+	e, err := upcastingStore.Get(ctx, v1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Metadata.SchemaVersion != 2 {
+		t.Errorf("expected Get to upcast event, got schema version %d", e.Metadata.SchemaVersion)
+	}
+
+	page, err := upcastingStore.ReadAll(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].Metadata.SchemaVersion != 2 {
+		t.Errorf("expected ReadAll to upcast events, got %v", page)
+	}
+
+	stored, err := store.Get(ctx, v1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Metadata.SchemaVersion != 1 || string(stored.Payload) != `{"name":"John Doe"}` {
+		t.Errorf("upcasting must not modify stored events, got %v", stored)
+	}
+}
@@ -2,8 +2,9 @@ package executioncontext
 
 // Execution context flags
 const (
-	LIVE   Flag = 1 << iota // live events handling
-	REPLAY                  // replay events handling
+	LIVE    Flag = 1 << iota // live events handling
+	REPLAY                   // replay events handling
+	REBUILD                  // read model rebuild, handlers only update their projection
 )
 
 // Flag type
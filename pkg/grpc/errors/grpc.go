@@ -25,6 +25,8 @@ func NewGRPCError(err error) error {
 		code = codes.DeadlineExceeded
 	case errors.Is(err, application.ErrTemporaryDisabled):
 		code = codes.Unavailable
+	case errors.Is(err, application.ErrConflict):
+		code = codes.Aborted
 	case errors.Is(err, application.ErrInternal):
 		code = codes.Internal
 	}
@@ -67,6 +67,8 @@ func appError(err error) error {
 		err = fmt.Errorf("%w: %s", application.ErrTimeout, err)
 	case errStatus.Code() == codes.Unavailable:
 		err = fmt.Errorf("%w: %s", application.ErrTemporaryDisabled, err)
+	case errStatus.Code() == codes.Aborted:
+		err = fmt.Errorf("%w: %s", application.ErrConflict, err)
 	default:
 		err = fmt.Errorf("%w: %s", application.ErrInternal, err)
 	}
@@ -80,8 +80,7 @@ func SetMetadataFromStreamRequest() grpc.StreamServerInterceptor {
 
 				m.Now = time.Now()
 
-				// TODO: update server stream context
-				// ctx := mtd.ContextWithMetadata(ss.Context(), &m)
+				ss = &serverStream{ServerStream: ss, ctx: mtd.ContextWithMetadata(ss.Context(), &m)}
 			}
 		}
 
@@ -89,6 +88,17 @@ func SetMetadataFromStreamRequest() grpc.StreamServerInterceptor {
 	}
 }
 
+// serverStream overrides context of wrapped server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns context carrying request metadata
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
 // SetMetadataFromUnaryRequest updates context with metadata
 //
 // 	https://godoc.org/google.golang.org/grpc#UnaryInterceptor
@@ -29,6 +29,8 @@ func NewHttpError(err error) *HttpError {
 		code = http.StatusRequestTimeout
 	case errors.Is(err, application.ErrTemporaryDisabled):
 		code = http.StatusServiceUnavailable
+	case errors.Is(err, application.ErrConflict):
+		code = http.StatusConflict
 	case errors.Is(err, application.ErrInternal):
 		code = http.StatusInternalServerError
 	}
@@ -32,6 +32,13 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 	return
 }
 
+// Flush implements http.Flusher so streaming handlers can send response in parts
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // WithMetadata adds Metadata to requests context
 func WithMetadata() gorouter.MiddlewareFunc {
 	m := func(next http.Handler) http.Handler {
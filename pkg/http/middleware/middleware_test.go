@@ -195,6 +195,30 @@ func TestWithMetadata(t *testing.T) {
 	h.ServeHTTP(w, req)
 }
 
+func TestWithMetadataFlush(t *testing.T) {
+	m := WithMetadata()
+	h := m(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("WithMetadata response writer does not implement http.Flusher")
+		}
+
+		f.Flush()
+	}))
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.ServeHTTP(w, req)
+
+	if !w.Flushed {
+		t.Error("WithMetadata did not flush response")
+	}
+}
+
 func TestWithContainer(t *testing.T) {
 	m := WithContainer(gocontainer.New())
 	h := m(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
@@ -0,0 +1,4 @@
+/*
+Package idempotency provides event handler decorator skipping events the handler already processed
+*/
+package idempotency
@@ -0,0 +1,32 @@
+package idempotency
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// Ledger keeps events processed by each handler
+type Ledger interface {
+	// Process calls fn unless handler already processed event, event is recorded on its own once fn succeeds,
+	// so event is processed at least once: fn is called again if it failed or process died before event was recorded.
+	// Concurrent calls for the same handler and event wait for each other
+	Process(ctx context.Context, handler string, eventID uuid.UUID, fn func(ctx context.Context) error) error
+}
+
+// Handler decorates event handler so event it already processed is skipped
+func Handler(ledger Ledger, name string, fn eventbus.EventHandler) eventbus.EventHandler {
+	return func(ctx context.Context, event domain.Event) error {
+		if err := ledger.Process(ctx, name, event.ID, func(ctx context.Context) error {
+			return fn(ctx, event)
+		}); err != nil {
+			return errors.Wrap(err)
+		}
+
+		return nil
+	}
+}
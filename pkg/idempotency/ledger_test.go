@@ -0,0 +1,50 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+)
+
+type ledgerMock struct {
+	processed map[uuid.UUID]bool
+}
+
+func (l *ledgerMock) Process(ctx context.Context, handler string, eventID uuid.UUID, fn func(ctx context.Context) error) error {
+	if l.processed[eventID] {
+		return nil
+	}
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	l.processed[eventID] = true
+
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	l := &ledgerMock{processed: make(map[uuid.UUID]bool)}
+
+	var handled []uuid.UUID
+	h := Handler(l, "handler", func(ctx context.Context, event domain.Event) error {
+		handled = append(handled, event.ID)
+		return nil
+	})
+
+	first := domain.Event{ID: uuid.New()}
+	second := domain.Event{ID: uuid.New()}
+
+	for _, e := range []domain.Event{first, second, first} {
+		if err := h(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(handled) != 2 || handled[0] != first.ID || handled[1] != second.ID {
+		t.Errorf("expected each event to be handled once, got: %v", handled)
+	}
+}
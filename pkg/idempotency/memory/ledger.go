@@ -0,0 +1,60 @@
+/*
+Package idempotency provides memory implementation of processed events ledger
+*/
+package idempotency
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	baseidempotency "github.com/vardius/go-api-boilerplate/pkg/idempotency"
+)
+
+type key struct {
+	handler string
+	eventID uuid.UUID
+}
+
+type entry struct {
+	mtx       sync.Mutex
+	processed bool
+}
+
+type ledger struct {
+	mtx     sync.Mutex
+	entries map[key]*entry
+}
+
+func (l *ledger) Process(ctx context.Context, handler string, eventID uuid.UUID, fn func(ctx context.Context) error) error {
+	l.mtx.Lock()
+	e, ok := l.entries[key{handler, eventID}]
+	if !ok {
+		e = &entry{}
+		l.entries[key{handler, eventID}] = e
+	}
+	l.mtx.Unlock()
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	if e.processed {
+		return nil
+	}
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+
+	e.processed = true
+
+	return nil
+}
+
+// New creates in memory ledger
+func New() baseidempotency.Ledger {
+	return &ledger{
+		entries: make(map[key]*entry),
+	}
+}
@@ -0,0 +1,90 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestLedgerProcess(t *testing.T) {
+	ctx := context.Background()
+	l := New()
+	eventID := uuid.New()
+
+	var calls int32
+	fn := func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+
+	if err := l.Process(ctx, "handler", eventID, fn); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Process(ctx, "handler", eventID, fn); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("expected event to be processed once, got: %d", calls)
+	}
+
+	if err := l.Process(ctx, "other", eventID, fn); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected other handler to process event, got: %d calls", calls)
+	}
+}
+
+func TestLedgerProcessFailure(t *testing.T) {
+	ctx := context.Background()
+	l := New()
+	eventID := uuid.New()
+
+	if err := l.Process(ctx, "handler", eventID, func(ctx context.Context) error {
+		return fmt.Errorf("failed")
+	}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	var called bool
+	if err := l.Process(ctx, "handler", eventID, func(ctx context.Context) error {
+		called = true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("expected failed event to be processed again")
+	}
+}
+
+func TestLedgerProcessConcurrently(t *testing.T) {
+	ctx := context.Background()
+	l := New()
+	eventID := uuid.New()
+
+	var calls int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := l.Process(ctx, "handler", eventID, func(ctx context.Context) error {
+				atomic.AddInt32(&calls, 1)
+				return nil
+			}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected event to be processed once, got: %d", calls)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Package idempotency provides mysql implementation of processed events ledger
+*/
+package idempotency
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	baseidempotency "github.com/vardius/go-api-boilerplate/pkg/idempotency"
+)
+
+// lockTimeout is a number of seconds Process waits for concurrent call processing the same event
+const lockTimeout = 60
+
+type ledger struct {
+	db *sql.DB
+}
+
+// Process holds named lock of handler and event while calling fn, concurrent call for the same event waits for it
+// without transaction held open while fn calls other services, ledger entry is inserted on its own once fn succeeds
+func (l *ledger) Process(ctx context.Context, handler string, eventID uuid.UUID, fn func(ctx context.Context) error) (err error) {
+	// named lock belongs to session, it has to be released on the same connection
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer conn.Close()
+
+	name := lockName(handler, eventID)
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, name, lockTimeout).Scan(&locked); err != nil {
+		return errors.Wrap(err)
+	}
+	if locked.Int64 != 1 {
+		return errors.Wrap(fmt.Errorf("%w: %s is processing event %s", application.ErrTimeout, handler, eventID))
+	}
+	defer func() {
+		// context may be already cancelled, lock has to be released before connection goes back to the pool
+		if _, releaseErr := conn.ExecContext(context.Background(), `DO RELEASE_LOCK(?)`, name); releaseErr != nil && err == nil {
+			err = errors.Wrap(releaseErr)
+		}
+	}()
+
+	var processed bool
+	if err := conn.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM processed_events WHERE handler=? AND event_id=?)`, handler, eventID.String()).Scan(&processed); err != nil {
+		return errors.Wrap(err)
+	}
+	if processed {
+		return nil
+	}
+
+	if err := fn(ctx); err != nil {
+		return errors.Wrap(err)
+	}
+
+	if _, err := conn.ExecContext(ctx, `INSERT IGNORE INTO processed_events (handler, event_id, processed_at) VALUES (?,?,UTC_TIMESTAMP())`, handler, eventID.String()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// lockName fits handler and event into 64 characters MySQL allows for lock names
+func lockName(handler string, eventID uuid.UUID) string {
+	return fmt.Sprintf("processed_events:%x", sha1.Sum([]byte(handler+":"+eventID.String())))
+}
+
+// New creates mysql ledger
+func New(db *sql.DB) baseidempotency.Ledger {
+	return &ledger{db: db}
+}
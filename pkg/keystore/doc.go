@@ -0,0 +1,4 @@
+/*
+Package keystore provides interfaces along with helper functions
+*/
+package keystore
@@ -0,0 +1,13 @@
+package keystore
+
+import (
+	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+)
+
+// ErrKeyNotFound is thrown when subject has no encryption key.
+var ErrKeyNotFound = fmt.Errorf("%w: encryption key not found", application.ErrNotFound)
+
+// ErrSubjectForgotten is thrown when subject's encryption key was destroyed.
+var ErrSubjectForgotten = fmt.Errorf("%w: subject was forgotten", application.ErrNotFound)
@@ -0,0 +1,86 @@
+package keystore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// KeySize is a size of AES-256 subject key
+const KeySize = 32
+
+// KeyStore methods allow to manage per subject encryption keys
+type KeyStore interface {
+	// GetOrCreate returns subject key creating it on first use,
+	// returns ErrSubjectForgotten if subject key was destroyed
+	GetOrCreate(ctx context.Context, subject uuid.UUID) ([]byte, error)
+	// Get returns subject key, ErrKeyNotFound or ErrSubjectForgotten
+	Get(ctx context.Context, subject uuid.UUID) ([]byte, error)
+	// Forget destroys subject key, data encrypted with it can no longer be decrypted
+	// and no new key will be created for the subject
+	Forget(ctx context.Context, subject uuid.UUID) error
+}
+
+// NewKey generates random subject key
+func NewKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("could not generate key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Encrypt seals plaintext with AES-GCM, random nonce is prepended to the ciphertext
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt opens ciphertext created by Encrypt
+func Decrypt(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("could not create gcm: %w", err)
+	}
+
+	return gcm, nil
+}
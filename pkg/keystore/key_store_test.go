@@ -0,0 +1,39 @@
+package keystore
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte(`"test@test.com"`)
+
+	ciphertext, err := Encrypt(key, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ciphertext, plaintext) {
+		t.Error("ciphertext contains plaintext")
+	}
+
+	decrypted, err := Decrypt(key, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected %s, got %s", plaintext, decrypted)
+	}
+
+	otherKey, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decrypt(otherKey, ciphertext); err == nil {
+		t.Error("expected decryption with other key to fail")
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Package keystore provides memory implementation of subject key store
+*/
+package keystore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	basekeystore "github.com/vardius/go-api-boilerplate/pkg/keystore"
+)
+
+type keyStore struct {
+	sync.RWMutex
+	// keys holds nil value for forgotten subjects
+	keys map[uuid.UUID][]byte
+}
+
+func (s *keyStore) GetOrCreate(ctx context.Context, subject uuid.UUID) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	key, ok := s.keys[subject]
+	if ok && key == nil {
+		return nil, errors.Wrap(basekeystore.ErrSubjectForgotten)
+	}
+	if ok {
+		return key, nil
+	}
+
+	key, err := basekeystore.NewKey()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	s.keys[subject] = key
+
+	return key, nil
+}
+
+func (s *keyStore) Get(ctx context.Context, subject uuid.UUID) ([]byte, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	key, ok := s.keys[subject]
+	if !ok {
+		return nil, errors.Wrap(basekeystore.ErrKeyNotFound)
+	}
+	if key == nil {
+		return nil, errors.Wrap(basekeystore.ErrSubjectForgotten)
+	}
+
+	return key, nil
+}
+
+func (s *keyStore) Forget(ctx context.Context, subject uuid.UUID) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.keys[subject] = nil
+
+	return nil
+}
+
+// New creates in memory key store
+func New() basekeystore.KeyStore {
+	return &keyStore{
+		keys: make(map[uuid.UUID][]byte),
+	}
+}
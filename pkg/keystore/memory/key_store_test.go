@@ -0,0 +1,57 @@
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	basekeystore "github.com/vardius/go-api-boilerplate/pkg/keystore"
+)
+
+func TestNew(t *testing.T) {
+	store := New()
+
+	if store == nil {
+		t.Fail()
+	}
+}
+
+func TestKeyStore(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	subject := uuid.New()
+
+	if _, err := store.Get(ctx, subject); !errors.Is(err, basekeystore.ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	key, err := store.GetOrCreate(ctx, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != basekeystore.KeySize {
+		t.Errorf("expected key of size %d, got %d", basekeystore.KeySize, len(key))
+	}
+
+	same, err := store.GetOrCreate(ctx, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, same) {
+		t.Error("expected the same key for the same subject")
+	}
+
+	if err := store.Forget(ctx, subject); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(ctx, subject); !errors.Is(err, basekeystore.ErrSubjectForgotten) {
+		t.Errorf("expected ErrSubjectForgotten, got %v", err)
+	}
+	if _, err := store.GetOrCreate(ctx, subject); !errors.Is(err, basekeystore.ErrSubjectForgotten) {
+		t.Errorf("expected forgotten subject key not to be recreated, got %v", err)
+	}
+}
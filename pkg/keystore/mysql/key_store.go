@@ -0,0 +1,69 @@
+/*
+Package keystore provides mysql implementation of subject key store
+*/
+package keystore
+
+import (
+	"context"
+	"database/sql"
+	systemErrors "errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	basekeystore "github.com/vardius/go-api-boilerplate/pkg/keystore"
+)
+
+type keyStore struct {
+	db *sql.DB
+}
+
+func (s *keyStore) GetOrCreate(ctx context.Context, subject uuid.UUID) ([]byte, error) {
+	key, err := basekeystore.NewKey()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	// keeps existing key or tombstone of forgotten subject untouched
+	if _, err := s.db.ExecContext(ctx, `INSERT IGNORE INTO subject_keys (subject_id, subject_key) VALUES (?,?)`, subject.String(), key); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return s.Get(ctx, subject)
+}
+
+func (s *keyStore) Get(ctx context.Context, subject uuid.UUID) ([]byte, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT subject_key FROM subject_keys WHERE subject_id=? LIMIT 1`, subject.String())
+
+	var key []byte
+	if err := row.Scan(&key); err != nil {
+		if systemErrors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(fmt.Errorf("%w: %s", basekeystore.ErrKeyNotFound, err))
+		}
+		return nil, errors.Wrap(err)
+	}
+	if key == nil {
+		return nil, errors.Wrap(basekeystore.ErrSubjectForgotten)
+	}
+
+	return key, nil
+}
+
+func (s *keyStore) Forget(ctx context.Context, subject uuid.UUID) error {
+	if _, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO subject_keys (subject_id, subject_key, forgotten_at) VALUES (?,NULL,UTC_TIMESTAMP())
+		ON DUPLICATE KEY UPDATE subject_key=NULL, forgotten_at=UTC_TIMESTAMP()`,
+		subject.String(),
+	); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// New creates mysql key store
+func New(db *sql.DB) basekeystore.KeyStore {
+	return &keyStore{db}
+}
@@ -18,6 +18,8 @@ type Metadata struct {
 	Now        time.Time `json:"-"`
 	TraceID    string    `json:"trace_id"`
 	StatusCode int       `json:"statusCode"`
+	// CausationID is an id of event being handled, empty when processing external request
+	CausationID string `json:"causation_id,omitempty"`
 }
 
 func New() *Metadata {
@@ -0,0 +1,24 @@
+package mysql
+
+import "context"
+
+// tableKey is how table replacement is stored/retrieved.
+type tableKey struct {
+	table string
+}
+
+// ContextWithTable returns a new Context that redirects queries of table to replacement,
+// e.g. to shadow table projection is rebuilt into
+func ContextWithTable(ctx context.Context, table, replacement string) context.Context {
+	return context.WithValue(ctx, tableKey{table}, replacement)
+}
+
+// TableFromContext returns name of the table queries of given table should use,
+// repositories use it so their queries can be redirected by the caller
+func TableFromContext(ctx context.Context, table string) string {
+	if replacement, ok := ctx.Value(tableKey{table}).(string); ok {
+		return replacement
+	}
+
+	return table
+}
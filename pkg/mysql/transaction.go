@@ -0,0 +1,65 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+// txKey is how transaction is stored/retrieved.
+type txKey struct{}
+
+// Executor is implemented by both *sql.DB and *sql.Tx
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ContextWithTx returns a new Context that carries transaction
+func ContextWithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns transaction stored in ctx, if any
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+
+	return tx, ok
+}
+
+// ExecutorFromContext returns transaction stored in ctx or db if there is none,
+// repositories use it so their writes join transaction of the caller
+func ExecutorFromContext(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// InTransaction calls fn with context carrying new transaction committed once fn succeeds,
+// if ctx already carries transaction fn joins it and caller commits it
+func InTransaction(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(ContextWithTx(ctx, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
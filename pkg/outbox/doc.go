@@ -0,0 +1,4 @@
+/*
+Package outbox provides transactional outbox interfaces along with relay publishing stored events
+*/
+package outbox
@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+type deferredEventBus struct {
+	eventbus.EventBus
+	outbox       Outbox
+	claimTimeout time.Duration
+}
+
+// WithDeferredPublish wraps event bus used by repositories when events are published by the relay,
+// Publish is a no-op as the event is already waiting in the outbox,
+// PublishAndAcknowledge claims the message, publishes the event right away and marks it as dispatched
+// so callers still block until handlers are finished, claim timeout has to exceed handling time.
+// Event claimed by the relay first is published by the relay without waiting for its handlers
+func WithDeferredPublish(bus eventbus.EventBus, outbox Outbox, claimTimeout time.Duration) eventbus.EventBus {
+	if claimTimeout <= 0 {
+		claimTimeout = DefaultClaimTimeout
+	}
+
+	return &deferredEventBus{
+		EventBus:     bus,
+		outbox:       outbox,
+		claimTimeout: claimTimeout,
+	}
+}
+
+func (b *deferredEventBus) Publish(ctx context.Context, event domain.Event) error {
+	return nil
+}
+
+func (b *deferredEventBus) PublishAndAcknowledge(ctx context.Context, event domain.Event) error {
+	claimed, err := b.outbox.ClaimEvent(ctx, event.ID, b.claimTimeout)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := b.EventBus.PublishAndAcknowledge(ctx, event); err != nil {
+		if markErr := b.outbox.MarkFailed(ctx, event.ID, err); markErr != nil {
+			return errors.Wrap(fmt.Errorf("%v: could not mark message as failed: %w", err, markErr))
+		}
+
+		return err
+	}
+
+	if err := b.outbox.MarkDispatched(ctx, event.ID); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
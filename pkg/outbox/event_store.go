@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+)
+
+type appendingEventStore struct {
+	eventstore.EventStore
+	outbox Appender
+}
+
+// WithOutbox wraps event store that cannot share transaction with the outbox (memory, file),
+// events are appended to the outbox right after they are stored.
+// Stores backed by sql database should add events to the outbox within their own transaction instead.
+func WithOutbox(store eventstore.EventStore, outbox Appender) eventstore.EventStore {
+	return &appendingEventStore{
+		EventStore: store,
+		outbox:     outbox,
+	}
+}
+
+func (s *appendingEventStore) Store(ctx context.Context, events []domain.Event) error {
+	if err := s.EventStore.Store(ctx, events); err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+
+	if err := s.outbox.Append(ctx, ids); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
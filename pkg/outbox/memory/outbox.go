@@ -0,0 +1,164 @@
+/*
+Package outbox provides memory implementation of transactional outbox
+*/
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	baseoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox"
+)
+
+// Outbox keeps pending messages in memory,
+// use it with outbox.WithOutbox wrapped event store
+type Outbox struct {
+	mtx      sync.RWMutex
+	messages []baseoutbox.Message
+	// claims holds expiration time of claimed messages by event id
+	claims map[uuid.UUID]time.Time
+}
+
+// Append adds events to the outbox
+func (o *Outbox) Append(ctx context.Context, eventIDs []uuid.UUID) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	now := time.Now()
+	for _, id := range eventIDs {
+		o.messages = append(o.messages, baseoutbox.Message{
+			EventID:   id,
+			CreatedAt: now,
+		})
+	}
+
+	return nil
+}
+
+// Pending returns messages waiting to be published
+func (o *Outbox) Pending(ctx context.Context, maxAttempts, limit int) ([]baseoutbox.Message, error) {
+	o.mtx.RLock()
+	defer o.mtx.RUnlock()
+
+	messages := make([]baseoutbox.Message, 0)
+	for _, m := range o.messages {
+		if len(messages) >= limit {
+			break
+		}
+		if maxAttempts > 0 && m.Attempts >= maxAttempts {
+			continue
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, nil
+}
+
+// Claim returns messages waiting to be published that are not claimed already and claims them
+func (o *Outbox) Claim(ctx context.Context, maxAttempts, limit int, timeout time.Duration) ([]baseoutbox.Message, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	now := time.Now()
+	messages := make([]baseoutbox.Message, 0)
+	for _, m := range o.messages {
+		if len(messages) >= limit {
+			break
+		}
+		if maxAttempts > 0 && m.Attempts >= maxAttempts {
+			continue
+		}
+		if o.isClaimed(m.EventID, now) {
+			continue
+		}
+		o.claims[m.EventID] = now.Add(timeout)
+		messages = append(messages, m)
+	}
+
+	return messages, nil
+}
+
+// ClaimEvent claims message of given event if it is waiting to be published and is not claimed already
+func (o *Outbox) ClaimEvent(ctx context.Context, eventID uuid.UUID, timeout time.Duration) (bool, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	now := time.Now()
+	for _, m := range o.messages {
+		if m.EventID != eventID {
+			continue
+		}
+		if o.isClaimed(eventID, now) {
+			return false, nil
+		}
+		o.claims[eventID] = now.Add(timeout)
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (o *Outbox) isClaimed(eventID uuid.UUID, now time.Time) bool {
+	until, ok := o.claims[eventID]
+
+	return ok && until.After(now)
+}
+
+// Release releases claim of message
+func (o *Outbox) Release(ctx context.Context, eventID uuid.UUID) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	delete(o.claims, eventID)
+
+	return nil
+}
+
+// MarkDispatched removes message from the outbox
+func (o *Outbox) MarkDispatched(ctx context.Context, eventID uuid.UUID) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	delete(o.claims, eventID)
+
+	for i, m := range o.messages {
+		if m.EventID == eventID {
+			o.messages = append(o.messages[:i], o.messages[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// MarkFailed records failed publish attempt and releases the claim
+func (o *Outbox) MarkFailed(ctx context.Context, eventID uuid.UUID, reason error) error {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+
+	delete(o.claims, eventID)
+
+	for i, m := range o.messages {
+		if m.EventID == eventID {
+			o.messages[i].Attempts++
+			o.messages[i].LastError = reason.Error()
+			return nil
+		}
+	}
+
+	return errors.Wrap(fmt.Errorf("%w: outbox message %s", application.ErrNotFound, eventID))
+}
+
+// New creates in memory outbox
+func New() *Outbox {
+	return &Outbox{
+		claims: make(map[uuid.UUID]time.Time),
+	}
+}
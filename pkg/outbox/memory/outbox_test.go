@@ -0,0 +1,124 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNew(t *testing.T) {
+	o := New()
+
+	if o == nil {
+		t.Fail()
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+	o := New()
+	first, second := uuid.New(), uuid.New()
+
+	if err := o.Append(ctx, []uuid.UUID{first, second}); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := o.Pending(ctx, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].EventID != first {
+		t.Fatalf("expected first message, got %v", pending)
+	}
+
+	if err := o.MarkFailed(ctx, first, errors.New("publish failed")); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err = o.Pending(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].EventID != second {
+		t.Errorf("expected message over max attempts to be skipped, got %v", pending)
+	}
+
+	pending, err = o.Pending(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].Attempts != 1 || pending[0].LastError != "publish failed" {
+		t.Errorf("expected failed attempt to be recorded, got %v", pending)
+	}
+
+	if err := o.MarkDispatched(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.MarkDispatched(ctx, first); err != nil {
+		t.Errorf("marking dispatched message again should not fail, got %v", err)
+	}
+
+	pending, err = o.Pending(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].EventID != second {
+		t.Errorf("expected only second message to be pending, got %v", pending)
+	}
+}
+
+func TestOutboxClaim(t *testing.T) {
+	ctx := context.Background()
+	o := New()
+	first, second := uuid.New(), uuid.New()
+
+	if err := o.Append(ctx, []uuid.UUID{first, second}); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := o.ClaimEvent(ctx, first, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !claimed {
+		t.Fatal("expected pending message to be claimed")
+	}
+
+	if claimed, err = o.ClaimEvent(ctx, first, time.Minute); err != nil || claimed {
+		t.Errorf("expected claimed message not to be claimed again, got %v %v", claimed, err)
+	}
+
+	messages, err := o.Claim(ctx, 0, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].EventID != second {
+		t.Fatalf("expected only not claimed message, got %v", messages)
+	}
+
+	if messages, err = o.Claim(ctx, 0, 10, time.Minute); err != nil || len(messages) != 0 {
+		t.Errorf("expected all messages to be claimed, got %v %v", messages, err)
+	}
+
+	if err := o.MarkFailed(ctx, first, errors.New("publish failed")); err != nil {
+		t.Fatal(err)
+	}
+	if messages, err = o.Claim(ctx, 0, 10, -time.Second); err != nil || len(messages) != 1 || messages[0].EventID != first {
+		t.Fatalf("expected failed message claim to be released, got %v %v", messages, err)
+	}
+
+	// claim with timeout in the past has already expired
+	if messages, err = o.Claim(ctx, 0, 10, time.Minute); err != nil || len(messages) != 1 || messages[0].EventID != first {
+		t.Errorf("expected expired claim to be claimed again, got %v %v", messages, err)
+	}
+
+	if err := o.MarkDispatched(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if claimed, err = o.ClaimEvent(ctx, second, time.Minute); err != nil || claimed {
+		t.Errorf("expected dispatched message not to be claimed, got %v %v", claimed, err)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Package outbox provides mysql implementation of transactional outbox
+*/
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	baseoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox"
+)
+
+type outbox struct {
+	db   *sql.DB
+	name string
+}
+
+func (o *outbox) Pending(ctx context.Context, maxAttempts, limit int) ([]baseoutbox.Message, error) {
+	query := `SELECT event_id, attempts, last_error, created_at FROM outbox WHERE outbox_name=? AND dispatched_at IS NULL`
+	args := []interface{}{o.name}
+	if maxAttempts > 0 {
+		query += ` AND attempts<?`
+		args = append(args, maxAttempts)
+	}
+	query += ` ORDER BY distinct_id ASC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return scanMessages(rows)
+}
+
+// Claim marks messages with a claim id of the call and reads them back,
+// concurrent claims wait for each other row locks and skip rows claimed meanwhile
+func (o *outbox) Claim(ctx context.Context, maxAttempts, limit int, timeout time.Duration) ([]baseoutbox.Message, error) {
+	claimID := uuid.New().String()
+
+	query := `UPDATE outbox SET claim_id=?, claimed_until=DATE_ADD(UTC_TIMESTAMP(6), INTERVAL ? MICROSECOND) WHERE outbox_name=? AND dispatched_at IS NULL AND (claimed_until IS NULL OR claimed_until<UTC_TIMESTAMP(6))`
+	args := []interface{}{claimID, timeout.Microseconds(), o.name}
+	if maxAttempts > 0 {
+		query += ` AND attempts<?`
+		args = append(args, maxAttempts)
+	}
+	query += ` ORDER BY distinct_id ASC LIMIT ?`
+	args = append(args, limit)
+
+	if _, err := o.db.ExecContext(ctx, query, args...); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	rows, err := o.db.QueryContext(ctx, `SELECT event_id, attempts, last_error, created_at FROM outbox WHERE outbox_name=? AND claim_id=? ORDER BY distinct_id ASC`, o.name, claimID)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return scanMessages(rows)
+}
+
+func (o *outbox) ClaimEvent(ctx context.Context, eventID uuid.UUID, timeout time.Duration) (bool, error) {
+	result, err := o.db.ExecContext(ctx, `UPDATE outbox SET claim_id=?, claimed_until=DATE_ADD(UTC_TIMESTAMP(6), INTERVAL ? MICROSECOND) WHERE outbox_name=? AND event_id=? AND dispatched_at IS NULL AND (claimed_until IS NULL OR claimed_until<UTC_TIMESTAMP(6))`, uuid.New().String(), timeout.Microseconds(), o.name, eventID.String())
+	if err != nil {
+		return false, errors.Wrap(err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err)
+	}
+
+	return claimed == 1, nil
+}
+
+func (o *outbox) Release(ctx context.Context, eventID uuid.UUID) error {
+	if _, err := o.db.ExecContext(ctx, `UPDATE outbox SET claim_id=NULL, claimed_until=NULL WHERE outbox_name=? AND event_id=?`, o.name, eventID.String()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (o *outbox) MarkDispatched(ctx context.Context, eventID uuid.UUID) error {
+	if _, err := o.db.ExecContext(ctx, `UPDATE outbox SET dispatched_at=UTC_TIMESTAMP() WHERE outbox_name=? AND event_id=? AND dispatched_at IS NULL`, o.name, eventID.String()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (o *outbox) MarkFailed(ctx context.Context, eventID uuid.UUID, reason error) error {
+	if _, err := o.db.ExecContext(ctx, `UPDATE outbox SET attempts=attempts+1, last_error=?, claim_id=NULL, claimed_until=NULL WHERE outbox_name=? AND event_id=?`, reason.Error(), o.name, eventID.String()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func scanMessages(rows *sql.Rows) ([]baseoutbox.Message, error) {
+	defer rows.Close()
+
+	messages := make([]baseoutbox.Message, 0)
+	for rows.Next() {
+		var (
+			m         baseoutbox.Message
+			eventID   string
+			lastError sql.NullString
+			err       error
+		)
+		if err := rows.Scan(&eventID, &m.Attempts, &lastError, &m.CreatedAt); err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		m.EventID, err = uuid.Parse(eventID)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		m.LastError = lastError.String
+
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return messages, nil
+}
+
+// New creates mysql outbox of given name,
+// events are added to it by event store created with mysql eventstore.NewWithOutbox using the same name
+func New(db *sql.DB, name string) baseoutbox.Outbox {
+	return &outbox{db: db, name: name}
+}
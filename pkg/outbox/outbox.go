@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is a reference to stored event waiting to be published
+type Message struct {
+	EventID   uuid.UUID
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+}
+
+// Outbox methods allow relay to fetch and acknowledge events waiting to be published,
+// message is claimed before it is published so it is not published by other relays meanwhile
+type Outbox interface {
+	// Pending returns up to limit not dispatched messages, in order they were added,
+	// that failed less than maxAttempts times, maxAttempts of 0 means no limit,
+	// claimed messages are included so it is meant for inspection, relay uses Claim
+	Pending(ctx context.Context, maxAttempts, limit int) ([]Message, error)
+	// Claim returns up to limit pending messages, in order they were added, that are not claimed already
+	// and claims them for given timeout, after which they can be claimed again if not marked meanwhile
+	Claim(ctx context.Context, maxAttempts, limit int, timeout time.Duration) ([]Message, error)
+	// ClaimEvent claims pending message of given event for given timeout,
+	// false means message is dispatched or claimed already
+	ClaimEvent(ctx context.Context, eventID uuid.UUID, timeout time.Duration) (bool, error)
+	// Release releases claim of message that was not published so it can be claimed again right away
+	Release(ctx context.Context, eventID uuid.UUID) error
+	// MarkDispatched removes message from pending ones, marking already dispatched message is not an error
+	MarkDispatched(ctx context.Context, eventID uuid.UUID) error
+	// MarkFailed records failed publish attempt and releases the claim so message is retried
+	MarkFailed(ctx context.Context, eventID uuid.UUID, reason error) error
+}
+
+// Appender is implemented by outboxes that have to be filled outside of event store transaction
+type Appender interface {
+	Append(ctx context.Context, eventIDs []uuid.UUID) error
+}
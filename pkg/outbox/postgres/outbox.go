@@ -0,0 +1,139 @@
+/*
+Package outbox provides postgres implementation of transactional outbox
+*/
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	baseoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox"
+)
+
+type outbox struct {
+	db   *sql.DB
+	name string
+}
+
+func (o *outbox) Pending(ctx context.Context, maxAttempts, limit int) ([]baseoutbox.Message, error) {
+	query := `SELECT event_id, attempts, last_error, created_at FROM outbox WHERE outbox_name=$1 AND dispatched_at IS NULL`
+	args := []interface{}{o.name}
+	if maxAttempts > 0 {
+		args = append(args, maxAttempts)
+		query += fmt.Sprintf(` AND attempts<$%d`, len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY distinct_id ASC LIMIT $%d`, len(args))
+
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return scanMessages(rows)
+}
+
+// Claim marks messages as claimed and returns them, rows locked by concurrent claims are skipped
+func (o *outbox) Claim(ctx context.Context, maxAttempts, limit int, timeout time.Duration) ([]baseoutbox.Message, error) {
+	args := []interface{}{timeout.Seconds(), o.name}
+	pending := `SELECT distinct_id FROM outbox WHERE outbox_name=$2 AND dispatched_at IS NULL AND (claimed_until IS NULL OR claimed_until<NOW())`
+	if maxAttempts > 0 {
+		args = append(args, maxAttempts)
+		pending += fmt.Sprintf(` AND attempts<$%d`, len(args))
+	}
+	args = append(args, limit)
+	pending += fmt.Sprintf(` ORDER BY distinct_id ASC LIMIT $%d FOR UPDATE SKIP LOCKED`, len(args))
+
+	query := `WITH claimed AS (
+UPDATE outbox SET claimed_until=NOW() + $1::float8 * INTERVAL '1 second' WHERE distinct_id IN (` + pending + `)
+RETURNING distinct_id, event_id, attempts, last_error, created_at
+)
+SELECT event_id, attempts, last_error, created_at FROM claimed ORDER BY distinct_id ASC`
+
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return scanMessages(rows)
+}
+
+func (o *outbox) ClaimEvent(ctx context.Context, eventID uuid.UUID, timeout time.Duration) (bool, error) {
+	result, err := o.db.ExecContext(ctx, `UPDATE outbox SET claimed_until=NOW() + $1::float8 * INTERVAL '1 second' WHERE outbox_name=$2 AND event_id=$3 AND dispatched_at IS NULL AND (claimed_until IS NULL OR claimed_until<NOW())`, timeout.Seconds(), o.name, eventID.String())
+	if err != nil {
+		return false, errors.Wrap(err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err)
+	}
+
+	return claimed == 1, nil
+}
+
+func (o *outbox) Release(ctx context.Context, eventID uuid.UUID) error {
+	if _, err := o.db.ExecContext(ctx, `UPDATE outbox SET claimed_until=NULL WHERE outbox_name=$1 AND event_id=$2`, o.name, eventID.String()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (o *outbox) MarkDispatched(ctx context.Context, eventID uuid.UUID) error {
+	if _, err := o.db.ExecContext(ctx, `UPDATE outbox SET dispatched_at=NOW() WHERE outbox_name=$1 AND event_id=$2 AND dispatched_at IS NULL`, o.name, eventID.String()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (o *outbox) MarkFailed(ctx context.Context, eventID uuid.UUID, reason error) error {
+	if _, err := o.db.ExecContext(ctx, `UPDATE outbox SET attempts=attempts+1, last_error=$1, claimed_until=NULL WHERE outbox_name=$2 AND event_id=$3`, reason.Error(), o.name, eventID.String()); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func scanMessages(rows *sql.Rows) ([]baseoutbox.Message, error) {
+	defer rows.Close()
+
+	messages := make([]baseoutbox.Message, 0)
+	for rows.Next() {
+		var (
+			m         baseoutbox.Message
+			eventID   string
+			lastError sql.NullString
+			err       error
+		)
+		if err := rows.Scan(&eventID, &m.Attempts, &lastError, &m.CreatedAt); err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		m.EventID, err = uuid.Parse(eventID)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		m.LastError = lastError.String
+
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return messages, nil
+}
+
+// New creates postgres outbox of given name,
+// events are added to it by event store created with postgres eventstore.NewWithOutbox using the same name
+func New(db *sql.DB, name string) baseoutbox.Outbox {
+	return &outbox{db: db, name: name}
+}
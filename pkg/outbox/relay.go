@@ -0,0 +1,173 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// RelayConfig configures outbox relay
+type RelayConfig struct {
+	// PollInterval is a time to wait before checking outbox again once it was drained or publishing failed
+	PollInterval time.Duration
+	// BatchSize is a number of messages fetched from outbox at once
+	BatchSize int
+	// MaxAttempts is a number of failed publish attempts after which message is left in outbox for inspection,
+	// 0 means retry forever
+	MaxAttempts int
+	// ClaimTimeout is a time messages stay claimed by the relay, messages left by a relay that stopped
+	// are published by others after it, it has to exceed time of publishing the whole batch
+	ClaimTimeout time.Duration
+}
+
+// DefaultClaimTimeout is a claim timeout used when none is configured
+const DefaultClaimTimeout = 30 * time.Second
+
+// Relay is an app adapter publishing events waiting in the outbox
+type Relay struct {
+	outbox Outbox
+	store  eventstore.EventStore
+	bus    eventbus.EventBus
+	logger *log.Logger
+	cfg    RelayConfig
+
+	mtx    sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRelay provides new outbox relay adapter,
+// events are loaded from the given store so it should be wrapped with the same decorators repositories use
+func NewRelay(outbox Outbox, store eventstore.EventStore, bus eventbus.EventBus, logger *log.Logger, cfg RelayConfig) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.ClaimTimeout <= 0 {
+		cfg.ClaimTimeout = DefaultClaimTimeout
+	}
+
+	return &Relay{
+		outbox: outbox,
+		store:  store,
+		bus:    bus,
+		logger: logger,
+		cfg:    cfg,
+	}
+}
+
+// Start polls the outbox until relay is stopped
+func (r *Relay) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	defer close(done)
+
+	r.mtx.Lock()
+	r.cancel = cancel
+	r.done = done
+	r.mtx.Unlock()
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error(ctx, "[outbox relay] %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops polling and waits for currently published batch
+func (r *Relay) Stop(ctx context.Context) error {
+	r.mtx.Lock()
+	cancel, done := r.cancel, r.done
+	r.mtx.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err())
+	}
+}
+
+// Dispatch claims and publishes pending messages until outbox is drained,
+// stops at first failure so events are published in order they were stored
+func (r *Relay) Dispatch(ctx context.Context) (int, error) {
+	var dispatched int
+
+	for {
+		messages, err := r.outbox.Claim(ctx, r.cfg.MaxAttempts, r.cfg.BatchSize, r.cfg.ClaimTimeout)
+		if err != nil {
+			return dispatched, errors.Wrap(err)
+		}
+
+		for i, m := range messages {
+			if err := r.publish(ctx, m); err != nil {
+				if markErr := r.outbox.MarkFailed(ctx, m.EventID, err); markErr != nil {
+					return dispatched, errors.Wrap(fmt.Errorf("%v: could not mark message as failed: %w", err, markErr))
+				}
+				// messages after the failed one are released so they are not published before it
+				if releaseErr := r.release(ctx, messages[i+1:]); releaseErr != nil {
+					return dispatched, errors.Wrap(fmt.Errorf("%v: %w", err, releaseErr))
+				}
+
+				return dispatched, errors.Wrap(err)
+			}
+
+			if err := r.outbox.MarkDispatched(ctx, m.EventID); err != nil {
+				return dispatched, errors.Wrap(err)
+			}
+
+			dispatched++
+		}
+
+		if len(messages) < r.cfg.BatchSize {
+			return dispatched, nil
+		}
+	}
+}
+
+func (r *Relay) release(ctx context.Context, messages []Message) error {
+	for _, m := range messages {
+		if err := r.outbox.Release(ctx, m.EventID); err != nil {
+			return fmt.Errorf("could not release message %s: %w", m.EventID, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Relay) publish(ctx context.Context, m Message) error {
+	event, err := r.store.Get(ctx, m.EventID)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if err := r.bus.Publish(executioncontext.WithFlag(ctx, executioncontext.LIVE), event); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
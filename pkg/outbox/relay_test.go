@@ -0,0 +1,262 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/outbox"
+	memoryoutbox "github.com/vardius/go-api-boilerplate/pkg/outbox/memory"
+)
+
+type rawEventMock struct {
+	Page int `json:"page"`
+}
+
+func (e rawEventMock) GetType() string {
+	return "test.Mock"
+}
+
+// busMock records published events and fails first publish attempts
+type busMock struct {
+	eventbus.EventBus
+	mtx       sync.Mutex
+	failures  int
+	published []domain.Event
+}
+
+func (b *busMock) Publish(ctx context.Context, event domain.Event) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if !executioncontext.Has(ctx, executioncontext.LIVE) {
+		return errors.New("relayed events should be published as live")
+	}
+
+	if b.failures > 0 {
+		b.failures--
+		return errors.New("bus unavailable")
+	}
+
+	b.published = append(b.published, event)
+
+	return nil
+}
+
+func (b *busMock) PublishAndAcknowledge(ctx context.Context, event domain.Event) error {
+	return b.Publish(ctx, event)
+}
+
+func (b *busMock) Published() []domain.Event {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return append([]domain.Event(nil), b.published...)
+}
+
+func newTestEvents(t *testing.T, count int) []domain.Event {
+	streamID := uuid.New()
+	events := make([]domain.Event, 0, count)
+	for v := 0; v < count; v++ {
+		e, err := domain.NewEvent(streamID, "test", v, rawEventMock{Page: v}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+
+	return events
+}
+
+func TestRelayDispatch(t *testing.T) {
+	ctx := context.Background()
+	o := memoryoutbox.New()
+	store := outbox.WithOutbox(memoryeventstore.New(), o)
+	bus := &busMock{failures: 1}
+	relay := outbox.NewRelay(o, store, bus, log.New("test"), outbox.RelayConfig{BatchSize: 2})
+
+	events := newTestEvents(t, 3)
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := relay.Dispatch(ctx); err == nil {
+		t.Fatal("expected publish failure to be returned")
+	}
+
+	pending, err := o.Pending(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 3 || pending[0].Attempts != 1 {
+		t.Fatalf("expected failed message to stay in outbox, got %v", pending)
+	}
+
+	n, err := relay.Dispatch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 dispatched messages, got %d", n)
+	}
+
+	published := bus.Published()
+	if len(published) != 3 {
+		t.Fatalf("expected 3 published events, got %d", len(published))
+	}
+	for i, e := range published {
+		if e.ID != events[i].ID {
+			t.Errorf("expected events to be published in order they were stored, got %v at %d", e.ID, i)
+		}
+	}
+
+	pending, err = o.Pending(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected outbox to be drained, got %v", pending)
+	}
+}
+
+func TestRelayStartStop(t *testing.T) {
+	ctx := context.Background()
+	o := memoryoutbox.New()
+	store := outbox.WithOutbox(memoryeventstore.New(), o)
+	bus := &busMock{failures: 2}
+	relay := outbox.NewRelay(o, store, bus, log.New("test"), outbox.RelayConfig{PollInterval: time.Millisecond})
+
+	go func() {
+		if err := relay.Start(ctx); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if err := store.Store(ctx, newTestEvents(t, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(bus.Published()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if len(bus.Published()) != 1 {
+		t.Error("expected relay to retry publishing until it succeeds")
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if err := relay.Stop(stopCtx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithDeferredPublish(t *testing.T) {
+	ctx := context.Background()
+	o := memoryoutbox.New()
+	store := outbox.WithOutbox(memoryeventstore.New(), o)
+	bus := &busMock{}
+	deferred := outbox.WithDeferredPublish(bus, o, time.Minute)
+
+	events := newTestEvents(t, 2)
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := deferred.Publish(ctx, events[0]); err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.Published()) != 0 {
+		t.Error("publish should be deferred to the relay")
+	}
+
+	if err := deferred.PublishAndAcknowledge(executioncontext.WithFlag(ctx, executioncontext.LIVE), events[1]); err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.Published()) != 1 {
+		t.Error("acknowledged publish should not be deferred")
+	}
+
+	pending, err := o.Pending(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].EventID != events[0].ID {
+		t.Errorf("expected acknowledged event to be marked as dispatched, got %v", pending)
+	}
+}
+
+func TestWithDeferredPublishClaimedByRelay(t *testing.T) {
+	ctx := executioncontext.WithFlag(context.Background(), executioncontext.LIVE)
+	o := memoryoutbox.New()
+	store := outbox.WithOutbox(memoryeventstore.New(), o)
+	bus := &busMock{}
+	deferred := outbox.WithDeferredPublish(bus, o, time.Minute)
+
+	events := newTestEvents(t, 1)
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+
+	// relay claimed the message between event was stored and acknowledged publish
+	claimed, err := o.Claim(ctx, 0, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 {
+		t.Fatalf("expected relay to claim stored event, got %v", claimed)
+	}
+
+	if err := deferred.PublishAndAcknowledge(ctx, events[0]); err != nil {
+		t.Fatal(err)
+	}
+	if len(bus.Published()) != 0 {
+		t.Error("event claimed by the relay should not be published again")
+	}
+}
+
+func TestRelayDispatchConcurrently(t *testing.T) {
+	ctx := context.Background()
+	o := memoryoutbox.New()
+	store := outbox.WithOutbox(memoryeventstore.New(), o)
+	bus := &busMock{}
+
+	events := newTestEvents(t, 50)
+	if err := store.Store(ctx, events); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			relay := outbox.NewRelay(o, store, bus, log.New("test"), outbox.RelayConfig{BatchSize: 5})
+			if _, err := relay.Dispatch(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	published := make(map[uuid.UUID]int)
+	for _, e := range bus.Published() {
+		published[e.ID]++
+	}
+	for _, e := range events {
+		if published[e.ID] != 1 {
+			t.Errorf("expected event %s to be published once, got %d", e.ID, published[e.ID])
+		}
+	}
+}
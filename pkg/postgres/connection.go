@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// ConnectionConfig provides values for postgres connection configuration
+type ConnectionConfig struct {
+	Host            string
+	Port            int
+	User            string
+	Pass            string
+	Database        string
+	SSLMode         string
+	ConnMaxLifetime time.Duration
+	MaxIdleConns    int
+	MaxOpenConns    int
+}
+
+// NewConnection provides new postgres connection
+func NewConnection(ctx context.Context, cfg ConnectionConfig, logger *log.Logger) (db *sql.DB) {
+	db, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", cfg.Host, cfg.Port, cfg.User, cfg.Pass, cfg.Database, cfg.SSLMode))
+	if err != nil {
+		logger.Critical(ctx, "[POSTGRES|Connection] %v\n", err)
+		os.Exit(1)
+	}
+
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+
+	return db
+}
@@ -0,0 +1,48 @@
+package projection
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/http/response"
+)
+
+// NewDebugHandler provides http handler for debug adapter,
+// GET lists rebuild progress of all projections, POST ?name=<projection> starts rebuild of projection in background
+func NewDebugHandler(runner *Runner, barrier Barrier) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if err := response.JSON(r.Context(), w, http.StatusOK, runner.Projections()); err != nil {
+				response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			}
+		case http.MethodPost:
+			name := r.URL.Query().Get("name")
+			if name == "" {
+				response.MustJSONError(r.Context(), w, errors.Wrap(fmt.Errorf("%w: name query parameter is required", application.ErrInvalid)))
+				return
+			}
+
+			if err := runner.Start(name, barrier); err != nil {
+				response.MustJSONError(r.Context(), w, errors.Wrap(err))
+				return
+			}
+
+			progress, err := runner.Progress(name)
+			if err != nil {
+				response.MustJSONError(r.Context(), w, errors.Wrap(err))
+				return
+			}
+
+			if err := response.JSON(r.Context(), w, http.StatusAccepted, progress); err != nil {
+				response.MustJSONError(r.Context(), w, errors.Wrap(err))
+			}
+		default:
+			response.NotAllowed().ServeHTTP(w, r)
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,4 @@
+/*
+Package projection provides runner rebuilding read models by replaying event log into their handlers
+*/
+package projection
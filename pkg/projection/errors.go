@@ -0,0 +1,13 @@
+package projection
+
+import (
+	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+)
+
+// ErrProjectionNotFound is returned when projection was not registered with runner
+var ErrProjectionNotFound = fmt.Errorf("%w: projection", application.ErrNotFound)
+
+// ErrRebuildInProgress is returned when projection is already being rebuilt
+var ErrRebuildInProgress = fmt.Errorf("%w: projection rebuild in progress", application.ErrConflict)
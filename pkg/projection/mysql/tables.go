@@ -0,0 +1,68 @@
+/*
+Package projection provides mysql implementation of projection shadow tables
+*/
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/mysql"
+	"github.com/vardius/go-api-boilerplate/pkg/projection"
+)
+
+const (
+	shadowSuffix   = "_rebuild"
+	replacedSuffix = "_replaced"
+)
+
+type tables struct {
+	db *sql.DB
+}
+
+// Prepare creates shadow table with the same structure as table, previous shadow table is dropped
+func (t *tables) Prepare(ctx context.Context, table string) (context.Context, error) {
+	shadow := table + shadowSuffix
+
+	if _, err := t.db.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS `%s`", shadow)); err != nil {
+		return ctx, errors.Wrap(err)
+	}
+	if _, err := t.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE `%s` LIKE `%s`", shadow, table)); err != nil {
+		return ctx, errors.Wrap(err)
+	}
+
+	return mysql.ContextWithTable(ctx, table, shadow), nil
+}
+
+// Swap renames both tables in one statement so readers never see table missing
+func (t *tables) Swap(ctx context.Context, table string) error {
+	shadow := table + shadowSuffix
+	replaced := table + replacedSuffix
+
+	if _, err := t.db.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS `%s`", replaced)); err != nil {
+		return errors.Wrap(err)
+	}
+	if _, err := t.db.ExecContext(ctx, fmt.Sprintf("RENAME TABLE `%s` TO `%s`, `%s` TO `%s`", table, replaced, shadow, table)); err != nil {
+		return errors.Wrap(err)
+	}
+	if _, err := t.db.ExecContext(ctx, fmt.Sprintf("DROP TABLE `%s`", replaced)); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (t *tables) Discard(ctx context.Context, table string) error {
+	if _, err := t.db.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS `%s`", table+shadowSuffix)); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// New creates mysql projection tables
+func New(db *sql.DB) projection.Tables {
+	return &tables{db: db}
+}
@@ -0,0 +1,60 @@
+package projection
+
+import (
+	"context"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// Projection is a read model stored in a single table and built by event handlers
+type Projection struct {
+	// Name identifies projection to rebuild
+	Name string
+	// Table read model is stored in, handlers have to resolve it with mysql.TableFromContext
+	Table string
+	// Handlers by event type or pattern, see eventbus.Topics
+	Handlers map[string]eventbus.EventHandler
+}
+
+// Tables manages shadow tables projections are rebuilt into
+type Tables interface {
+	// Prepare creates empty shadow copy of table, returned context redirects handler queries to it
+	Prepare(ctx context.Context, table string) (context.Context, error)
+	// Swap atomically replaces table with its shadow copy
+	Swap(ctx context.Context, table string) error
+	// Discard drops shadow copy of table
+	Discard(ctx context.Context, table string) error
+}
+
+// Barrier is implemented by consumer group keeping projection up to date with live events
+type Barrier interface {
+	// Position returns global position of the last event handled by projection handlers
+	Position() uint64
+	// Exclusive calls fn while projection handlers are held, fn gets position of the last event they handled
+	Exclusive(ctx context.Context, fn func(ctx context.Context, position uint64) error) error
+}
+
+// Status of projection rebuild
+type Status string
+
+// Projection rebuild statuses
+const (
+	StatusIdle    Status = "idle"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Progress of projection rebuild
+type Progress struct {
+	Projection string `json:"projection"`
+	Status     Status `json:"status"`
+	// Position of the last replayed event
+	Position uint64 `json:"position"`
+	// Handled is a number of replayed events projection has handlers for
+	Handled    int       `json:"handled"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+}
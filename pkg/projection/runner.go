@@ -0,0 +1,275 @@
+package projection
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// Config configures projection runner
+type Config struct {
+	// BatchSize is a number of events read from event store at once
+	BatchSize int
+}
+
+// Runner rebuilds projections from event log
+type Runner struct {
+	store  eventstore.EventStore
+	tables Tables
+	logger *log.Logger
+	cfg    Config
+
+	mtx         sync.RWMutex
+	projections map[string]Projection
+	progress    map[string]Progress
+}
+
+// NewRunner provides new projection runner,
+// events are read from the given store so it should be wrapped with the same decorators repositories use
+func NewRunner(store eventstore.EventStore, tables Tables, logger *log.Logger, cfg Config, projections ...Projection) *Runner {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	r := &Runner{
+		store:       store,
+		tables:      tables,
+		logger:      logger,
+		cfg:         cfg,
+		projections: make(map[string]Projection, len(projections)),
+		progress:    make(map[string]Progress, len(projections)),
+	}
+
+	for _, p := range projections {
+		r.projections[p.Name] = p
+		r.progress[p.Name] = Progress{Projection: p.Name, Status: StatusIdle}
+	}
+
+	return r
+}
+
+// Projections returns progress of the last rebuild of every projection ordered by name
+func (r *Runner) Projections() []Progress {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	list := make([]Progress, 0, len(r.progress))
+	for _, p := range r.progress {
+		list = append(list, p)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Projection < list[j].Projection
+	})
+
+	return list
+}
+
+// Progress returns progress of the last rebuild of projection
+func (r *Runner) Progress(name string) (Progress, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	p, ok := r.progress[name]
+	if !ok {
+		return Progress{}, errors.Wrap(ErrProjectionNotFound)
+	}
+
+	return p, nil
+}
+
+// Rebuild replays event log into shadow table of projection and swaps it in once it caught up.
+// Events are handled without executioncontext.LIVE flag and with executioncontext.REBUILD flag
+// so handlers do not cause side effects. Barrier holds live handlers of projection while the last events
+// are replayed and tables are swapped, without barrier projection must not be updated until rebuild is done
+func (r *Runner) Rebuild(ctx context.Context, name string, barrier Barrier) error {
+	p, err := r.begin(name)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	err = r.rebuild(ctx, p, barrier)
+	r.finish(ctx, p, err)
+
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// Start rebuilds projection in background, see Rebuild, its progress is reported by Progress
+func (r *Runner) Start(name string, barrier Barrier) error {
+	p, err := r.begin(name)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	go func() {
+		// rebuild outlives the request that started it
+		ctx := context.Background()
+
+		r.finish(ctx, p, r.rebuild(ctx, p, barrier))
+	}()
+
+	return nil
+}
+
+func (r *Runner) begin(name string) (Projection, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	p, ok := r.projections[name]
+	if !ok {
+		return Projection{}, errors.Wrap(ErrProjectionNotFound)
+	}
+	if r.progress[name].Status == StatusRunning {
+		return Projection{}, errors.Wrap(ErrRebuildInProgress)
+	}
+
+	r.progress[name] = Progress{
+		Projection: name,
+		Status:     StatusRunning,
+		StartedAt:  time.Now(),
+	}
+
+	return p, nil
+}
+
+func (r *Runner) finish(ctx context.Context, p Projection, err error) {
+	r.mtx.Lock()
+	progress := r.progress[p.Name]
+	progress.FinishedAt = time.Now()
+	progress.Status = StatusDone
+	if err != nil {
+		progress.Status = StatusFailed
+		progress.Error = err.Error()
+	}
+	r.progress[p.Name] = progress
+	r.mtx.Unlock()
+
+	if err != nil {
+		r.logger.Error(ctx, "[projection] %s: rebuild failed: %v\n", p.Name, err)
+		return
+	}
+
+	r.logger.Info(ctx, "[projection] %s: rebuilt from %d events up to position %d\n", p.Name, progress.Handled, progress.Position)
+}
+
+func (r *Runner) rebuild(ctx context.Context, p Projection, barrier Barrier) error {
+	shadowCtx, err := r.tables.Prepare(ctx, p.Table)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	replayCtx := executioncontext.ClearFlag(shadowCtx, executioncontext.LIVE)
+	replayCtx = executioncontext.WithFlag(replayCtx, executioncontext.REPLAY)
+	replayCtx = executioncontext.WithFlag(replayCtx, executioncontext.REBUILD)
+
+	if barrier == nil {
+		err = r.replayAndSwap(ctx, replayCtx, p, 0, math.MaxUint64)
+	} else {
+		// most events are replayed while live handlers keep running, they are held only for the rest
+		var position uint64
+		position, err = r.replay(replayCtx, p, 0, barrier.Position())
+		if err == nil {
+			err = barrier.Exclusive(ctx, func(ctx context.Context, livePosition uint64) error {
+				return r.replayAndSwap(ctx, replayCtx, p, position, livePosition)
+			})
+		}
+	}
+
+	if err != nil {
+		// shadow table is dropped regardless of cancelled context
+		if discardErr := r.tables.Discard(context.Background(), p.Table); discardErr != nil {
+			r.logger.Error(ctx, "[projection] %s: could not discard shadow table: %v\n", p.Name, discardErr)
+		}
+
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (r *Runner) replayAndSwap(ctx, replayCtx context.Context, p Projection, from, to uint64) error {
+	if _, err := r.replay(replayCtx, p, from, to); err != nil {
+		return errors.Wrap(err)
+	}
+
+	if err := r.tables.Swap(ctx, p.Table); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+// replay handles events with position in (from, to] until event store is drained, returns position it stopped at
+func (r *Runner) replay(ctx context.Context, p Projection, from, to uint64) (uint64, error) {
+	position := from
+	for position < to {
+		events, err := r.store.ReadAll(ctx, position, r.cfg.BatchSize)
+		if err != nil {
+			return position, errors.Wrap(err)
+		}
+
+		var handled int
+		for _, e := range events {
+			if e.Position > to {
+				r.report(ctx, p, position, handled)
+				return position, nil
+			}
+
+			fns := handlersOf(p, e)
+			for _, fn := range fns {
+				if err := fn(domain.ContextWithCause(ctx, e), e); err != nil {
+					return position, errors.Wrap(err)
+				}
+			}
+			if len(fns) > 0 {
+				handled++
+			}
+
+			position = e.Position
+		}
+
+		r.report(ctx, p, position, handled)
+
+		if len(events) < r.cfg.BatchSize {
+			break
+		}
+	}
+
+	return position, nil
+}
+
+func (r *Runner) report(ctx context.Context, p Projection, position uint64, handled int) {
+	r.mtx.Lock()
+	progress := r.progress[p.Name]
+	progress.Position = position
+	progress.Handled += handled
+	r.progress[p.Name] = progress
+	r.mtx.Unlock()
+
+	r.logger.Info(ctx, "[projection] %s: replayed up to position %d, %d events handled\n", p.Name, position, progress.Handled)
+}
+
+// handlersOf returns projection handlers of every topic event is delivered to
+func handlersOf(p Projection, event domain.Event) []eventbus.EventHandler {
+	var fns []eventbus.EventHandler
+	for _, topic := range eventbus.Topics(event) {
+		if fn, ok := p.Handlers[topic]; ok {
+			fns = append(fns, fn)
+		}
+	}
+
+	return fns
+}
@@ -0,0 +1,274 @@
+package projection_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/eventstore"
+	memoryeventstore "github.com/vardius/go-api-boilerplate/pkg/eventstore/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/executioncontext"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/mysql"
+	"github.com/vardius/go-api-boilerplate/pkg/projection"
+)
+
+type rawEventMock struct{}
+
+func (e rawEventMock) GetType() string {
+	return "test.Mock"
+}
+
+type otherRawEventMock struct{}
+
+func (e otherRawEventMock) GetType() string {
+	return "test.Other"
+}
+
+type tablesMock struct {
+	mtx      sync.Mutex
+	prepared []string
+	swapped  []string
+	dropped  []string
+	// swapHook is called on swap, e.g. to check barrier is held
+	swapHook func()
+}
+
+func (t *tablesMock) Prepare(ctx context.Context, table string) (context.Context, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.prepared = append(t.prepared, table)
+
+	return mysql.ContextWithTable(ctx, table, table+"_shadow"), nil
+}
+
+func (t *tablesMock) Swap(ctx context.Context, table string) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.swapHook != nil {
+		t.swapHook()
+	}
+	t.swapped = append(t.swapped, table)
+
+	return nil
+}
+
+func (t *tablesMock) Discard(ctx context.Context, table string) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.dropped = append(t.dropped, table)
+
+	return nil
+}
+
+type barrierMock struct {
+	position  uint64
+	live      uint64
+	exclusive bool
+}
+
+func (b *barrierMock) Position() uint64 {
+	return b.position
+}
+
+func (b *barrierMock) Exclusive(ctx context.Context, fn func(ctx context.Context, position uint64) error) error {
+	b.exclusive = true
+	defer func() { b.exclusive = false }()
+
+	return fn(ctx, b.live)
+}
+
+// recorder records events replayed into projection
+type recorder struct {
+	mtx       sync.Mutex
+	positions []uint64
+	tables    []string
+	live      bool
+	rebuild   bool
+	fail      bool
+	block     chan struct{}
+}
+
+func (r *recorder) handle(ctx context.Context, event domain.Event) error {
+	if r.block != nil {
+		<-r.block
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.fail {
+		return errors.New("handler failed")
+	}
+
+	r.positions = append(r.positions, event.Position)
+	r.tables = append(r.tables, mysql.TableFromContext(ctx, "table"))
+	r.live = r.live || executioncontext.Has(ctx, executioncontext.LIVE)
+	r.rebuild = executioncontext.Has(ctx, executioncontext.REBUILD)
+
+	return nil
+}
+
+func storeEvents(t *testing.T, store eventstore.EventStore, rawEvents ...domain.RawEvent) {
+	t.Helper()
+
+	events := make([]domain.Event, len(rawEvents))
+	for i, rawEvent := range rawEvents {
+		e, err := domain.NewEvent(uuid.New(), "test", 0, rawEvent, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events[i] = e
+	}
+
+	if err := store.Store(context.Background(), events); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newRunner(store eventstore.EventStore, tables projection.Tables, r *recorder) *projection.Runner {
+	return projection.NewRunner(store, tables, log.New("development"), projection.Config{BatchSize: 2}, projection.Projection{
+		Name:  "test",
+		Table: "table",
+		Handlers: map[string]eventbus.EventHandler{
+			(rawEventMock{}).GetType(): r.handle,
+		},
+	})
+}
+
+func TestRebuild(t *testing.T) {
+	ctx := executioncontext.WithFlag(context.Background(), executioncontext.LIVE)
+	store := memoryeventstore.New()
+	storeEvents(t, store, rawEventMock{}, otherRawEventMock{}, rawEventMock{}, rawEventMock{}, otherRawEventMock{})
+
+	tables := &tablesMock{}
+	r := &recorder{}
+	runner := newRunner(store, tables, r)
+
+	if err := runner.Rebuild(ctx, "test", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.positions) != 3 || r.positions[0] != 1 || r.positions[1] != 3 || r.positions[2] != 4 {
+		t.Errorf("expected events 1, 3 and 4 to be replayed, got: %v", r.positions)
+	}
+	for _, table := range r.tables {
+		if table != "table_shadow" {
+			t.Errorf("expected events to be replayed into shadow table, got: %s", table)
+		}
+	}
+	if r.live || !r.rebuild {
+		t.Error("expected events to be replayed without live flag and with rebuild flag")
+	}
+	if len(tables.swapped) != 1 || len(tables.dropped) != 0 {
+		t.Errorf("expected shadow table to be swapped in, swapped: %v dropped: %v", tables.swapped, tables.dropped)
+	}
+
+	progress, err := runner.Progress("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Status != projection.StatusDone || progress.Handled != 3 || progress.Position != 5 {
+		t.Errorf("unexpected progress: %+v", progress)
+	}
+}
+
+func TestRebuildWithBarrier(t *testing.T) {
+	ctx := context.Background()
+	store := memoryeventstore.New()
+	storeEvents(t, store, rawEventMock{}, rawEventMock{}, rawEventMock{}, rawEventMock{}, rawEventMock{})
+
+	// live handlers handled 3 events when rebuild started and 4 by the time they are held, 5th is not handled yet
+	barrier := &barrierMock{position: 3, live: 4}
+	tables := &tablesMock{}
+	tables.swapHook = func() {
+		if !barrier.exclusive {
+			t.Error("expected tables to be swapped while barrier is held")
+		}
+	}
+	r := &recorder{}
+	runner := newRunner(store, tables, r)
+
+	if err := runner.Rebuild(ctx, "test", barrier); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.positions) != 4 || r.positions[3] != 4 {
+		t.Errorf("expected events up to live position to be replayed, got: %v", r.positions)
+	}
+	if len(tables.swapped) != 1 {
+		t.Errorf("expected shadow table to be swapped in, got: %v", tables.swapped)
+	}
+}
+
+func TestRebuildFailure(t *testing.T) {
+	ctx := context.Background()
+	store := memoryeventstore.New()
+	storeEvents(t, store, rawEventMock{})
+
+	tables := &tablesMock{}
+	r := &recorder{fail: true}
+	runner := newRunner(store, tables, r)
+
+	if err := runner.Rebuild(ctx, "test", nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if len(tables.swapped) != 0 || len(tables.dropped) != 1 {
+		t.Errorf("expected shadow table to be discarded, swapped: %v dropped: %v", tables.swapped, tables.dropped)
+	}
+
+	progress, err := runner.Progress("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Status != projection.StatusFailed || progress.Error == "" {
+		t.Errorf("unexpected progress: %+v", progress)
+	}
+}
+
+func TestStart(t *testing.T) {
+	store := memoryeventstore.New()
+	storeEvents(t, store, rawEventMock{})
+
+	r := &recorder{block: make(chan struct{})}
+	runner := newRunner(store, &tablesMock{}, r)
+
+	if err := runner.Start("test", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Start("test", nil); !errors.Is(err, projection.ErrRebuildInProgress) {
+		t.Errorf("expected rebuild in progress error, got: %v", err)
+	}
+	if err := runner.Start("unknown", nil); !errors.Is(err, projection.ErrProjectionNotFound) {
+		t.Errorf("expected projection not found error, got: %v", err)
+	}
+
+	close(r.block)
+
+	deadline := time.After(time.Second)
+	for {
+		progress, err := runner.Progress("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if progress.Status == projection.StatusDone {
+			return
+		}
+
+		select {
+		case <-deadline:
+			t.Fatalf("rebuild did not finish: %+v", progress)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,4 @@
+/*
+Package snapshotstore provides interfaces along with helper functions
+*/
+package snapshotstore
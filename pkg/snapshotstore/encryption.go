@@ -0,0 +1,77 @@
+package snapshotstore
+
+import (
+	"context"
+	"encoding/json"
+	systemErrors "errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/keystore"
+)
+
+type encryptedPayload struct {
+	Ciphertext []byte `json:"$pii"`
+}
+
+type encryptingSnapshotStore struct {
+	SnapshotStore
+	keyStore keystore.KeyStore
+}
+
+// WithEncryption wraps snapshot store so snapshot payloads are encrypted with a key of the stream,
+// snapshots of forgotten subjects are reported as not found so aggregate roots are rebuilt from events
+func WithEncryption(store SnapshotStore, keyStore keystore.KeyStore) SnapshotStore {
+	return &encryptingSnapshotStore{
+		SnapshotStore: store,
+		keyStore:      keyStore,
+	}
+}
+
+func (s *encryptingSnapshotStore) Store(ctx context.Context, snapshot Snapshot) error {
+	key, err := s.keyStore.GetOrCreate(ctx, snapshot.StreamID)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	ciphertext, err := keystore.Encrypt(key, snapshot.Payload)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	snapshot.Payload, err = json.Marshal(encryptedPayload{Ciphertext: ciphertext})
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	return s.SnapshotStore.Store(ctx, snapshot)
+}
+
+func (s *encryptingSnapshotStore) Get(ctx context.Context, streamID uuid.UUID, streamName string) (Snapshot, error) {
+	snapshot, err := s.SnapshotStore.Get(ctx, streamID, streamName)
+	if err != nil {
+		return snapshot, err
+	}
+
+	var payload encryptedPayload
+	if err := json.Unmarshal(snapshot.Payload, &payload); err != nil {
+		return Snapshot{}, errors.Wrap(err)
+	}
+
+	key, err := s.keyStore.Get(ctx, streamID)
+	if err != nil {
+		if systemErrors.Is(err, keystore.ErrSubjectForgotten) || systemErrors.Is(err, keystore.ErrKeyNotFound) {
+			return Snapshot{}, errors.Wrap(fmt.Errorf("%w: %s", ErrSnapshotNotFound, err))
+		}
+		return Snapshot{}, errors.Wrap(err)
+	}
+
+	snapshot.Payload, err = keystore.Decrypt(key, payload.Ciphertext)
+	if err != nil {
+		return Snapshot{}, errors.Wrap(err)
+	}
+
+	return snapshot, nil
+}
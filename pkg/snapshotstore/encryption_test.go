@@ -0,0 +1,58 @@
+package snapshotstore_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	memorykeystore "github.com/vardius/go-api-boilerplate/pkg/keystore/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
+	memorysnapshotstore "github.com/vardius/go-api-boilerplate/pkg/snapshotstore/memory"
+)
+
+func TestWithEncryption(t *testing.T) {
+	ctx := context.Background()
+	streamID := uuid.New()
+
+	inner := memorysnapshotstore.New()
+	keyStore := memorykeystore.New()
+	store := snapshotstore.WithEncryption(inner, keyStore)
+
+	if err := store.Store(ctx, snapshotstore.Snapshot{
+		StreamID:      streamID,
+		StreamName:    "test",
+		StreamVersion: 1,
+		TakenAt:       time.Now(),
+		Payload:       []byte(`{"email":"test@test.com"}`),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := inner.Get(ctx, streamID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw.Payload), "test@test.com") {
+		t.Errorf("snapshot payload should be encrypted at rest, got %s", raw.Payload)
+	}
+
+	s, err := store.Get(ctx, streamID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(s.Payload) != `{"email":"test@test.com"}` || s.StreamVersion != 1 {
+		t.Errorf("unexpected snapshot %v", s)
+	}
+
+	if err := keyStore.Forget(ctx, streamID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(ctx, streamID, "test"); !errors.Is(err, snapshotstore.ErrSnapshotNotFound) {
+		t.Errorf("expected ErrSnapshotNotFound for forgotten subject, got %v", err)
+	}
+}
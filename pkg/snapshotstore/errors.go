@@ -0,0 +1,10 @@
+package snapshotstore
+
+import (
+	"fmt"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+)
+
+// ErrSnapshotNotFound is thrown when there is no snapshot of a stream in the store.
+var ErrSnapshotNotFound = fmt.Errorf("%w: snapshot not found", application.ErrNotFound)
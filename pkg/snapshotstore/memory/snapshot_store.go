@@ -0,0 +1,56 @@
+/*
+Package snapshotstore provides memory implementation of aggregate snapshot store
+*/
+package snapshotstore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	basesnapshotstore "github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
+)
+
+type snapshotStore struct {
+	sync.RWMutex
+	snapshots map[string]basesnapshotstore.Snapshot
+}
+
+func (s *snapshotStore) Store(ctx context.Context, snapshot basesnapshotstore.Snapshot) error {
+	s.Lock()
+	defer s.Unlock()
+
+	key := streamKey(snapshot.StreamID, snapshot.StreamName)
+	if current, ok := s.snapshots[key]; ok && current.StreamVersion >= snapshot.StreamVersion {
+		return nil
+	}
+
+	s.snapshots[key] = snapshot
+
+	return nil
+}
+
+func (s *snapshotStore) Get(ctx context.Context, streamID uuid.UUID, streamName string) (basesnapshotstore.Snapshot, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	snapshot, ok := s.snapshots[streamKey(streamID, streamName)]
+	if !ok {
+		return basesnapshotstore.Snapshot{}, errors.Wrap(basesnapshotstore.ErrSnapshotNotFound)
+	}
+
+	return snapshot, nil
+}
+
+func streamKey(streamID uuid.UUID, streamName string) string {
+	return streamName + ":" + streamID.String()
+}
+
+// New creates in memory snapshot store
+func New() basesnapshotstore.SnapshotStore {
+	return &snapshotStore{
+		snapshots: make(map[string]basesnapshotstore.Snapshot),
+	}
+}
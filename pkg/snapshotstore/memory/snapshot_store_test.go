@@ -0,0 +1,56 @@
+package snapshotstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	basesnapshotstore "github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
+)
+
+func TestNew(t *testing.T) {
+	store := New()
+
+	if store == nil {
+		t.Fail()
+	}
+}
+
+func TestSnapshotStore(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	streamID := uuid.New()
+
+	if _, err := store.Get(ctx, streamID, "test"); !errors.Is(err, basesnapshotstore.ErrSnapshotNotFound) {
+		t.Fatalf("expected ErrSnapshotNotFound, got %v", err)
+	}
+
+	// snapshot at version 4 is saved last by slower concurrent save
+	for _, version := range []int{3, 6, 4} {
+		if err := store.Store(ctx, basesnapshotstore.Snapshot{
+			StreamID:      streamID,
+			StreamName:    "test",
+			StreamVersion: version,
+			TakenAt:       time.Now(),
+			Payload:       json.RawMessage(`{}`),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshot, err := store.Get(ctx, streamID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.StreamVersion != 6 {
+		t.Errorf("expected latest snapshot at version 6, got %d", snapshot.StreamVersion)
+	}
+
+	if _, err := store.Get(ctx, streamID, "other"); !errors.Is(err, basesnapshotstore.ErrSnapshotNotFound) {
+		t.Errorf("expected ErrSnapshotNotFound for other stream name, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Package snapshotstore provides mysql implementation of aggregate snapshot store
+*/
+package snapshotstore
+
+import (
+	"context"
+	"database/sql"
+	systemErrors "errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	basesnapshotstore "github.com/vardius/go-api-boilerplate/pkg/snapshotstore"
+)
+
+type snapshotStore struct {
+	db *sql.DB
+}
+
+// Store keeps snapshot of a newer stream version only, columns are assigned in order
+// so stream_version is compared before it is updated
+func (s *snapshotStore) Store(ctx context.Context, snapshot basesnapshotstore.Snapshot) error {
+	if _, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO snapshots (stream_id, stream_name, stream_version, taken_at, payload) VALUES (?,?,?,?,?)
+		ON DUPLICATE KEY UPDATE
+			taken_at=IF(VALUES(stream_version)>stream_version, VALUES(taken_at), taken_at),
+			payload=IF(VALUES(stream_version)>stream_version, VALUES(payload), payload),
+			stream_version=IF(VALUES(stream_version)>stream_version, VALUES(stream_version), stream_version)`,
+		snapshot.StreamID.String(),
+		snapshot.StreamName,
+		snapshot.StreamVersion,
+		snapshot.TakenAt,
+		snapshot.Payload,
+	); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (s *snapshotStore) Get(ctx context.Context, streamID uuid.UUID, streamName string) (basesnapshotstore.Snapshot, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT stream_version, taken_at, payload FROM snapshots WHERE stream_id=? AND stream_name=? LIMIT 1`, streamID.String(), streamName)
+
+	snapshot := basesnapshotstore.Snapshot{
+		StreamID:   streamID,
+		StreamName: streamName,
+	}
+
+	if err := row.Scan(&snapshot.StreamVersion, &snapshot.TakenAt, &snapshot.Payload); err != nil {
+		if systemErrors.Is(err, sql.ErrNoRows) {
+			return basesnapshotstore.Snapshot{}, errors.Wrap(fmt.Errorf("%w: %s", basesnapshotstore.ErrSnapshotNotFound, err))
+		}
+		return basesnapshotstore.Snapshot{}, errors.Wrap(err)
+	}
+
+	return snapshot, nil
+}
+
+// New creates mysql snapshot store
+func New(db *sql.DB) basesnapshotstore.SnapshotStore {
+	return &snapshotStore{db}
+}
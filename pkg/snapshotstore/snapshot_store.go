@@ -0,0 +1,53 @@
+package snapshotstore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Snapshot is a serialized state of an aggregate root at given stream version
+type Snapshot struct {
+	StreamID   uuid.UUID `json:"stream_id"`
+	StreamName string    `json:"stream_name"`
+	// StreamVersion is a number of events applied to aggregate root
+	// when snapshot was taken, next event to load has this version
+	StreamVersion int             `json:"stream_version"`
+	TakenAt       time.Time       `json:"taken_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// SnapshotStore methods allow to save and load aggregate snapshots
+type SnapshotStore interface {
+	// Store saves snapshot replacing previous one of the same stream if it was taken at lower version,
+	// snapshot older than the stored one is ignored so slower concurrent save does not overwrite newer state
+	Store(ctx context.Context, snapshot Snapshot) error
+	// Get returns latest snapshot of the stream or ErrSnapshotNotFound
+	Get(ctx context.Context, streamID uuid.UUID, streamName string) (Snapshot, error)
+}
+
+// Snapshotter is implemented by aggregate roots that can be restored from snapshot
+type Snapshotter interface {
+	// MarshalSnapshot serializes current aggregate root state
+	MarshalSnapshot() (json.RawMessage, error)
+	// UnmarshalSnapshot restores aggregate root state taken at given version
+	UnmarshalSnapshot(version int, payload json.RawMessage) error
+}
+
+// Policy decides if snapshot should be taken after aggregate root
+// moved from one version to another
+type Policy func(fromVersion, toVersion int) bool
+
+// EveryNEvents returns policy taking snapshot each time aggregate root
+// crosses a multiple of n events, non positive n disables snapshots
+func EveryNEvents(n int) Policy {
+	return func(fromVersion, toVersion int) bool {
+		if n <= 0 {
+			return false
+		}
+
+		return fromVersion/n != toVersion/n
+	}
+}
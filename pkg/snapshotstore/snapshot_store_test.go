@@ -0,0 +1,28 @@
+package snapshotstore
+
+import (
+	"testing"
+)
+
+func TestEveryNEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		n           int
+		fromVersion int
+		toVersion   int
+		want        bool
+	}{
+		{"below threshold", 3, 0, 2, false},
+		{"reaches threshold", 3, 0, 3, true},
+		{"crosses threshold", 3, 2, 4, true},
+		{"between thresholds", 3, 3, 5, false},
+		{"disabled", 0, 0, 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EveryNEvents(tt.n)(tt.fromVersion, tt.toVersion); got != tt.want {
+				t.Errorf("EveryNEvents(%d)(%d, %d) = %v, want %v", tt.n, tt.fromVersion, tt.toVersion, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delivery is a single attempt to deliver event to subscription,
+// event is referenced by id so its personal data is not kept after subject is forgotten
+type Delivery struct {
+	ID             uuid.UUID `json:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	EventID        uuid.UUID `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	Attempt        int       `json:"attempt"`
+	// StatusCode of endpoint response, zero if request failed
+	StatusCode  int           `json:"status_code,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Succeeded   bool          `json:"succeeded"`
+	Duration    time.Duration `json:"duration"`
+	DeliveredAt time.Time     `json:"delivered_at"`
+}
+
+// DeliveryLog keeps delivery attempts of each subscription
+type DeliveryLog interface {
+	Add(ctx context.Context, d Delivery) error
+	// List returns up to limit deliveries of subscription, the most recent first
+	List(ctx context.Context, subscriptionID uuid.UUID, offset, limit int) ([]Delivery, error)
+}
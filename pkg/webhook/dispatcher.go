@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/deadletter"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+)
+
+// Config configures webhook dispatcher
+type Config struct {
+	// Retry policy of a single delivery, endpoint not responding with 2xx status is retried
+	Retry deadletter.RetryPolicy
+	// Timeout of a single request
+	Timeout time.Duration
+	// Concurrency limits number of requests sent at once
+	Concurrency int
+}
+
+// Dispatcher is an app adapter delivering events to matching subscriptions,
+// deliveries run in background so slow endpoints do not hold event bus
+type Dispatcher struct {
+	bus           eventbus.EventBus
+	subscriptions Subscriptions
+	deliveries    DeliveryLog
+	client        *http.Client
+	logger        *log.Logger
+	cfg           Config
+	handler       eventbus.EventHandler
+	slots         chan struct{}
+
+	// ctx is cancelled when dispatcher is stopped, mtx guards adding deliveries after it
+	ctx    context.Context
+	cancel context.CancelFunc
+	mtx    sync.Mutex
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher provides new webhook dispatcher
+func NewDispatcher(bus eventbus.EventBus, subscriptions Subscriptions, deliveries DeliveryLog, client *http.Client, logger *log.Logger, cfg Config) *Dispatcher {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 10
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	d := &Dispatcher{
+		bus:           bus,
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		client:        client,
+		logger:        logger,
+		cfg:           cfg,
+		slots:         make(chan struct{}, cfg.Concurrency),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	// handler has to be the same value to unsubscribe it
+	d.handler = d.Handle
+
+	return d
+}
+
+// Start subscribes dispatcher to all events until it is stopped
+func (d *Dispatcher) Start(ctx context.Context) error {
+	// remote buses block while subscribed
+	go func() {
+		if err := d.bus.Subscribe(d.ctx, eventbus.AllEvents, d.handler); err != nil && d.ctx.Err() == nil {
+			d.logger.Error(ctx, "[webhook] could not subscribe to events: %v\n", err)
+		}
+	}()
+
+	<-d.ctx.Done()
+
+	return nil
+}
+
+// Stop unsubscribes dispatcher and waits for deliveries in progress, their retries are abandoned
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	if err := d.bus.Unsubscribe(ctx, eventbus.AllEvents, d.handler); err != nil {
+		return errors.Wrap(err)
+	}
+
+	d.mtx.Lock()
+	d.cancel()
+	d.mtx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err())
+	}
+}
+
+// Handle starts delivery of event to every subscription matching it
+func (d *Dispatcher) Handle(ctx context.Context, event domain.Event) error {
+	subscriptions, err := d.subscriptions.FindAll(ctx)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	var payload []byte
+	for _, s := range subscriptions {
+		if !s.Matches(event) {
+			continue
+		}
+
+		if payload == nil {
+			// identity of event author holds its credentials, they are not sent outside
+			event.Identity = nil
+			if payload, err = json.Marshal(event); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+
+		d.mtx.Lock()
+		if d.ctx.Err() != nil {
+			d.mtx.Unlock()
+			return nil
+		}
+		d.wg.Add(1)
+		d.mtx.Unlock()
+
+		go func(s Subscription) {
+			defer d.wg.Done()
+
+			d.deliver(d.ctx, s, event, payload)
+		}(s)
+	}
+
+	return nil
+}
+
+// deliver sends event until endpoint accepts it or retry policy gives up, every attempt is saved to delivery log
+func (d *Dispatcher) deliver(ctx context.Context, s Subscription, event domain.Event, payload []byte) {
+	for attempt := 1; ; attempt++ {
+		select {
+		case d.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		delivery := d.send(ctx, s, event, payload, attempt)
+		<-d.slots
+
+		// log is saved even if dispatcher is being stopped
+		if err := d.deliveries.Add(context.Background(), delivery); err != nil {
+			d.logger.Error(ctx, "[webhook] could not save delivery %s of %s to %s: %v\n", delivery.ID, event.ID, s.ID, err)
+		}
+
+		if delivery.Succeeded {
+			return
+		}
+		if attempt >= d.cfg.Retry.MaxAttempts {
+			d.logger.Error(ctx, "[webhook] %s %s was not delivered to %s after %d attempts: %s\n", event.Metadata.Type, event.ID, s.ID, attempt, delivery.Error)
+			return
+		}
+
+		d.logger.Warning(ctx, "[webhook] %s %s was not delivered to %s (attempt %d): %s\n", event.Metadata.Type, event.ID, s.ID, attempt, delivery.Error)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.cfg.Retry.Backoff(attempt)):
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, s Subscription, event domain.Event, payload []byte, attempt int) Delivery {
+	delivery := Delivery{
+		ID:             uuid.New(),
+		SubscriptionID: s.ID,
+		EventID:        event.ID,
+		EventType:      event.Metadata.Type,
+		Attempt:        attempt,
+		DeliveredAt:    time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(s.Secret, payload, delivery.DeliveredAt))
+	req.Header.Set(EventTypeHeader, event.Metadata.Type)
+	req.Header.Set(EventIDHeader, event.ID.String())
+	req.Header.Set(DeliveryIDHeader, delivery.ID.String())
+
+	resp, err := d.client.Do(req)
+	delivery.Duration = time.Since(delivery.DeliveredAt)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+
+	// body is drained so connection can be reused
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Succeeded {
+		delivery.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return delivery
+}
@@ -0,0 +1,137 @@
+package webhook_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/deadletter"
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	memoryeventbus "github.com/vardius/go-api-boilerplate/pkg/eventbus/memory"
+	"github.com/vardius/go-api-boilerplate/pkg/identity"
+	"github.com/vardius/go-api-boilerplate/pkg/log"
+	"github.com/vardius/go-api-boilerplate/pkg/webhook"
+	memorywebhook "github.com/vardius/go-api-boilerplate/pkg/webhook/memory"
+)
+
+type eventMock struct{}
+
+func (e eventMock) GetType() string {
+	return "test.Mock"
+}
+
+type subscriptionsMock []webhook.Subscription
+
+func (s subscriptionsMock) FindAll(ctx context.Context) ([]webhook.Subscription, error) {
+	return s, nil
+}
+
+// waitForDeliveries polls delivery log until it holds n deliveries of subscription
+func waitForDeliveries(t *testing.T, deliveries webhook.DeliveryLog, subscriptionID uuid.UUID, n int) []webhook.Delivery {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		list, err := deliveries.List(context.Background(), subscriptionID, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) >= n {
+			return list
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d deliveries, got %d", n, len(list))
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New("development")
+	secret := "0123456789abcdef"
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if err := webhook.Verify(secret, body, r.Header.Get(webhook.SignatureHeader), time.Now(), time.Minute); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if bytes.Contains(body, []byte("token")) {
+			t.Errorf("expected identity to be omitted, got %s", body)
+		}
+		if r.Header.Get(webhook.EventTypeHeader) != (eventMock{}).GetType() {
+			t.Errorf("unexpected event type header %s", r.Header.Get(webhook.EventTypeHeader))
+		}
+
+		// first attempt fails so delivery is retried
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	matching := webhook.Subscription{ID: uuid.New(), URL: server.URL, Secret: secret, EventTypes: []string{"test.*"}}
+	other := webhook.Subscription{ID: uuid.New(), URL: server.URL, Secret: secret, EventTypes: []string{"other.Mock"}}
+
+	bus := memoryeventbus.New(1, logger)
+	deliveries := memorywebhook.New()
+	d := webhook.NewDispatcher(bus, subscriptionsMock{matching, other}, deliveries, server.Client(), logger, webhook.Config{
+		Retry: deadletter.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+
+	go func() {
+		if err := d.Start(ctx); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer func() {
+		if err := d.Stop(ctx); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	event, err := domain.NewEvent(uuid.New(), "test", 0, eventMock{}, &identity.Identity{Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Handle(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+
+	list := waitForDeliveries(t, deliveries, matching.ID, 2)
+	if list[0].Attempt != 2 || !list[0].Succeeded || list[0].StatusCode != http.StatusNoContent {
+		t.Errorf("expected second attempt to succeed, got %+v", list[0])
+	}
+	if list[1].Attempt != 1 || list[1].Succeeded || list[1].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected first attempt to fail, got %+v", list[1])
+	}
+	if list[0].EventID != event.ID {
+		t.Errorf("expected delivery of %s, got %s", event.ID, list[0].EventID)
+	}
+
+	list, err = deliveries.List(ctx, other.ID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Errorf("expected no deliveries of not matching subscription, got %d", len(list))
+	}
+}
@@ -0,0 +1,4 @@
+/*
+Package webhook provides dispatcher delivering events to subscribed http endpoints signed with HMAC
+*/
+package webhook
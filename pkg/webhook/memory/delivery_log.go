@@ -0,0 +1,52 @@
+/*
+Package webhook provides memory implementation of webhook delivery log
+*/
+package webhook
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	basewebhook "github.com/vardius/go-api-boilerplate/pkg/webhook"
+)
+
+type deliveryLog struct {
+	mtx        sync.RWMutex
+	deliveries []basewebhook.Delivery
+}
+
+func (l *deliveryLog) Add(ctx context.Context, d basewebhook.Delivery) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.deliveries = append(l.deliveries, d)
+
+	return nil
+}
+
+func (l *deliveryLog) List(ctx context.Context, subscriptionID uuid.UUID, offset, limit int) ([]basewebhook.Delivery, error) {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+
+	deliveries := make([]basewebhook.Delivery, 0)
+	for i := len(l.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if l.deliveries[i].SubscriptionID != subscriptionID {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+
+		deliveries = append(deliveries, l.deliveries[i])
+	}
+
+	return deliveries, nil
+}
+
+// New creates in memory webhook delivery log
+func New() basewebhook.DeliveryLog {
+	return &deliveryLog{}
+}
@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	basewebhook "github.com/vardius/go-api-boilerplate/pkg/webhook"
+)
+
+func TestNew(t *testing.T) {
+	l := New()
+
+	if l == nil {
+		t.Fail()
+	}
+}
+
+func TestDeliveryLog(t *testing.T) {
+	ctx := context.Background()
+	l := New()
+	subscriptionID := uuid.New()
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if err := l.Add(ctx, basewebhook.Delivery{ID: uuid.New(), SubscriptionID: subscriptionID, Attempt: attempt}); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Add(ctx, basewebhook.Delivery{ID: uuid.New(), SubscriptionID: uuid.New(), Attempt: attempt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deliveries, err := l.List(ctx, subscriptionID, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(deliveries))
+	}
+	if deliveries[0].Attempt != 3 || deliveries[1].Attempt != 2 {
+		t.Errorf("expected the most recent deliveries first, got attempts %d, %d", deliveries[0].Attempt, deliveries[1].Attempt)
+	}
+
+	deliveries, err = l.List(ctx, subscriptionID, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Attempt != 1 {
+		t.Errorf("expected the first attempt after offset, got %+v", deliveries)
+	}
+}
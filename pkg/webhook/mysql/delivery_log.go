@@ -0,0 +1,73 @@
+/*
+Package webhook provides mysql implementation of webhook delivery log
+*/
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+	basewebhook "github.com/vardius/go-api-boilerplate/pkg/webhook"
+)
+
+type deliveryLog struct {
+	db *sql.DB
+}
+
+func (l *deliveryLog) Add(ctx context.Context, d basewebhook.Delivery) error {
+	if _, err := l.db.ExecContext(ctx, `INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, attempt, status_code, error, succeeded, duration_ms, delivered_at) VALUES (?,?,?,?,?,?,?,?,?,?)`,
+		d.ID.String(), d.SubscriptionID.String(), d.EventID.String(), d.EventType, d.Attempt, d.StatusCode, d.Error, d.Succeeded, d.Duration.Milliseconds(), d.DeliveredAt.UTC(),
+	); err != nil {
+		return errors.Wrap(err)
+	}
+
+	return nil
+}
+
+func (l *deliveryLog) List(ctx context.Context, subscriptionID uuid.UUID, offset, limit int) ([]basewebhook.Delivery, error) {
+	rows, err := l.db.QueryContext(ctx, `SELECT id, subscription_id, event_id, event_type, attempt, status_code, error, succeeded, duration_ms, delivered_at FROM webhook_deliveries WHERE subscription_id=? ORDER BY distinct_id DESC LIMIT ? OFFSET ?`, subscriptionID.String(), limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]basewebhook.Delivery, 0)
+	for rows.Next() {
+		var (
+			d                           basewebhook.Delivery
+			id, subscriptionID, eventID string
+			duration                    int64
+		)
+		if err := rows.Scan(&id, &subscriptionID, &eventID, &d.EventType, &d.Attempt, &d.StatusCode, &d.Error, &d.Succeeded, &duration, &d.DeliveredAt); err != nil {
+			return nil, errors.Wrap(err)
+		}
+
+		if d.ID, err = uuid.Parse(id); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if d.SubscriptionID, err = uuid.Parse(subscriptionID); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if d.EventID, err = uuid.Parse(eventID); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		d.Duration = time.Duration(duration) * time.Millisecond
+
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return deliveries, nil
+}
+
+// New creates mysql webhook delivery log
+func New(db *sql.DB) basewebhook.DeliveryLog {
+	return &deliveryLog{db: db}
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vardius/go-api-boilerplate/pkg/application"
+	"github.com/vardius/go-api-boilerplate/pkg/errors"
+)
+
+// Webhook request headers
+const (
+	// SignatureHeader carries signature of request body, see Sign
+	SignatureHeader = "X-Webhook-Signature"
+	// EventTypeHeader carries type of delivered event
+	EventTypeHeader = "X-Webhook-Event"
+	// EventIDHeader carries id of delivered event, it is the same for every attempt so receivers can skip duplicates
+	EventIDHeader = "X-Webhook-Event-ID"
+	// DeliveryIDHeader carries id of delivery attempt as it is saved in delivery log
+	DeliveryIDHeader = "X-Webhook-Delivery-ID"
+)
+
+// ErrInvalidSignature is when webhook request signature does not match its body
+var ErrInvalidSignature = fmt.Errorf("%w: invalid webhook signature", application.ErrUnauthorized)
+
+// Sign returns signature header value of payload sent at given time, t=<unix time>,v1=<signature>
+// where signature is hex encoded HMAC-SHA256 of "<unix time>.<payload>" keyed with subscription secret
+func Sign(secret string, payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, signature(secret, timestamp, payload))
+}
+
+// Verify checks signature header value of payload received at given time,
+// signatures older than tolerance are rejected so captured requests can not be replayed
+func Verify(secret string, payload []byte, header string, at time.Time, tolerance time.Duration) error {
+	var timestamp, sig string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch kv[0] {
+		case "t":
+			timestamp = kv[1]
+		case "v1":
+			sig = kv[1]
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || sig == "" {
+		return errors.Wrap(fmt.Errorf("%w: malformed header", ErrInvalidSignature))
+	}
+
+	if age := at.Sub(time.Unix(unix, 0)); tolerance > 0 && (age > tolerance || age < -tolerance) {
+		return errors.Wrap(fmt.Errorf("%w: timestamp outside of tolerance", ErrInvalidSignature))
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, timestamp, payload))) {
+		return errors.Wrap(ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+func signature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
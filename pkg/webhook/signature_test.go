@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"id":"1"}`)
+	at := time.Unix(1602662400, 0)
+	header := Sign("secret", payload, at)
+
+	tests := []struct {
+		name    string
+		secret  string
+		payload []byte
+		header  string
+		at      time.Time
+		valid   bool
+	}{
+		{"valid", "secret", payload, header, at, true},
+		{"valid within tolerance", "secret", payload, header, at.Add(time.Minute), true},
+		{"other secret", "other", payload, header, at, false},
+		{"tampered payload", "secret", []byte(`{"id":"2"}`), header, at, false},
+		{"expired", "secret", payload, header, at.Add(time.Hour), false},
+		{"malformed header", "secret", payload, "v1=abc", at, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.payload, tt.header, tt.at, 5*time.Minute)
+			if tt.valid && err != nil {
+				t.Errorf("expected valid signature, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/vardius/go-api-boilerplate/pkg/domain"
+	"github.com/vardius/go-api-boilerplate/pkg/eventbus"
+)
+
+// Subscription of http endpoint to events
+type Subscription struct {
+	ID     uuid.UUID
+	URL    string
+	Secret string
+	// EventTypes are event types or patterns, see eventbus.Topics
+	EventTypes []string
+}
+
+// Matches returns true if event is delivered to subscription
+func (s Subscription) Matches(event domain.Event) bool {
+	for _, topic := range eventbus.Topics(event) {
+		for _, eventType := range s.EventTypes {
+			if eventType == topic {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Subscriptions provides active subscriptions
+type Subscriptions interface {
+	FindAll(ctx context.Context) ([]Subscription, error)
+}